@@ -43,7 +43,7 @@ func (s *AccountService) GetProfitAndLoss() (*AccountProfitAndLossResponse, erro
 
 	param := url.Values{}
 
-	if err := s.client.getPublic("/iserver/account/pnl/partitioned", param, &res); err != nil {
+	if err := s.client.getPublic(newOperation(OperationNameGetProfitAndLoss, "/iserver/account/pnl/partitioned"), param, &res); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,201 @@
+// Package backtest replays historical bars or recorded ticks through the
+// handler signatures of the SDK, so strategy code runs unchanged against a
+// simulated fill model and a virtual clock.
+//
+//	runner := backtest.NewRunner(backtest.Config{Cash: ibkr.DecimalFromInt(100000)})
+//	strategy := NewStrategy(runner.Orders()) // takes ibkr.OrdersServiceI
+//	runner.SubscribeMarketData(strategy.OnMarketData)
+//	runner.SubscribeTradesData(strategy.OnTrades)
+//	result, err := runner.Run(backtest.Bars(bars))
+//	result.WriteJSON(os.Stdout)
+package backtest
+
+import (
+	"fmt"
+	"iter"
+	"time"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+	"github.com/dictxwang/go-ibkr/ibkrsim"
+)
+
+// Config :
+type Config struct {
+	// Cash is the starting equity.
+	Cash       ibkr.Decimal
+	Commission ibkrsim.CommissionFunc
+	Slippage   ibkrsim.SlippageFunc
+	// Multipliers holds the contract multiplier by conid, 1 if missing.
+	Multipliers map[int]ibkr.Decimal
+	// Location sets the trading day for DAY orders and daily returns,
+	// America/New_York if nil.
+	Location *time.Location
+}
+
+// Runner :
+// Drives one backtest. Orders placed through Orders fill against the feed
+// in ibkrsim, with the clock advanced to the time of each tick.
+type Runner struct {
+	config Config
+	sim    *ibkrsim.SimulatedOrders
+
+	now                time.Time
+	marketDataHandlers []func(ibkr.WebsocketPublicMarketDataResponse) error
+
+	cash       ibkr.Decimal
+	positions  map[int]ibkr.Decimal
+	marks      map[int]ibkr.Decimal
+	commission ibkr.Decimal
+	traded     ibkr.Decimal
+	equity     []EquityPoint
+	trades     []ibkr.TradeItem
+}
+
+// NewRunner :
+func NewRunner(config Config) *Runner {
+	r := &Runner{
+		config:    config,
+		cash:      config.Cash,
+		positions: map[int]ibkr.Decimal{},
+		marks:     map[int]ibkr.Decimal{},
+	}
+	r.sim = ibkrsim.NewSimulatedOrders().
+		WithClock(r.Now).
+		WithCommission(config.Commission).
+		WithSlippage(config.Slippage)
+	if config.Location != nil {
+		r.sim.WithLocation(config.Location)
+	}
+	r.sim.SubscribeExecutions(r.execute)
+	return r
+}
+
+// Orders :
+func (r *Runner) Orders() ibkr.OrdersServiceI {
+	return r.sim
+}
+
+// OrderMonitoring :
+func (r *Runner) OrderMonitoring() ibkr.OrderMonitoringServiceI {
+	return r.sim
+}
+
+// Now :
+// Returns the virtual time, the time of the tick being replayed.
+func (r *Runner) Now() time.Time {
+	return r.now
+}
+
+// SubscribeMarketData :
+// Receives every tick of the feed after resting orders were matched against
+// it. An error stops Run.
+func (r *Runner) SubscribeMarketData(handler func(ibkr.WebsocketPublicMarketDataResponse) error) {
+	r.marketDataHandlers = append(r.marketDataHandlers, handler)
+}
+
+// SubscribeOrder :
+// Receives synthetic sor events.
+func (r *Runner) SubscribeOrder(handler func(ibkr.WebsocketPrivateOrderResponse) error) {
+	r.sim.SubscribeOrder(handler)
+}
+
+// SubscribeTradesData :
+// Receives synthetic str events.
+func (r *Runner) SubscribeTradesData(handler func(ibkr.WebsocketPrivateTradesDataResponse) error) {
+	r.sim.SubscribeTradesData(handler)
+}
+
+// Run :
+// Replays feed, whose ticks must be in time order, and returns the result.
+// A Runner runs once.
+func (r *Runner) Run(feed iter.Seq[ibkr.WebsocketPublicMarketDataResponse]) (*Result, error) {
+	for tick := range feed {
+		if tick.UpdateTime != 0 {
+			now := time.UnixMilli(tick.UpdateTime)
+			if now.Before(r.now) {
+				return nil, fmt.Errorf("backtest: tick at %s is before %s", now.Format(time.RFC3339Nano), r.now.Format(time.RFC3339Nano))
+			}
+			r.now = now
+		}
+		if err := r.sim.OnMarketData(tick); err != nil {
+			return nil, err
+		}
+		r.mark(tick)
+		for _, handler := range r.marketDataHandlers {
+			if err := handler(tick); err != nil {
+				return nil, err
+			}
+		}
+		r.record()
+	}
+	return r.result(), nil
+}
+
+// execute :
+// Books one simulated execution.
+func (r *Runner) execute(trade ibkr.TradeItem) error {
+	quantity := trade.Size
+	if trade.Side == "S" {
+		quantity = quantity.Neg()
+	}
+	value := trade.Price.Mul(r.multiplier(trade.ContractId))
+
+	r.positions[trade.ContractId] = r.positions[trade.ContractId].Add(quantity)
+	r.cash = r.cash.Sub(quantity.Mul(value)).Sub(trade.Commission)
+	r.commission = r.commission.Add(trade.Commission)
+	r.traded = r.traded.Add(trade.Size.Mul(value))
+	r.marks[trade.ContractId] = trade.Price
+	r.trades = append(r.trades, trade)
+	return nil
+}
+
+// mark :
+// Marks the positions of the tick's contract to its last price, or to the
+// middle of its quote.
+func (r *Runner) mark(tick ibkr.WebsocketPublicMarketDataResponse) {
+	contractId, ok := contractIdOf(tick)
+	if !ok {
+		return
+	}
+	if last, ok := parsePrice(tick.LastPrice); ok {
+		r.marks[contractId] = last
+		return
+	}
+	bid, hasBid := parsePrice(tick.BidPrice)
+	ask, hasAsk := parsePrice(tick.AskPrice)
+	if hasBid && hasAsk {
+		r.marks[contractId] = bid.Add(ask).Mul(ibkr.NewDecimal(5, 1))
+	}
+}
+
+// record :
+// Appends the equity at the current time, replacing a point of the same time.
+func (r *Runner) record() {
+	point := EquityPoint{Time: r.now, Cash: r.cash, Equity: r.cash}
+	for contractId, position := range r.positions {
+		point.Equity = point.Equity.Add(position.Mul(r.marks[contractId]).Mul(r.multiplier(contractId)))
+	}
+	if n := len(r.equity); n > 0 && r.equity[n-1].Time.Equal(r.now) {
+		r.equity[n-1] = point
+		return
+	}
+	r.equity = append(r.equity, point)
+}
+
+func (r *Runner) multiplier(contractId int) ibkr.Decimal {
+	if multiplier, ok := r.config.Multipliers[contractId]; ok {
+		return multiplier
+	}
+	return ibkr.DecimalFromInt(1)
+}
+
+func (r *Runner) location() *time.Location {
+	if r.config.Location != nil {
+		return r.config.Location
+	}
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
@@ -0,0 +1,187 @@
+package backtest_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+	"github.com/dictxwang/go-ibkr/backtest"
+)
+
+const conid = 265598
+
+var d = ibkr.MustParseDecimal
+
+// flatBars returns one bar a day at 10:00 New York, with every price at close.
+func flatBars(closes ...string) []backtest.Bar {
+	start := time.Date(2026, 10, 12, 14, 0, 0, 0, time.UTC)
+	bars := make([]backtest.Bar, 0, len(closes))
+	for n, price := range closes {
+		bars = append(bars, backtest.Bar{
+			ContractId: conid,
+			Time:       start.AddDate(0, 0, n),
+			Open:       d(price),
+			High:       d(price),
+			Low:        d(price),
+			Close:      d(price),
+		})
+	}
+	return bars
+}
+
+func marketOrder(side ibkr.OrderSide, quantity string) ibkr.PlaceOrderParam {
+	builder := ibkr.NewOrder("DU123", conid)
+	if side == ibkr.OrderSideBuy {
+		builder.Buy(d(quantity))
+	} else {
+		builder.Sell(d(quantity))
+	}
+	param, err := builder.Market().Build()
+	if err != nil {
+		panic(err)
+	}
+	return param
+}
+
+func TestRunner(t *testing.T) {
+	tests := []struct {
+		name string
+		bars []backtest.Bar
+		// orders are placed on the first tick of the bar of their index
+		orders map[int]ibkr.PlaceOrderParam
+
+		// trades are side, size and price of every fill
+		trades      []string
+		equity      []string
+		totalReturn float64
+		sharpe      float64
+		maxDrawdown float64
+		turnover    float64
+	}{
+		{
+			name:        "long round trip",
+			bars:        flatBars("100", "110", "99", "108.9"),
+			orders:      map[int]ibkr.PlaceOrderParam{0: marketOrder(ibkr.OrderSideBuy, "50"), 3: marketOrder(ibkr.OrderSideSell, "50")},
+			trades:      []string{"B 50 100", "S 50 108.9"},
+			equity:      []string{"9999", "10499", "9949", "10443"},
+			totalReturn: 0.0443,
+			sharpe:      3.833675973454259,
+			maxDrawdown: 550.0 / 10499,
+			turnover:    10445 / 10222.5,
+		},
+		{
+			name:        "short round trip",
+			bars:        flatBars("100", "90"),
+			orders:      map[int]ibkr.PlaceOrderParam{0: marketOrder(ibkr.OrderSideSell, "50"), 1: marketOrder(ibkr.OrderSideBuy, "50")},
+			trades:      []string{"S 50 100", "B 50 90"},
+			equity:      []string{"9999", "10498"},
+			totalReturn: 0.0498,
+			sharpe:      11.180076752690324,
+			maxDrawdown: 0.0001,
+			turnover:    9500 / 10248.5,
+		},
+		{
+			name:   "no trades",
+			bars:   flatBars("100", "90"),
+			trades: []string{},
+			equity: []string{"10000", "10000"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := backtest.NewRunner(backtest.Config{
+				Cash:       d("10000"),
+				Commission: backtest.PerShareCommission(d("0.01"), d("1"), ibkr.Decimal{}),
+			})
+			ticks := 0
+			runner.SubscribeMarketData(func(ibkr.WebsocketPublicMarketDataResponse) error {
+				// Bars replays four ticks a bar
+				if order, ok := tt.orders[ticks/4]; ok && ticks%4 == 0 {
+					if _, err := runner.Orders().PlaceOrder([]ibkr.PlaceOrderParam{order}); err != nil {
+						return err
+					}
+				}
+				ticks++
+				return nil
+			})
+
+			result, err := runner.Run(backtest.Bars(tt.bars))
+			if err != nil {
+				t.Fatal(err)
+			}
+			trades := []string{}
+			for _, trade := range result.Trades {
+				trades = append(trades, trade.Side+" "+trade.Size.String()+" "+trade.Price.String())
+			}
+			if !reflect.DeepEqual(trades, tt.trades) {
+				t.Errorf("trades %v, want %v", trades, tt.trades)
+			}
+			var equity []string
+			for _, point := range result.Equity {
+				equity = append(equity, point.Equity.String())
+			}
+			if !reflect.DeepEqual(equity, tt.equity) {
+				t.Errorf("equity %v, want %v", equity, tt.equity)
+			}
+
+			summary := result.Summary
+			if summary.Trades != len(tt.trades) || summary.Commission.String() != ibkr.DecimalFromInt(int64(len(tt.trades))).String() {
+				t.Errorf("%d trades and commission %s, want %d and 1 a trade", summary.Trades, summary.Commission, len(tt.trades))
+			}
+			if !summary.Start.Equal(tt.bars[0].Time) || !summary.End.Equal(tt.bars[len(tt.bars)-1].Time) {
+				t.Errorf("period %s to %s", summary.Start, summary.End)
+			}
+			for _, metric := range []struct {
+				name      string
+				got, want float64
+			}{
+				{"total return", summary.TotalReturn, tt.totalReturn},
+				{"sharpe ratio", summary.SharpeRatio, tt.sharpe},
+				{"max drawdown", summary.MaxDrawdown, tt.maxDrawdown},
+				{"turnover", summary.Turnover, tt.turnover},
+			} {
+				if math.Abs(metric.got-metric.want) > 1e-9 {
+					t.Errorf("%s %v, want %v", metric.name, metric.got, metric.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRunnerMultiplier(t *testing.T) {
+	runner := backtest.NewRunner(backtest.Config{
+		Cash:        d("100000"),
+		Multipliers: map[int]ibkr.Decimal{conid: d("100")},
+	})
+	placed := false
+	runner.SubscribeMarketData(func(ibkr.WebsocketPublicMarketDataResponse) error {
+		if placed {
+			return nil
+		}
+		placed = true
+		_, err := runner.Orders().PlaceOrder([]ibkr.PlaceOrderParam{marketOrder(ibkr.OrderSideBuy, "2")})
+		return err
+	})
+	result, err := runner.Run(backtest.Bars(flatBars("5", "6")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2 contracts of 100 bought at 5 and marked at 6
+	last := result.Equity[len(result.Equity)-1]
+	if last.Cash.String() != "99000" || last.Equity.String() != "100200" {
+		t.Errorf("cash %s equity %s, want 99000 and 100200", last.Cash, last.Equity)
+	}
+}
+
+func TestRunnerTicksOutOfOrder(t *testing.T) {
+	start := time.Date(2026, 10, 12, 14, 0, 0, 0, time.UTC)
+	ticks := []ibkr.WebsocketPublicMarketDataResponse{
+		{ContractId: conid, UpdateTime: start.UnixMilli(), LastPrice: "100"},
+		{ContractId: conid, UpdateTime: start.Add(-time.Second).UnixMilli(), LastPrice: "101"},
+	}
+	if _, err := backtest.NewRunner(backtest.Config{Cash: d("10000")}).Run(backtest.Ticks(ticks)); err == nil {
+		t.Error("a tick before the previous one was replayed")
+	}
+}
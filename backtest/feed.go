@@ -0,0 +1,175 @@
+package backtest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"strconv"
+	"strings"
+	"time"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+	"github.com/dictxwang/go-ibkr/cassette"
+)
+
+// Bar :
+// One OHLCV bar starting at Time.
+type Bar struct {
+	ContractId int          `json:"conid"`
+	Time       time.Time    `json:"time"`
+	Open       ibkr.Decimal `json:"open"`
+	High       ibkr.Decimal `json:"high"`
+	Low        ibkr.Decimal `json:"low"`
+	Close      ibkr.Decimal `json:"close"`
+	Volume     ibkr.Decimal `json:"volume"`
+}
+
+// Bars :
+// Replays each bar as four ticks at its time: open, the extreme nearer to the
+// open, the other extreme, close. Bid, ask and last all carry the price and
+// sizes are unlimited.
+func Bars(bars []Bar) iter.Seq[ibkr.WebsocketPublicMarketDataResponse] {
+	return func(yield func(ibkr.WebsocketPublicMarketDataResponse) bool) {
+		for _, bar := range bars {
+			prices := []ibkr.Decimal{bar.Open, bar.High, bar.Low, bar.Close}
+			if bar.Close.Cmp(bar.Open) >= 0 {
+				prices = []ibkr.Decimal{bar.Open, bar.Low, bar.High, bar.Close}
+			}
+			for _, price := range prices {
+				value := price.String()
+				tick := ibkr.WebsocketPublicMarketDataResponse{
+					Topic:      fmt.Sprintf("%s+%d", ibkr.MessageTopicSubscribeMarketData, bar.ContractId),
+					ContractId: bar.ContractId,
+					UpdateTime: bar.Time.UnixMilli(),
+					BidPrice:   value,
+					AskPrice:   value,
+					LastPrice:  value,
+				}
+				if !yield(tick) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Ticks :
+// Replays recorded smd updates as they are.
+func Ticks(ticks []ibkr.WebsocketPublicMarketDataResponse) iter.Seq[ibkr.WebsocketPublicMarketDataResponse] {
+	return func(yield func(ibkr.WebsocketPublicMarketDataResponse) bool) {
+		for _, tick := range ticks {
+			if !yield(tick) {
+				return
+			}
+		}
+	}
+}
+
+// CassetteTicks :
+// Replays the smd updates received in a cassette recording.
+func CassetteTicks(c *cassette.Cassette) iter.Seq[ibkr.WebsocketPublicMarketDataResponse] {
+	return func(yield func(ibkr.WebsocketPublicMarketDataResponse) bool) {
+		for _, interaction := range c.Interactions {
+			if interaction.Kind != cassette.KindWebsocket || interaction.Direction != cassette.DirectionRead {
+				continue
+			}
+			var tick ibkr.WebsocketPublicMarketDataResponse
+			if err := json.Unmarshal([]byte(interaction.Message), &tick); err != nil {
+				continue
+			}
+			if ibkr.ParseWebsocketTopic(tick.Topic).Name != ibkr.MessageTopicSubscribeMarketData {
+				continue
+			}
+			if tick.UpdateTime == 0 {
+				tick.UpdateTime = interaction.Time.UnixMilli()
+			}
+			if !yield(tick) {
+				return
+			}
+		}
+	}
+}
+
+// LoadBarsCSV :
+// Reads bars of contractId from CSV with the header
+// time,open,high,low,close,volume. Time is RFC 3339, a date, or epoch
+// seconds.
+func LoadBarsCSV(r io.Reader, contractId int) ([]Bar, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"time", "open", "high", "low", "close"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("backtest: missing column %q", name)
+		}
+	}
+
+	var bars []Bar
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return bars, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		bar := Bar{ContractId: contractId}
+		if bar.Time, err = parseTime(record[columns["time"]]); err != nil {
+			return nil, fmt.Errorf("backtest: line %d: %w", line, err)
+		}
+		fields := map[string]*ibkr.Decimal{"open": &bar.Open, "high": &bar.High, "low": &bar.Low, "close": &bar.Close, "volume": &bar.Volume}
+		for name, dst := range fields {
+			column, ok := columns[name]
+			if !ok {
+				continue
+			}
+			if *dst, err = ibkr.ParseDecimal(record[column]); err != nil {
+				return nil, fmt.Errorf("backtest: line %d: %s: %w", line, name, err)
+			}
+		}
+		bars = append(bars, bar)
+	}
+}
+
+func parseTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func contractIdOf(tick ibkr.WebsocketPublicMarketDataResponse) (int, bool) {
+	if tick.ContractId != 0 {
+		return tick.ContractId, true
+	}
+	topic := ibkr.ParseWebsocketTopic(tick.Topic)
+	if len(topic.Args) == 0 {
+		return 0, false
+	}
+	contractId, err := strconv.Atoi(topic.Args[0])
+	return contractId, err == nil
+}
+
+// parsePrice :
+// Parses an smd price, which is prefixed with C for a prior close and H for
+// a halted contract.
+func parsePrice(value string) (ibkr.Decimal, bool) {
+	value = strings.TrimLeft(value, "CH")
+	if value == "" {
+		return ibkr.Decimal{}, false
+	}
+	price, err := ibkr.ParseDecimal(value)
+	return price, err == nil
+}
@@ -0,0 +1,51 @@
+package backtest
+
+import (
+	"strings"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+	"github.com/dictxwang/go-ibkr/ibkrsim"
+)
+
+// PerShareCommission :
+// Charges rate per share, at least minimum and at most maximumRate of the
+// traded value; a zero maximumRate means no cap.
+func PerShareCommission(rate, minimum, maximumRate ibkr.Decimal) ibkrsim.CommissionFunc {
+	return func(param ibkr.PlaceOrderParam, quantity, price ibkr.Decimal) ibkr.Decimal {
+		commission := quantity.Mul(rate)
+		if commission.LessThan(minimum) {
+			commission = minimum
+		}
+		if maximum := quantity.Mul(price).Mul(maximumRate); maximumRate.Sign() > 0 && maximum.LessThan(commission) {
+			commission = maximum
+		}
+		return commission
+	}
+}
+
+// PercentCommission :
+// Charges rate of the traded value, e.g. 0.0002 for 2 basis points.
+func PercentCommission(rate ibkr.Decimal) ibkrsim.CommissionFunc {
+	return func(param ibkr.PlaceOrderParam, quantity, price ibkr.Decimal) ibkr.Decimal {
+		return quantity.Mul(price).Mul(rate)
+	}
+}
+
+// FixedSlippage :
+// Moves every market execution amount against the order.
+func FixedSlippage(amount ibkr.Decimal) ibkrsim.SlippageFunc {
+	return func(param ibkr.PlaceOrderParam, quantity, price ibkr.Decimal) ibkr.Decimal {
+		if strings.EqualFold(param.Side, string(ibkr.OrderSideBuy)) {
+			return price.Add(amount)
+		}
+		return price.Sub(amount)
+	}
+}
+
+// BasisPointSlippage :
+// Moves every market execution basisPoints of its price against the order.
+func BasisPointSlippage(basisPoints ibkr.Decimal) ibkrsim.SlippageFunc {
+	return func(param ibkr.PlaceOrderParam, quantity, price ibkr.Decimal) ibkr.Decimal {
+		return FixedSlippage(price.Mul(basisPoints).Mul(ibkr.NewDecimal(1, 4)))(param, quantity, price)
+	}
+}
@@ -0,0 +1,198 @@
+package backtest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+)
+
+// EquityPoint :
+type EquityPoint struct {
+	Time   time.Time    `json:"time"`
+	Cash   ibkr.Decimal `json:"cash"`
+	Equity ibkr.Decimal `json:"equity"`
+}
+
+// Summary :
+type Summary struct {
+	Start          time.Time    `json:"start"`
+	End            time.Time    `json:"end"`
+	StartingEquity ibkr.Decimal `json:"startingEquity"`
+	EndingEquity   ibkr.Decimal `json:"endingEquity"`
+	TotalReturn    float64      `json:"totalReturn"`
+	// SharpeRatio is annualized from daily returns with a zero risk-free rate.
+	SharpeRatio float64 `json:"sharpeRatio"`
+	// MaxDrawdown is the largest fall from a peak, as a fraction of the peak.
+	MaxDrawdown float64 `json:"maxDrawdown"`
+	// Turnover is the traded value divided by the average equity.
+	Turnover   float64      `json:"turnover"`
+	Trades     int          `json:"trades"`
+	Commission ibkr.Decimal `json:"commission"`
+}
+
+// Result :
+type Result struct {
+	Summary Summary          `json:"summary"`
+	Equity  []EquityPoint    `json:"equity"`
+	Trades  []ibkr.TradeItem `json:"trades"`
+}
+
+func (r *Runner) result() *Result {
+	result := &Result{
+		Equity: r.equity,
+		Trades: r.trades,
+		Summary: Summary{
+			StartingEquity: r.config.Cash,
+			EndingEquity:   r.config.Cash,
+			Trades:         len(r.trades),
+			Commission:     r.commission,
+		},
+	}
+	if result.Equity == nil {
+		result.Equity = []EquityPoint{}
+	}
+	if result.Trades == nil {
+		result.Trades = []ibkr.TradeItem{}
+	}
+	if len(r.equity) == 0 {
+		return result
+	}
+
+	summary := &result.Summary
+	summary.Start = r.equity[0].Time
+	summary.End = r.equity[len(r.equity)-1].Time
+	summary.EndingEquity = r.equity[len(r.equity)-1].Equity
+	if cash := r.config.Cash.Float64(); cash != 0 {
+		summary.TotalReturn = summary.EndingEquity.Float64()/cash - 1
+	}
+
+	peak, total := r.config.Cash.Float64(), 0.0
+	for _, point := range r.equity {
+		equity := point.Equity.Float64()
+		peak = math.Max(peak, equity)
+		if peak > 0 {
+			summary.MaxDrawdown = math.Max(summary.MaxDrawdown, (peak-equity)/peak)
+		}
+		total += equity
+	}
+	if average := total / float64(len(r.equity)); average > 0 {
+		summary.Turnover = r.traded.Float64() / average
+	}
+	summary.SharpeRatio = sharpeRatio(r.dailyEquity())
+	return result
+}
+
+// dailyEquity :
+// Returns the starting equity followed by the closing equity of every day.
+func (r *Runner) dailyEquity() []float64 {
+	location := r.location()
+	daily := []float64{r.config.Cash.Float64()}
+	day := ""
+	for _, point := range r.equity {
+		if current := point.Time.In(location).Format(time.DateOnly); current != day {
+			day = current
+			daily = append(daily, point.Equity.Float64())
+			continue
+		}
+		daily[len(daily)-1] = point.Equity.Float64()
+	}
+	return daily
+}
+
+func sharpeRatio(equity []float64) float64 {
+	var returns []float64
+	for i := 1; i < len(equity); i++ {
+		if equity[i-1] != 0 {
+			returns = append(returns, equity[i]/equity[i-1]-1)
+		}
+	}
+	if len(returns) < 2 {
+		return 0
+	}
+	var mean float64
+	for _, value := range returns {
+		mean += value
+	}
+	mean /= float64(len(returns))
+	var variance float64
+	for _, value := range returns {
+		variance += (value - mean) * (value - mean)
+	}
+	deviation := math.Sqrt(variance / float64(len(returns)-1))
+	if deviation == 0 {
+		return 0
+	}
+	return mean / deviation * math.Sqrt(252)
+}
+
+// WriteJSON :
+func (r *Result) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteSummaryCSV :
+func (r *Result) WriteSummaryCSV(w io.Writer) error {
+	s := r.Summary
+	return writeCSV(w, []string{"metric", "value"}, [][]string{
+		{"start", s.Start.Format(time.RFC3339)},
+		{"end", s.End.Format(time.RFC3339)},
+		{"startingEquity", s.StartingEquity.String()},
+		{"endingEquity", s.EndingEquity.String()},
+		{"totalReturn", formatFloat(s.TotalReturn)},
+		{"sharpeRatio", formatFloat(s.SharpeRatio)},
+		{"maxDrawdown", formatFloat(s.MaxDrawdown)},
+		{"turnover", formatFloat(s.Turnover)},
+		{"trades", strconv.Itoa(s.Trades)},
+		{"commission", s.Commission.String()},
+	})
+}
+
+// WriteEquityCSV :
+func (r *Result) WriteEquityCSV(w io.Writer) error {
+	rows := make([][]string, 0, len(r.Equity))
+	for _, point := range r.Equity {
+		rows = append(rows, []string{point.Time.Format(time.RFC3339Nano), point.Cash.String(), point.Equity.String()})
+	}
+	return writeCSV(w, []string{"time", "cash", "equity"}, rows)
+}
+
+// WriteTradesCSV :
+func (r *Result) WriteTradesCSV(w io.Writer) error {
+	rows := make([][]string, 0, len(r.Trades))
+	for _, trade := range r.Trades {
+		rows = append(rows, []string{
+			time.UnixMilli(trade.TradeTimeR).UTC().Format(time.RFC3339Nano),
+			trade.ExecutionId,
+			trade.Account,
+			strconv.Itoa(trade.ContractId),
+			trade.Side,
+			trade.Size.String(),
+			trade.Price.String(),
+			trade.Commission.String(),
+			trade.OrderRef,
+		})
+	}
+	return writeCSV(w, []string{"time", "executionId", "account", "conid", "side", "size", "price", "commission", "orderRef"}, rows)
+}
+
+func writeCSV(w io.Writer, header []string, rows [][]string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
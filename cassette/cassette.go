@@ -0,0 +1,103 @@
+// Package cassette records REST and websocket traffic of the SDK to redacted
+// JSONL cassettes and replays them without a gateway.
+//
+// Recording:
+//
+//	recorder := cassette.NewRecorder(file)
+//	recorder.Record(client)
+//	recorder.RecordWebsocket(wsClient)
+//
+// Replay, REST only or over a server from package cassettetest:
+//
+//	c, err := cassette.Open("incident.jsonl")
+//	client := c.Client()
+//	server := cassettetest.NewReplayServer(t, c)
+//	client, wsClient := server.Client(), server.WebsocketClient()
+package cassette
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+)
+
+const (
+	KindHTTP      = "http"
+	KindWebsocket = "websocket"
+
+	// DirectionRead is a message received from the gateway, DirectionWrite
+	// one sent to it.
+	DirectionRead  = "read"
+	DirectionWrite = "write"
+)
+
+// Interaction :
+// One line of a cassette: a REST exchange or a websocket message.
+type Interaction struct {
+	Kind string    `json:"kind"`
+	Time time.Time `json:"time"`
+
+	Method       string `json:"method,omitempty"`
+	Path         string `json:"path,omitempty"`
+	Query        string `json:"query,omitempty"`
+	RequestBody  string `json:"requestBody,omitempty"`
+	Status       int    `json:"status,omitempty"`
+	ResponseBody string `json:"responseBody,omitempty"`
+
+	Service string `json:"service,omitempty"`
+	// Connection numbers websocket connections in dial order, from 0.
+	Connection int    `json:"connection"`
+	Direction  string `json:"direction,omitempty"`
+	Message    string `json:"message,omitempty"`
+}
+
+// Cassette :
+type Cassette struct {
+	Interactions []Interaction
+}
+
+// Load :
+func Load(r io.Reader) (*Cassette, error) {
+	c := &Cassette{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var interaction Interaction
+		if err := json.Unmarshal(scanner.Bytes(), &interaction); err != nil {
+			return nil, err
+		}
+		c.Interactions = append(c.Interactions, interaction)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Open :
+// Loads the cassette file at path.
+func Open(path string) (*Cassette, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Load(file)
+}
+
+// WebsocketConnection :
+// Returns the websocket interactions of connection n in order.
+func (c *Cassette) WebsocketConnection(n int) []Interaction {
+	var result []Interaction
+	for _, interaction := range c.Interactions {
+		if interaction.Kind == KindWebsocket && interaction.Connection == n {
+			result = append(result, interaction)
+		}
+	}
+	return result
+}
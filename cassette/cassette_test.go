@@ -0,0 +1,209 @@
+package cassette_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+	"github.com/dictxwang/go-ibkr/cassette"
+	"github.com/dictxwang/go-ibkr/cassette/cassettetest"
+	"github.com/dictxwang/go-ibkr/ibkrtest"
+)
+
+const account = "DU1234567"
+
+// transcript is what a session saw of the gateway.
+type transcript struct {
+	positions []string
+	orderIds  []string
+	ticks     []string
+	orders    []string
+}
+
+// receive waits for count values of channel.
+func receive(t *testing.T, channel chan string, count int) []string {
+	t.Helper()
+	values := make([]string, 0, count)
+	for len(values) < count {
+		select {
+		case value := <-channel:
+			values = append(values, value)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("received %v, want %d values", values, count)
+		}
+	}
+	return values
+}
+
+// session reads positions, places an order through a confirmation prompt,
+// then streams market data and order updates until it shuts down. subscribed
+// runs once the order subscription is set up.
+func session(t *testing.T, client *ibkr.Client, wsClient *ibkr.WebSocketClient, subscribed func()) transcript {
+	t.Helper()
+	var result transcript
+
+	positions, err := client.Service().Portfolio().GetPositions(ibkr.GetPositionParam{AccountId: account})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, position := range *positions {
+		result.positions = append(result.positions, fmt.Sprintf("%s %d %s", position.AccountId, position.ContractId, position.Position))
+	}
+
+	order, err := ibkr.NewOrder(account, 265598).Buy(ibkr.MustParseDecimal("100")).Limit(ibkr.MustParseDecimal("187")).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	orders := client.Service().Order()
+	placed, err := orders.PlaceOrder([]ibkr.PlaceOrderParam{order})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if placed.AlternateResults == nil || len(*placed.AlternateResults) != 1 {
+		t.Fatalf("got %+v, want a prompt", placed)
+	}
+	confirmed, err := orders.PlaceOrderReplyConfirmation(ibkr.PlaceOrderReplyConfirmationParam{ReplyId: (*placed.AlternateResults)[0].Id, Confirmed: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, normal := range confirmed.NormalResults {
+		result.orderIds = append(result.orderIds, normal.OrderId)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{}, 2)
+	start := func(run func(context.Context, ibkr.ErrHandler) error) {
+		go func() {
+			defer func() { started <- struct{}{} }()
+			_ = run(ctx, nil)
+		}()
+	}
+
+	public, err := wsClient.Service().Public("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start(public.Start)
+	ticks := make(chan string, 4)
+	if _, err := public.SubscribeMarketData(ibkr.WebsocketPublicMarketDataParam{ContractIds: []int{265598}}, func(resp ibkr.WebsocketPublicMarketDataResponse) error {
+		ticks <- resp.Topic + " " + resp.LastPrice
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	result.ticks = receive(t, ticks, 2)
+
+	private, err := wsClient.Service().Private("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start(private.Start)
+	updates := make(chan string, 4)
+	if _, err := private.SubscribeOrderV2(func(resp ibkr.WebsocketPrivateOrderResponseV2) error {
+		for _, order := range resp.Orders {
+			updates <- fmt.Sprintf("%s %d %s", order.AccountId, order.OrderId, order.Status)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if subscribed != nil {
+		subscribed()
+	}
+	result.orders = receive(t, updates, 2)
+
+	cancel()
+	for range 2 {
+		select {
+		case <-started:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Start did not return after its context was cancelled")
+		}
+	}
+	return result
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	server := ibkrtest.NewServer(t)
+	server.SetPositions(account, []ibkr.PositionInfo{
+		{AccountId: account, ContractId: 265598, Position: ibkr.MustParseDecimal("100")},
+		{AccountId: account, ContractId: 8314, Position: ibkr.MustParseDecimal("-20")},
+	})
+	server.QueueOrderReplies(ibkrtest.OrderReplyChain{
+		Prompts: []ibkr.PlaceOrderAlternateResult{{Id: "reply-1", Message: []string{"Are you sure?"}}},
+		Results: []ibkr.PlaceOrderNormalResult{{OrderId: "11", OrderStatus: ibkr.OrderStatusSubmitted}},
+	})
+	server.SetMarketDataTicks(265598,
+		ibkr.WebsocketPublicMarketDataResponse{LastPrice: "187.25"},
+		ibkr.WebsocketPublicMarketDataResponse{LastPrice: "187.30"},
+	)
+
+	var buf bytes.Buffer
+	recorder := cassette.NewRecorder(&buf)
+	client := recorder.Record(server.Client())
+	wsClient := recorder.RecordWebsocket(server.WebsocketClient().WithRESTClient(client))
+	recorded := session(t, client, wsClient, func() {
+		server.WaitForWebsocketMessage(t, "sor+", time.Second)
+		if err := server.PushOrders(
+			ibkr.WebsocketPrivateOrder{AccountId: account, OrderId: 11, Status: "PreSubmitted"},
+			ibkr.WebsocketPrivateOrder{AccountId: account, OrderId: 11, Status: "Filled"},
+		); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if err := recorder.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), account) || strings.Contains(buf.String(), ibkrtest.SessionToken) {
+		t.Error("the cassette holds an account id or session token")
+	}
+
+	loaded, err := cassette.Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay := cassettetest.NewReplayServer(t, loaded)
+	replayed := session(t, replay.Client(), replay.WebsocketClient(), nil)
+
+	want := transcript{
+		positions: []string{account + " 265598 100", account + " 8314 -20"},
+		orderIds:  []string{"11"},
+		ticks:     []string{"smd+265598 187.25", "smd+265598 187.30"},
+		orders:    []string{account + " 11 PreSubmitted", account + " 11 Filled"},
+	}
+	if !reflect.DeepEqual(recorded, want) {
+		t.Errorf("recorded %+v, want %+v", recorded, want)
+	}
+	redacted := transcript{
+		positions: []string{"DU***567 265598 100", "DU***567 8314 -20"},
+		orderIds:  want.orderIds,
+		ticks:     want.ticks,
+		orders:    []string{"DU***567 11 PreSubmitted", "DU***567 11 Filled"},
+	}
+	if !reflect.DeepEqual(replayed, redacted) {
+		t.Errorf("replayed %+v, want %+v", replayed, redacted)
+	}
+}
+
+func TestCassetteTransport(t *testing.T) {
+	c, err := cassette.Load(strings.NewReader(`{"kind":"http","method":"GET","path":"/v1/api/portfolio/DU***567/positions/0","status":200,"responseBody":"[{\"conid\":265598,\"position\":5}]"}` + "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	portfolio := c.Client().Service().Portfolio()
+	positions, err := portfolio.GetPositions(ibkr.GetPositionParam{AccountId: account})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*positions) != 1 || (*positions)[0].Position.String() != "5" {
+		t.Errorf("positions %+v", *positions)
+	}
+	if _, err := portfolio.GetPositions(ibkr.GetPositionParam{AccountId: account}); err == nil {
+		t.Error("an interaction was replayed twice")
+	}
+}
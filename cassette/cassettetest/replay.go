@@ -0,0 +1,101 @@
+// Package cassettetest serves cassettes recorded with package cassette over
+// REST and websocket, so tests can replay an incident against real clients.
+package cassettetest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+	"github.com/dictxwang/go-ibkr/cassette"
+	"github.com/gorilla/websocket"
+)
+
+// ReplayServer :
+// Serves a cassette over REST and websocket. The n-th websocket connection
+// replays recorded connection n: each recorded write waits for a message of
+// the client, each recorded read is sent to it. Messages are matched by
+// order, not content.
+type ReplayServer struct {
+	*httptest.Server
+
+	cassette    *cassette.Cassette
+	player      *cassette.Player
+	connections atomic.Int32
+}
+
+// NewReplayServer :
+// Starts a replay server closed with tb's cleanup. tb may be nil, then call Close.
+func NewReplayServer(tb testing.TB, c *cassette.Cassette) *ReplayServer {
+	s := &ReplayServer{cassette: c, player: cassette.NewPlayer(c)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	if tb != nil {
+		tb.Cleanup(s.Close)
+	}
+	return s
+}
+
+// Client :
+// Returns a REST client for the server.
+func (s *ReplayServer) Client() *ibkr.Client {
+	return ibkr.NewClient(s.URL, "", true)
+}
+
+// WebsocketClient :
+// Returns a websocket client for the server. It fetches the session token
+// from the recorded /tickle.
+func (s *ReplayServer) WebsocketClient() *ibkr.WebSocketClient {
+	wsURL := "ws" + strings.TrimPrefix(s.URL, "http")
+	return ibkr.NewWebsocketClient(wsURL, "", true).WithRESTClient(s.Client())
+}
+
+func (s *ReplayServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.TrimPrefix(r.URL.Path, ibkr.DefaultPrefixEndpoint) == "/ws" {
+		s.serveWebsocket(w, r)
+		return
+	}
+
+	_, _ = io.Copy(io.Discard, r.Body)
+	interaction, err := s.player.Next(r.Method, r.URL.Path, r.URL.RawQuery)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(interaction.Status)
+	_, _ = w.Write([]byte(interaction.ResponseBody))
+}
+
+var upgrader = websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+
+func (s *ReplayServer) serveWebsocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	connection := int(s.connections.Add(1)) - 1
+	for _, interaction := range s.cassette.WebsocketConnection(connection) {
+		switch interaction.Direction {
+		case cassette.DirectionWrite:
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		case cassette.DirectionRead:
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(interaction.Message)); err != nil {
+				return
+			}
+		}
+	}
+	// keep the connection open until the client leaves
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+)
+
+// Recorder :
+// Writes interactions as redacted JSONL. It is safe for concurrent use.
+type Recorder struct {
+	mutex   sync.Mutex
+	encoder *json.Encoder
+	err     error
+
+	connections int
+	current     map[string]int
+}
+
+// NewRecorder :
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{encoder: json.NewEncoder(w), current: map[string]int{}}
+}
+
+// Err :
+// Returns the first write error.
+func (r *Recorder) Err() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.err
+}
+
+// Record :
+// Records the REST traffic of client.
+func (r *Recorder) Record(client *ibkr.Client) *ibkr.Client {
+	return client.WrapTransport(r.Transport)
+}
+
+// Transport :
+// Returns a transport recording every exchange sent through next.
+func (r *Recorder) Transport(next http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		var requestBody []byte
+		if req.Body != nil {
+			var err error
+			if requestBody, err = io.ReadAll(req.Body); err != nil {
+				return nil, err
+			}
+			_ = req.Body.Close()
+			req.Body = io.NopCloser(bytes.NewReader(requestBody))
+		}
+
+		resp, err := next.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		responseBody, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+		r.write(Interaction{
+			Kind:         KindHTTP,
+			Method:       req.Method,
+			Path:         ibkr.Redact(req.URL.Path),
+			Query:        ibkr.Redact(req.URL.RawQuery),
+			RequestBody:  ibkr.Redact(string(requestBody)),
+			Status:       resp.StatusCode,
+			ResponseBody: ibkr.Redact(string(responseBody)),
+		})
+		return resp, nil
+	})
+}
+
+// RecordWebsocket :
+// Records the websocket traffic of client, keeping the hooks already set on
+// it, e.g. those of otelibkr.
+func (r *Recorder) RecordWebsocket(client *ibkr.WebSocketClient) *ibkr.WebSocketClient {
+	return client.WithHooks(r.WebsocketHooks(client.Hooks()))
+}
+
+// WebsocketHooks :
+// Returns hooks recording every websocket message and then calling the
+// hooks of next, whose other funcs are kept as they are.
+func (r *Recorder) WebsocketHooks(next ibkr.WebsocketHooks) ibkr.WebsocketHooks {
+	hooks := next
+	hooks.OnConnect = func(service string, err error) {
+		if err == nil {
+			r.mutex.Lock()
+			r.current[service] = r.connections
+			r.connections++
+			r.mutex.Unlock()
+		}
+		if next.OnConnect != nil {
+			next.OnConnect(service, err)
+		}
+	}
+	hooks.OnRead = func(service string, message []byte) {
+		r.writeMessage(service, DirectionRead, message)
+		if next.OnRead != nil {
+			next.OnRead(service, message)
+		}
+	}
+	hooks.OnWrite = func(service string, message []byte) {
+		r.writeMessage(service, DirectionWrite, message)
+		if next.OnWrite != nil {
+			next.OnWrite(service, message)
+		}
+	}
+	return hooks
+}
+
+func (r *Recorder) writeMessage(service, direction string, message []byte) {
+	r.mutex.Lock()
+	connection := r.current[service]
+	r.mutex.Unlock()
+
+	r.write(Interaction{
+		Kind:       KindWebsocket,
+		Service:    service,
+		Connection: connection,
+		Direction:  direction,
+		Message:    ibkr.Redact(string(message)),
+	})
+}
+
+func (r *Recorder) write(interaction Interaction) {
+	interaction.Time = time.Now().UTC()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.err != nil {
+		return
+	}
+	r.err = r.encoder.Encode(interaction)
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,80 @@
+package cassette
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+)
+
+// Player :
+// Hands out recorded REST interactions in order. Each one is used once. It is
+// safe for concurrent use.
+type Player struct {
+	mutex    sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// NewPlayer :
+func NewPlayer(cassette *Cassette) *Player {
+	return &Player{cassette: cassette, used: make([]bool, len(cassette.Interactions))}
+}
+
+// Next :
+// Returns the first unused interaction matching method, path and query after
+// redaction.
+func (p *Player) Next(method, path, query string) (Interaction, error) {
+	path, query = ibkr.Redact(path), ibkr.Redact(query)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for i, interaction := range p.cassette.Interactions {
+		if p.used[i] || interaction.Kind != KindHTTP {
+			continue
+		}
+		if interaction.Method == method && interaction.Path == path && interaction.Query == query {
+			p.used[i] = true
+			return interaction, nil
+		}
+	}
+	return Interaction{}, fmt.Errorf("cassette: no recorded interaction for %s %s?%s", method, path, query)
+}
+
+// Transport :
+// Returns a transport answering from the cassette without a network. A
+// request that was not recorded, or was already replayed, fails.
+func (c *Cassette) Transport() http.RoundTripper {
+	p := NewPlayer(c)
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		interaction, err := p.Next(req.Method, req.URL.Path, req.URL.RawQuery)
+		if err != nil {
+			return nil, err
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Status, http.StatusText(interaction.Status)),
+			StatusCode:    interaction.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": []string{"application/json"}},
+			Body:          io.NopCloser(strings.NewReader(interaction.ResponseBody)),
+			ContentLength: int64(len(interaction.ResponseBody)),
+			Request:       req,
+		}, nil
+	})
+}
+
+// Client :
+// Returns a REST client replaying the cassette through Transport.
+func (c *Cassette) Client() *ibkr.Client {
+	return ibkr.NewClient("http://cassette", "", true).WrapTransport(func(http.RoundTripper) http.RoundTripper {
+		return c.Transport()
+	})
+}
@@ -2,11 +2,12 @@ package ibkr
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
@@ -23,7 +24,7 @@ type Client struct {
 	httpClient *http.Client
 
 	debug  bool
-	logger *log.Logger
+	logger *slog.Logger
 
 	baseURL        string
 	endpointPrefix string
@@ -31,12 +32,22 @@ type Client struct {
 	secret         string
 
 	referer string
+
+	middleware []Middleware
+
+	// transportWrappers are applied in order over baseTransport, so
+	// WithTLSConfig can swap the transport without losing them
+	transportWrappers []func(next http.RoundTripper) http.RoundTripper
+	baseTransport     http.RoundTripper
 }
 
-func (c *Client) debugf(format string, v ...interface{}) {
-	if c.debug {
-		c.logger.Printf(format, v...)
+// log :
+// Returns the client logger, falling back to the package logger.
+func (c *Client) log() *slog.Logger {
+	if c.logger != nil {
+		return c.logger
 	}
+	return packageLogger()
 }
 
 // NewClient :
@@ -73,33 +84,101 @@ func NewClient(restBaseUrl, restEndpointPrefix string, skipTlsVerify bool) *Clie
 
 	return &Client{
 		httpClient:     httpClient,
-		logger:         newDefaultLogger(),
 		baseURL:        baseUrl,
 		endpointPrefix: endpointPrefix,
 	}
 }
 
+// NewClientWithTLS :
+// Creates a client verifying the gateway with options instead of skipping verification.
+func NewClientWithTLS(restBaseUrl, restEndpointPrefix string, options TLSOptions) (*Client, error) {
+	tlsConfig, err := NewTLSConfig(options)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(restBaseUrl, restEndpointPrefix, false).WithTLSConfig(tlsConfig), nil
+}
+
 // NewDefaultClient :
 func NewDefaultClient() *Client {
 	return NewClient("", "", true)
 }
 
 // WithHTTPClient :
+// Uses httpClient as is, dropping transports wrapped so far.
 func (c *Client) WithHTTPClient(httpClient *http.Client) *Client {
 	c.httpClient = httpClient
+	c.transportWrappers = nil
+	c.baseTransport = nil
 
 	return c
 }
 
+// WrapTransport :
+// Wraps the transport of the HTTP client, e.g. to record traffic. A nil
+// transport is passed as http.DefaultTransport. Wrappers stay in place when
+// WithTLSConfig is called afterwards.
+func (c *Client) WrapTransport(wrap func(next http.RoundTripper) http.RoundTripper) *Client {
+	if len(c.transportWrappers) == 0 {
+		c.baseTransport = c.httpClient.Transport
+	}
+	c.transportWrappers = append(c.transportWrappers, wrap)
+	c.setTransport()
+
+	return c
+}
+
+// WithTLSConfig :
+// Uses config for connections to the gateway, see NewTLSConfig. The
+// *http.Transport under the wrappers of WrapTransport is cloned with config,
+// any other transport is replaced by a clone of http.DefaultTransport. The
+// HTTP client is copied, so one passed to WithHTTPClient is left unchanged.
+func (c *Client) WithTLSConfig(config *tls.Config) *Client {
+	base := c.httpClient.Transport
+	if len(c.transportWrappers) > 0 {
+		base = c.baseTransport
+	}
+	var transport *http.Transport
+	if t, ok := base.(*http.Transport); ok {
+		transport = t.Clone()
+	} else {
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	transport.TLSClientConfig = config
+	c.baseTransport = transport
+	c.setTransport()
+
+	return c
+}
+
+// setTransport :
+// Installs the wrappers over the base transport on a copy of the HTTP client.
+func (c *Client) setTransport() {
+	next := c.baseTransport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	for _, wrap := range c.transportWrappers {
+		next = wrap(next)
+	}
+	httpClient := *c.httpClient
+	httpClient.Transport = next
+	c.httpClient = &httpClient
+}
+
 // WithDebug :
+// Logs redacted response bodies at debug level. Without a logger they go to stderr.
 func (c *Client) WithDebug(debug bool) *Client {
 	c.debug = debug
+	if debug && c.logger == nil {
+		c.logger = newDefaultLogger()
+	}
 
 	return c
 }
 
 // WithLogger :
-func (c *Client) WithLogger(logger *log.Logger) *Client {
+func (c *Client) WithLogger(logger *slog.Logger) *Client {
 	c.debug = true
 	c.logger = logger
 
@@ -127,13 +206,20 @@ func (c *Client) Request(req *http.Request, dst interface{}) error {
 
 // RequestFull :
 func (c *Client) RequestFull(req *http.Request, conciseResponse bool, dst interface{}) ([]byte, error) {
-	c.debugf("request: %v", req)
-	resp, err := c.httpClient.Do(req)
-	c.debugf("response: %v", resp)
+	operation, ok := OperationFromContext(req.Context())
+	if !ok {
+		operation = Operation{Endpoint: Endpoint{Template: req.URL.Path, Path: req.URL.Path}}
+	}
+	logger := c.log().With("method", req.Method, "operation", operation.Name, "endpoint", operation.Endpoint.Template)
+
+	start := time.Now()
+	resp, err := c.handler()(operation, req)
+	latency := time.Since(start)
 	if err != nil {
+		logger.Warn("ibkr request failed", "latency", latency, "error", Redact(err.Error()))
 		return nil, err
 	}
-	c.debugf("response status code: %v", resp.StatusCode)
+	logger.Debug("ibkr request", "status", resp.StatusCode, "latency", latency)
 	defer func() {
 		cerr := resp.Body.Close()
 		if err == nil && cerr != nil {
@@ -147,8 +233,7 @@ func (c *Client) RequestFull(req *http.Request, conciseResponse bool, dst interf
 		if err != nil {
 			return nil, err
 		}
-		fmt.Printf("response body: %+v\n", string(body))
-		c.debugf("response body: %v", string(body))
+		c.logBody(logger, body)
 
 		if conciseResponse {
 			return body, nil
@@ -165,8 +250,7 @@ func (c *Client) RequestFull(req *http.Request, conciseResponse bool, dst interf
 			if err != nil {
 				return nil, err
 			}
-			fmt.Printf("response body: %+v\n", string(body))
-			c.debugf("response body: %v", string(body))
+			c.logBody(logger, body)
 
 			if conciseResponse {
 				return body, nil
@@ -178,28 +262,40 @@ func (c *Client) RequestFull(req *http.Request, conciseResponse bool, dst interf
 				}
 			}
 		} else {
+			logger.Warn("ibkr request rejected", "status", resp.StatusCode)
 			return nil, fmt.Errorf("%v: Need to send the request with GET / POST (must be capitalized) url=%s", ErrBadRequest, req.URL.String())
 		}
 	case resp.StatusCode == http.StatusUnauthorized:
+		logger.Warn("ibkr request rejected", "status", resp.StatusCode)
 		return nil, fmt.Errorf("%w: invalid key/secret", ErrInvalidRequest)
 	case resp.StatusCode == http.StatusForbidden:
+		logger.Warn("ibkr request rejected", "status", resp.StatusCode)
 		return nil, fmt.Errorf("%w: not permitted", ErrForbiddenRequest)
 	case resp.StatusCode == http.StatusNotFound:
+		logger.Warn("ibkr request rejected", "status", resp.StatusCode)
 		return nil, fmt.Errorf("%w: wrong path", ErrPathNotFound)
 	default:
+		logger.Warn("ibkr request rejected", "status", resp.StatusCode)
 		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
 	}
 }
 
-func (c *Client) getPublic(path string, query url.Values, dst interface{}) error {
+// logBody :
+func (c *Client) logBody(logger *slog.Logger, body []byte) {
+	if c.debug {
+		logger.Debug("ibkr response body", "body", Redact(string(body)))
+	}
+}
+
+func (c *Client) getPublic(operation Operation, query url.Values, dst interface{}) error {
 	u, err := url.Parse(c.baseURL)
 	if err != nil {
 		return err
 	}
-	u.Path = c.endpointPrefix + path
+	u.Path = c.endpointPrefix + operation.Endpoint.Path
 	u.RawQuery = query.Encode()
 
-	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	req, err := http.NewRequestWithContext(withOperation(context.Background(), operation), http.MethodGet, u.String(), nil)
 	if err != nil {
 		return err
 	}
@@ -211,16 +307,16 @@ func (c *Client) getPublic(path string, query url.Values, dst interface{}) error
 	return nil
 }
 
-func (c *Client) deletePublic(path string, query url.Values, dst interface{}) error {
+func (c *Client) deletePublic(operation Operation, query url.Values, dst interface{}) error {
 
 	u, err := url.Parse(c.baseURL)
 	if err != nil {
 		return err
 	}
-	u.Path = c.endpointPrefix + path
+	u.Path = c.endpointPrefix + operation.Endpoint.Path
 	u.RawQuery = query.Encode()
 
-	req, err := http.NewRequest(http.MethodDelete, u.String(), nil)
+	req, err := http.NewRequestWithContext(withOperation(context.Background(), operation), http.MethodDelete, u.String(), nil)
 	if err != nil {
 		return err
 	}
@@ -232,15 +328,15 @@ func (c *Client) deletePublic(path string, query url.Values, dst interface{}) er
 	return nil
 }
 
-func (c *Client) postJSON(path string, body []byte, dst interface{}) error {
+func (c *Client) postJSON(operation Operation, body []byte, dst interface{}) error {
 
 	u, err := url.Parse(c.baseURL)
 	if err != nil {
 		return err
 	}
-	u.Path = c.endpointPrefix + path
+	u.Path = c.endpointPrefix + operation.Endpoint.Path
 
-	req, err := http.NewRequest(http.MethodPost, u.String(), bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(withOperation(context.Background(), operation), http.MethodPost, u.String(), bytes.NewBuffer(body))
 	if err != nil {
 		return err
 	}
@@ -253,15 +349,15 @@ func (c *Client) postJSON(path string, body []byte, dst interface{}) error {
 	return nil
 }
 
-func (c *Client) postJSONConciseResponse(path string, body []byte) ([]byte, error) {
+func (c *Client) postJSONConciseResponse(operation Operation, body []byte) ([]byte, error) {
 
 	u, err := url.Parse(c.baseURL)
 	if err != nil {
 		return nil, err
 	}
-	u.Path = c.endpointPrefix + path
+	u.Path = c.endpointPrefix + operation.Endpoint.Path
 
-	req, err := http.NewRequest(http.MethodPost, u.String(), bytes.NewBuffer(body))
+	req, err := http.NewRequestWithContext(withOperation(context.Background(), operation), http.MethodPost, u.String(), bytes.NewBuffer(body))
 	if err != nil {
 		return nil, err
 	}
@@ -271,15 +367,15 @@ func (c *Client) postJSONConciseResponse(path string, body []byte) ([]byte, erro
 	return respBody, err
 }
 
-func (c *Client) postForm(path string, body url.Values, dst interface{}) error {
+func (c *Client) postForm(operation Operation, body url.Values, dst interface{}) error {
 
 	u, err := url.Parse(c.baseURL)
 	if err != nil {
 		return nil
 	}
-	u.Path = path
+	u.Path = operation.Endpoint.Path
 
-	req, err := http.NewRequest(http.MethodPost, u.String(), strings.NewReader(body.Encode()))
+	req, err := http.NewRequestWithContext(withOperation(context.Background(), operation), http.MethodPost, u.String(), strings.NewReader(body.Encode()))
 	if err != nil {
 		return err
 	}
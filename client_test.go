@@ -0,0 +1,102 @@
+package ibkr_test
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+	"github.com/dictxwang/go-ibkr/ibkrtest"
+)
+
+type countingTransport struct {
+	next  http.RoundTripper
+	count *atomic.Int32
+}
+
+func (t countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.count.Add(1)
+	return t.next.RoundTrip(req)
+}
+
+func TestClientWithTLSConfig(t *testing.T) {
+	server := ibkrtest.NewTLSServer(t)
+	pinned, err := ibkr.NewTLSConfig(ibkr.TLSOptions{
+		PinnedSHA256: []string{ibkr.CertificateFingerprint(server.Certificate())},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := ibkr.NewTLSConfig(ibkr.TLSOptions{
+		PinnedSHA256: []string{"00112233445566778899aabbccddeeff00112233445566778899aabbccddeeff"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		client  func(count *atomic.Int32) *ibkr.Client
+		wantErr error
+		// wantCount is the number of requests seen by the wrapping transport
+		wantCount int32
+	}{
+		{
+			name: "pinned",
+			client: func(*atomic.Int32) *ibkr.Client {
+				return ibkr.NewClient(server.URL, "", false).WithTLSConfig(pinned)
+			},
+		},
+		{
+			name: "not pinned",
+			client: func(*atomic.Int32) *ibkr.Client {
+				return ibkr.NewClient(server.URL, "", false).WithTLSConfig(other)
+			},
+			wantErr: ibkr.ErrCertificateNotPinned,
+		},
+		{
+			name: "wrapped before",
+			client: func(count *atomic.Int32) *ibkr.Client {
+				return ibkr.NewClient(server.URL, "", false).
+					WrapTransport(func(next http.RoundTripper) http.RoundTripper { return countingTransport{next, count} }).
+					WithTLSConfig(pinned)
+			},
+			wantCount: 1,
+		},
+		{
+			name: "wrapped after",
+			client: func(count *atomic.Int32) *ibkr.Client {
+				return ibkr.NewClient(server.URL, "", false).
+					WithTLSConfig(pinned).
+					WrapTransport(func(next http.RoundTripper) http.RoundTripper { return countingTransport{next, count} })
+			},
+			wantCount: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count := &atomic.Int32{}
+			_, err := tt.client(count).Service().Session().PostAuthStatus()
+			if tt.wantErr == nil && err != nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if got := count.Load(); got != tt.wantCount {
+				t.Errorf("wrapping transport saw %d requests, want %d", got, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestClientWithTLSConfigCopiesHTTPClient(t *testing.T) {
+	transport := &http.Transport{}
+	httpClient := &http.Client{Transport: transport}
+	config, err := ibkr.NewTLSConfig(ibkr.TLSOptions{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ibkr.NewClient("", "", false).WithHTTPClient(httpClient).WithTLSConfig(config)
+	if httpClient.Transport != transport || transport.TLSClientConfig != nil && transport.TLSClientConfig.InsecureSkipVerify {
+		t.Error("WithTLSConfig changed the HTTP client passed to WithHTTPClient")
+	}
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	namespace = "ibkr"
+	// positionsPageSize is the page size of /portfolio/{accountId}/positions/{pageId}
+	positionsPageSize = 100
+	maxPositionPages  = 50
+)
+
+type exporter struct {
+	client   *ibkr.Client
+	accounts []string
+	logger   *slog.Logger
+	started  time.Time
+
+	mutex   sync.Mutex
+	monitor *ibkr.WebsocketMonitor
+
+	up                  *prometheus.GaugeVec
+	pollErrors          *prometheus.CounterVec
+	netLiquidation      *prometheus.GaugeVec
+	excessLiquidity     *prometheus.GaugeVec
+	dailyPnL            *prometheus.GaugeVec
+	unrealizedPnL       *prometheus.GaugeVec
+	positions           *prometheus.GaugeVec
+	positionMarketValue *prometheus.GaugeVec
+	openOrders          *prometheus.GaugeVec
+	authenticated       prometheus.Gauge
+	competing           prometheus.Gauge
+	connected           prometheus.Gauge
+	websocketUp         prometheus.Gauge
+	reconnects          prometheus.Counter
+	heartbeatAge        prometheus.GaugeFunc
+}
+
+func newExporter(client *ibkr.Client, accounts []string, logger *slog.Logger) *exporter {
+	e := &exporter{
+		client:   client,
+		accounts: accounts,
+		logger:   logger,
+		started:  time.Now(),
+	}
+	gauge := func(name, help string, labels ...string) *prometheus.GaugeVec {
+		return prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: namespace, Name: name, Help: help}, labels)
+	}
+	e.up = gauge("up", "Whether the last poll of source succeeded.", "source")
+	e.pollErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Name: "poll_errors_total", Help: "Failed polls by source.",
+	}, []string{"source"})
+	e.netLiquidation = gauge("net_liquidation", "Net liquidation value.", "account")
+	e.excessLiquidity = gauge("excess_liquidity", "Excess liquidity.", "account")
+	e.dailyPnL = gauge("daily_pnl", "Daily PnL.", "account")
+	e.unrealizedPnL = gauge("unrealized_pnl", "Unrealized PnL.", "account")
+	e.positions = gauge("positions", "Number of open positions.", "account")
+	e.positionMarketValue = gauge("position_market_value", "Market value of open positions.", "account", "currency")
+	e.openOrders = gauge("open_orders", "Live orders by status.", "account", "status")
+	e.authenticated = prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "authenticated", Help: "Whether the brokerage session is authenticated."})
+	e.competing = prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "competing", Help: "Whether another session competes for the brokerage session."})
+	e.connected = prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "connected", Help: "Whether the gateway is connected to the backend."})
+	e.websocketUp = prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: "websocket_up", Help: "Whether the websocket is connected."})
+	e.reconnects = prometheus.NewCounter(prometheus.CounterOpts{Namespace: namespace, Name: "websocket_reconnects_total", Help: "Websocket reconnects."})
+	e.heartbeatAge = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace, Name: "websocket_heartbeat_age_seconds",
+		Help: "Seconds since the last websocket heartbeat, or since start when none arrived.",
+	}, e.heartbeatAgeSeconds)
+	return e
+}
+
+func (e *exporter) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		e.up, e.pollErrors,
+		e.netLiquidation, e.excessLiquidity, e.dailyPnL, e.unrealizedPnL,
+		e.positions, e.positionMarketValue, e.openOrders,
+		e.authenticated, e.competing, e.connected,
+		e.websocketUp, e.reconnects, e.heartbeatAge,
+	}
+}
+
+// poll :
+// Polls the REST endpoints every interval until ctx is done.
+func (e *exporter) poll(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		e.pollOnce()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *exporter) pollOnce() {
+	service := e.client.Service()
+
+	status, err := service.Session().PostAuthStatus()
+	if e.record("auth_status", err) {
+		e.authenticated.Set(boolValue(status.Authenticated))
+		e.competing.Set(boolValue(status.Competing))
+		e.connected.Set(boolValue(status.Connected))
+	}
+
+	pnl, err := service.Account().GetProfitAndLoss()
+	if e.record("pnl", err) {
+		e.setPnL(pnl.UserPnL)
+	}
+
+	accounts, err := e.accountIds()
+	if e.record("accounts", err) {
+		for _, account := range accounts {
+			e.pollPositions(account)
+		}
+	}
+
+	orders, err := service.OrderMonitoring().GetLiveOrders(ibkr.GetLiveOrdersParam{})
+	if e.record("orders", err) {
+		e.openOrders.Reset()
+		for _, order := range orders.Orders {
+			e.openOrders.WithLabelValues(order.AccountId, order.Status).Inc()
+		}
+	}
+}
+
+func (e *exporter) accountIds() ([]string, error) {
+	if len(e.accounts) > 0 {
+		return e.accounts, nil
+	}
+	accounts, err := e.client.Service().Portfolio().GetAccounts()
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, account := range *accounts {
+		ids = append(ids, account.AccountId)
+	}
+	return ids, nil
+}
+
+func (e *exporter) pollPositions(account string) {
+	var (
+		count       int
+		marketValue = map[string]ibkr.Decimal{}
+	)
+	for page := 0; page < maxPositionPages; page++ {
+		positions, err := e.client.Service().Portfolio().GetPositions(ibkr.GetPositionParam{AccountId: account, PageId: page})
+		if !e.record("positions", err) {
+			return
+		}
+		for _, position := range *positions {
+			if position.Position.IsZero() {
+				continue
+			}
+			count++
+			marketValue[position.Currency] = marketValue[position.Currency].Add(position.MarketValue)
+		}
+		if len(*positions) < positionsPageSize {
+			break
+		}
+	}
+	e.positions.WithLabelValues(account).Set(float64(count))
+	e.positionMarketValue.DeletePartialMatch(prometheus.Labels{"account": account})
+	for currency, value := range marketValue {
+		e.positionMarketValue.WithLabelValues(account, currency).Set(value.Float64())
+	}
+}
+
+// setPnL :
+// Sets the PnL gauges from keys like "DU123.Core", as sent by both
+// /iserver/account/pnl/partitioned and the spl topic.
+func (e *exporter) setPnL(pnl map[string]ibkr.AccountProfitAndLossInfo) {
+	for key, info := range pnl {
+		account := strings.TrimSuffix(key, ".Core")
+		e.netLiquidation.WithLabelValues(account).Set(info.NetLiquidity)
+		e.excessLiquidity.WithLabelValues(account).Set(info.ExcessLiquidity)
+		e.dailyPnL.WithLabelValues(account).Set(info.DailyPnL)
+		e.unrealizedPnL.WithLabelValues(account).Set(info.UnPnL)
+	}
+}
+
+// record :
+// Records the outcome of a poll and returns whether it succeeded.
+func (e *exporter) record(source string, err error) bool {
+	if err != nil {
+		e.logger.Warn("poll failed", "source", source, "error", err)
+		e.pollErrors.WithLabelValues(source).Inc()
+		e.up.WithLabelValues(source).Set(0)
+		return false
+	}
+	e.up.WithLabelValues(source).Set(1)
+	return true
+}
+
+// stream :
+// Keeps a private websocket open for spl updates and heartbeats, reconnecting
+// until ctx is done.
+func (e *exporter) stream(ctx context.Context, wsClient *ibkr.WebSocketClient, staleAfter time.Duration) {
+	monitor := ibkr.NewWebsocketMonitor(ibkr.WebsocketMonitorConfig{
+		ConnectionStaleAfter:   staleAfter,
+		CloseOnConnectionStale: true,
+	})
+	e.mutex.Lock()
+	e.monitor = monitor
+	e.mutex.Unlock()
+
+	for first := true; ctx.Err() == nil; first = false {
+		if !first {
+			e.reconnects.Inc()
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(5 * time.Second):
+			}
+		}
+		if err := e.streamOnce(ctx, wsClient, monitor); err != nil {
+			e.logger.Warn("websocket failed", "error", err)
+		}
+	}
+}
+
+func (e *exporter) streamOnce(ctx context.Context, wsClient *ibkr.WebSocketClient, monitor *ibkr.WebsocketMonitor) error {
+	service, err := wsClient.Service().Private("")
+	if err != nil {
+		return err
+	}
+	service.SetMonitor(monitor)
+	if _, err := service.SubscribePnL(func(resp ibkr.WebsocketPrivatePnLResponse) error {
+		buf, err := json.Marshal(resp.Args)
+		if err != nil {
+			return err
+		}
+		var pnl map[string]ibkr.AccountProfitAndLossInfo
+		if err := json.Unmarshal(buf, &pnl); err != nil {
+			return err
+		}
+		e.setPnL(pnl)
+		return nil
+	}); err != nil {
+		_ = service.Close()
+		return err
+	}
+
+	e.websocketUp.Set(1)
+	defer e.websocketUp.Set(0)
+	return service.Start(ctx, func(isWebsocketClosed bool, err error) {
+		if !isWebsocketClosed {
+			e.logger.Warn("websocket read failed", "error", err)
+		}
+	})
+}
+
+func (e *exporter) heartbeatAgeSeconds() float64 {
+	e.mutex.Lock()
+	monitor := e.monitor
+	e.mutex.Unlock()
+
+	since := e.started
+	if monitor != nil {
+		if last := monitor.LastHeartbeat(); !last.IsZero() {
+			since = last
+		}
+	}
+	return time.Since(since).Seconds()
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
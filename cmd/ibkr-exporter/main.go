@@ -0,0 +1,80 @@
+// Command ibkr-exporter exposes account and connection health of a Client
+// Portal gateway as Prometheus metrics on /metrics.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+func main() {
+	var (
+		listen         = flag.String("listen", ":9464", "address serving /metrics")
+		baseURL        = flag.String("base-url", ibkr.DefaultBaseUrl, "gateway REST base URL")
+		websocketURL   = flag.String("websocket-url", ibkr.DefaultWebsocketBaseURL, "gateway websocket base URL, empty disables the websocket")
+		insecure       = flag.Bool("insecure", true, "skip TLS verification of the self-signed gateway certificate")
+		interval       = flag.Duration("interval", 30*time.Second, "REST poll interval")
+		accounts       = flag.String("accounts", "", "comma separated account ids, all portfolio accounts by default")
+		debug          = flag.Bool("debug", false, "log requests at debug level")
+		staleHeartbeat = flag.Duration("heartbeat-stale-after", ibkr.DefaultMonitorConnectionStaleAfter, "reconnect the websocket after this long without a heartbeat")
+	)
+	flag.Parse()
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	if *debug {
+		logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+	ibkr.SetLogger(logger)
+
+	client := ibkr.NewClient(*baseURL, "", *insecure)
+	registry := prometheus.NewRegistry()
+	exporter := newExporter(client, splitAccounts(*accounts), logger)
+	registry.MustRegister(exporter.collectors()...)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go exporter.poll(ctx, *interval)
+	if *websocketURL != "" {
+		wsClient := ibkr.NewWebsocketClient(*websocketURL, "", *insecure).WithRESTClient(client)
+		go exporter.stream(ctx, wsClient, *staleHeartbeat)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	server := &http.Server{Addr: *listen, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info("serving metrics", "listen", *listen)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("serve metrics", "error", err)
+		os.Exit(1)
+	}
+}
+
+func splitAccounts(accounts string) []string {
+	var result []string
+	for _, account := range strings.Split(accounts, ",") {
+		if account = strings.TrimSpace(account); account != "" {
+			result = append(result, account)
+		}
+	}
+	return result
+}
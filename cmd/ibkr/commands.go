@@ -0,0 +1,375 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+)
+
+// positionsPageSize is the page size of /portfolio/{accountId}/positions/{pageId}
+const positionsPageSize = 100
+
+func newFlags(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	return flags
+}
+
+// decimalFlag :
+// Defines a flag holding an exact decimal, 0 if not set.
+func decimalFlag(flags *flag.FlagSet, name, usage string) *ibkr.Decimal {
+	value := new(ibkr.Decimal)
+	flags.Func(name, usage, func(s string) error {
+		parsed, err := ibkr.ParseDecimal(s)
+		if err != nil {
+			return err
+		}
+		*value = parsed
+		return nil
+	})
+	return value
+}
+
+func intArg(args []string, name string) (int, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("want exactly one %s", name)
+	}
+	value, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", name, args[0])
+	}
+	return value, nil
+}
+
+func authStatus(a *app, args []string) error {
+	status, err := a.service.Session().PostAuthStatus()
+	if err != nil {
+		return err
+	}
+	t := &table{raw: status, headers: []string{"authenticated", "competing", "connected", "message", "server"}}
+	t.add(status.Authenticated, status.Competing, status.Connected, status.Message, status.ServerInfo.ServerName)
+	return t.write(a.stdout, a.output)
+}
+
+func authTickle(a *app, args []string) error {
+	resp, err := a.service.Session().PostPingServer()
+	if err != nil {
+		return err
+	}
+	// the session token authenticates websockets, keep it out of terminals and logs
+	resp.Session = ""
+	status := resp.IServer.AuthStatus
+	t := &table{raw: resp, headers: []string{"authenticated", "competing", "connected", "ssoExpires", "userId"}}
+	t.add(status.Authenticated, status.Competing, status.Connected, resp.SsoExpires, resp.UserId)
+	return t.write(a.stdout, a.output)
+}
+
+func authReauth(a *app, args []string) error {
+	resp, err := a.service.Session().PostReauthenticate()
+	if err != nil {
+		return err
+	}
+	t := &table{raw: resp, headers: []string{"message"}}
+	t.add(resp.Message)
+	return t.write(a.stdout, a.output)
+}
+
+func authLogout(a *app, args []string) error {
+	resp, err := a.service.Session().PostLogout()
+	if err != nil {
+		return err
+	}
+	t := &table{raw: resp, headers: []string{"status"}}
+	t.add(resp.Status)
+	return t.write(a.stdout, a.output)
+}
+
+func accounts(a *app, args []string) error {
+	accounts, err := a.service.Portfolio().GetAccounts()
+	if err != nil {
+		return err
+	}
+	t := &table{raw: accounts, headers: []string{"account", "alias", "title", "currency", "type"}}
+	for _, account := range *accounts {
+		t.add(account.AccountId, account.AccountAlias, account.AccountTitle, account.Currency, account.AccountType)
+	}
+	return t.write(a.stdout, a.output)
+}
+
+func positions(a *app, args []string) error {
+	flags := newFlags("positions")
+	account := flags.String("account", "", "account id")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	accountId, err := a.accountId(*account)
+	if err != nil {
+		return err
+	}
+
+	var all []ibkr.PositionInfo
+	for page := 0; ; page++ {
+		positions, err := a.service.Portfolio().GetPositions(ibkr.GetPositionParam{AccountId: accountId, PageId: page})
+		if err != nil {
+			return err
+		}
+		all = append(all, *positions...)
+		if len(*positions) < positionsPageSize {
+			break
+		}
+	}
+
+	t := &table{raw: all, headers: []string{"conid", "description", "position", "price", "value", "currency", "avgCost", "unrealizedPnl"}}
+	for _, position := range all {
+		t.add(position.ContractId, position.ContractDesc, position.Position, position.MarketPrice,
+			position.MarketValue, position.Currency, position.AverageCost, position.UnrealizedPnl)
+	}
+	return t.write(a.stdout, a.output)
+}
+
+func ledger(a *app, args []string) error {
+	flags := newFlags("ledger")
+	account := flags.String("account", "", "account id")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	accountId, err := a.accountId(*account)
+	if err != nil {
+		return err
+	}
+	ledger, err := a.service.Portfolio().GetLedger(accountId)
+	if err != nil {
+		return err
+	}
+
+	currencies := make([]string, 0, len(*ledger))
+	for currency := range *ledger {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	t := &table{raw: ledger, headers: []string{"currency", "cash", "settledCash", "netLiquidation", "stockValue", "unrealizedPnl", "realizedPnl", "exchangeRate"}}
+	for _, currency := range currencies {
+		item := (*ledger)[currency]
+		t.add(currency, item.CashBalance, item.SettledCash, item.NetLiquidationValue, item.StockMarketValue,
+			item.UnrealizedPnl, item.RealizedPnl, item.ExchangeRate)
+	}
+	return t.write(a.stdout, a.output)
+}
+
+func ordersList(a *app, args []string) error {
+	flags := newFlags("orders list")
+	status := flags.String("status", "", "comma separated status filters, e.g. submitted,filled")
+	force := flags.Bool("force", false, "clear the cached order list first")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	param := ibkr.GetLiveOrdersParam{Force: *force}
+	for _, filter := range strings.Split(*status, ",") {
+		if filter = strings.TrimSpace(filter); filter != "" {
+			param.StatusValueFilters = append(param.StatusValueFilters, ibkr.OrderStatusFilterValue(filter))
+		}
+	}
+	orders, err := a.service.OrderMonitoring().GetLiveOrders(param)
+	if err != nil {
+		return err
+	}
+
+	t := &table{raw: orders, headers: []string{"orderId", "account", "ticker", "side", "type", "avgPrice", "filled", "remaining", "status"}}
+	for _, order := range orders.Orders {
+		t.add(order.OrderId, order.AccountId, order.Ticker, order.Side, order.OrderType, order.AveragePrice,
+			order.FilledQuantity, order.RemainingQuantity, order.Status)
+	}
+	return t.write(a.stdout, a.output)
+}
+
+func ordersStatus(a *app, args []string) error {
+	orderId, err := intArg(args, "order id")
+	if err != nil {
+		return err
+	}
+	status, err := a.service.OrderMonitoring().GetStatus(orderId)
+	if err != nil {
+		return err
+	}
+	t := &table{raw: status, headers: []string{"orderId", "account", "symbol", "side", "type", "size", "filled", "status"}}
+	t.add(status.OrderId, status.AccountId, status.Symbol, status.Side, status.OrderType, status.TotalSize,
+		status.CumulativeFill, status.OrderStatus)
+	return t.write(a.stdout, a.output)
+}
+
+func ordersCancel(a *app, args []string) error {
+	flags := newFlags("orders cancel")
+	account := flags.String("account", "", "account id")
+	yes := flags.Bool("yes", false, "cancel without confirmation")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	orderId, err := intArg(flags.Args(), "order id")
+	if err != nil {
+		return err
+	}
+	accountId, err := a.accountId(*account)
+	if err != nil {
+		return err
+	}
+	if !*yes && !a.confirm(fmt.Sprintf("Cancel order %d of account %s?", orderId, accountId)) {
+		return errAborted
+	}
+
+	resp, err := a.service.Order().CancelOrder(ibkr.CancelOrderParam{AccountId: accountId, OrderId: int64(orderId)})
+	if err != nil {
+		return err
+	}
+	if resp.Error != "" {
+		return errors.New(resp.Error)
+	}
+	t := &table{raw: resp, headers: []string{"orderId", "account", "conid", "message"}}
+	t.add(resp.OrderId, resp.AccountId, resp.ContractId, resp.Msg)
+	return t.write(a.stdout, a.output)
+}
+
+func trades(a *app, args []string) error {
+	flags := newFlags("trades")
+	days := flags.Int("days", 0, "days of executions, up to 7; today only by default")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	param := ibkr.GetTradesParam{}
+	if *days > 0 {
+		param.Days = days
+	}
+	trades, err := a.service.OrderMonitoring().GetTrades(param)
+	if err != nil {
+		return err
+	}
+
+	t := &table{raw: trades, headers: []string{"time", "executionId", "account", "symbol", "side", "size", "price", "commission", "exchange"}}
+	for _, trade := range *trades {
+		t.add(trade.TradeTime, trade.ExecutionId, trade.Account, trade.Symbol, trade.Side, trade.Size,
+			trade.Price, trade.Commission, trade.Exchange)
+	}
+	return t.write(a.stdout, a.output)
+}
+
+func contractSearch(a *app, args []string) error {
+	flags := newFlags("contract search")
+	secType := flags.String("sectype", "", "security type, e.g. STK")
+	byName := flags.Bool("name", false, "search by company name")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("want exactly one symbol")
+	}
+	query := ibkr.SearchContractBySymbolQuery{Symbol: flags.Arg(0)}
+	if *byName {
+		query.Name = byName
+	}
+	if *secType != "" {
+		securityType := ibkr.SecurityType(strings.ToUpper(*secType))
+		query.SecurityType = &securityType
+	}
+	items, err := a.service.Contract().SearchContractBySymbol(query)
+	if err != nil {
+		return err
+	}
+
+	t := &table{raw: items, headers: []string{"conid", "symbol", "company", "description", "secTypes"}}
+	for _, item := range *items {
+		var secTypes []string
+		for _, section := range item.Sections {
+			secTypes = append(secTypes, section.SecType)
+		}
+		t.add(item.ContractId, item.Symbol, item.CompanyName, item.Description, strings.Join(secTypes, " "))
+	}
+	return t.write(a.stdout, a.output)
+}
+
+func contractInfo(a *app, args []string) error {
+	conid, err := intArg(args, "conid")
+	if err != nil {
+		return err
+	}
+	info, err := a.service.Contract().GetContractInfoByContractId(conid)
+	if err != nil {
+		return err
+	}
+	t := &table{raw: info, headers: []string{"conid", "symbol", "localSymbol", "instrument", "currency", "exchanges"}}
+	t.add(info.ContractId, info.Symbol, info.LocalSymbol, info.InstrumentType, info.Currency, info.ValidExchanges)
+	return t.write(a.stdout, a.output)
+}
+
+func contractRules(a *app, args []string) error {
+	flags := newFlags("contract rules")
+	sell := flags.Bool("sell", false, "rules for selling instead of buying")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	conid, err := intArg(flags.Args(), "conid")
+	if err != nil {
+		return err
+	}
+	isBuy := !*sell
+	rules, err := a.service.Contract().SearchContractRules(ibkr.SearchContractRulesQuery{ContractId: conid, IsBuy: &isBuy})
+	if err != nil {
+		return err
+	}
+	if rules.Error != nil {
+		return errors.New(*rules.Error)
+	}
+	t := &table{raw: rules, headers: []string{"orderTypes", "outsideRTH", "tifTypes", "defaultSize", "sizeIncrement", "increment"}}
+	t.add(strings.Join(rules.OrderTypes, " "), strings.Join(rules.OrderTypesOutside, " "), strings.Join(rules.TifTypes, " "),
+		rules.DefaultSize, rules.SizeIncrement, rules.Increment)
+	return t.write(a.stdout, a.output)
+}
+
+func contractSchedule(a *app, args []string) error {
+	flags := newFlags("contract schedule")
+	assetClass := flags.String("asset", "STK", "asset class")
+	exchange := flags.String("exchange", "", "exchange")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("want exactly one symbol")
+	}
+	query := ibkr.TradingScheduleQuery{AssetClass: *assetClass, Symbol: flags.Arg(0)}
+	if *exchange != "" {
+		query.Exchange = exchange
+	}
+	schedules, err := a.service.Contract().GetTradingScheduleBySymbol(query)
+	if err != nil {
+		return err
+	}
+
+	t := &table{raw: schedules, headers: []string{"exchange", "timezone", "date", "opening", "closing", "prop"}}
+	for _, schedule := range *schedules {
+		for _, day := range schedule.Schedules {
+			for _, session := range day.Sessions {
+				t.add(schedule.Exchange, schedule.Timezone, day.TradingScheduleDate, session.OpeningTime, session.ClosingTime, session.Prop)
+			}
+		}
+	}
+	return t.write(a.stdout, a.output)
+}
+
+func fxRate(a *app, args []string) error {
+	if len(args) != 2 {
+		return errors.New("want source and target currency")
+	}
+	source, target := strings.ToUpper(args[0]), strings.ToUpper(args[1])
+	resp, err := a.service.Contract().GetCurrencyExchangeRate(source, target)
+	if err != nil {
+		return err
+	}
+	t := &table{raw: resp, headers: []string{"source", "target", "rate"}}
+	t.add(source, target, resp.Rate)
+	return t.write(a.stdout, a.output)
+}
@@ -0,0 +1,211 @@
+// Command ibkr runs everyday Client Portal gateway operations.
+//
+//	ibkr [flags] <command> [subcommand] [flags] [args]
+//
+// Commands that change state, like orders cancel, ask for confirmation unless
+// -yes is given.
+//
+// The gateway certificate is verified. Trust the self-signed certificate of a
+// local gateway with -ca or -pin; -insecure skips the check.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+)
+
+const usage = `usage: ibkr [flags] <command> [subcommand] [flags] [args]
+
+commands:
+  auth status|tickle|reauth|logout
+  accounts
+  positions [-account id]
+  ledger [-account id]
+  order place [-account id] -symbol s|-conid n -side BUY|SELL -qty n [-type t] [-price p]
+              [-aux-price p] [-tif t] [-outside-rth] [-dry-run]
+  orders list [-status filters]
+  orders status <orderId>
+  orders cancel [-account id] [-yes] <orderId>
+  trades [-days n]
+  contract search [-sectype type] [-name] <symbol>
+  contract info <conid>
+  contract rules [-sell] <conid>
+  contract schedule [-asset class] [-exchange exchange] <symbol>
+  fx rate <source> <target>
+  stream quotes [-fields f,f] <conid...>
+
+flags:
+`
+
+type app struct {
+	ctx      context.Context
+	service  ibkr.ClientServiceI
+	wsClient *ibkr.WebSocketClient
+	output   outputFormat
+	account  string
+	stdin    *bufio.Reader
+	stdout   io.Writer
+}
+
+type command func(a *app, args []string) error
+
+var commands = map[string]command{
+	"auth":      subcommands(map[string]command{"status": authStatus, "tickle": authTickle, "reauth": authReauth, "logout": authLogout}),
+	"accounts":  accounts,
+	"positions": positions,
+	"ledger":    ledger,
+	"order":     subcommands(map[string]command{"place": orderPlace}),
+	"orders":    subcommands(map[string]command{"list": ordersList, "status": ordersStatus, "cancel": ordersCancel}),
+	"trades":    trades,
+	"contract":  subcommands(map[string]command{"search": contractSearch, "info": contractInfo, "rules": contractRules, "schedule": contractSchedule}),
+	"fx":        subcommands(map[string]command{"rate": fxRate}),
+	"stream":    subcommands(map[string]command{"quotes": streamQuotes}),
+}
+
+func main() {
+	flags := flag.NewFlagSet("ibkr", flag.ExitOnError)
+	var (
+		baseURL      = flags.String("base-url", envOr("IBKR_BASE_URL", ibkr.DefaultBaseUrl), "gateway REST base URL, $IBKR_BASE_URL")
+		websocketURL = flags.String("websocket-url", envOr("IBKR_WEBSOCKET_URL", ibkr.DefaultWebsocketBaseURL), "gateway websocket base URL, $IBKR_WEBSOCKET_URL")
+		caFile       = flags.String("ca", os.Getenv("IBKR_CA_FILE"), "PEM file of the CA or certificate the gateway is verified against, $IBKR_CA_FILE")
+		pins         = flags.String("pin", os.Getenv("IBKR_CERT_PIN"), "comma separated SHA-256 fingerprints of the gateway certificate, $IBKR_CERT_PIN")
+		insecure     = flags.Bool("insecure", false, "skip TLS verification of the gateway certificate, DO NOT USE IN PRODUCTION")
+		output       = flags.String("o", "table", "output format: table, json or csv")
+		account      = flags.String("account", os.Getenv("IBKR_ACCOUNT"), "default account id, $IBKR_ACCOUNT")
+		debug        = flags.Bool("debug", false, "log requests to stderr")
+	)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(os.Args[1:])
+
+	format, err := parseOutputFormat(*output)
+	if err != nil {
+		fatal(err)
+	}
+	if *debug {
+		ibkr.SetLogger(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	tlsOptions := ibkr.TLSOptions{CAFile: *caFile, InsecureSkipVerify: *insecure}
+	for _, pin := range strings.Split(*pins, ",") {
+		if pin = strings.TrimSpace(pin); pin != "" {
+			tlsOptions.PinnedSHA256 = append(tlsOptions.PinnedSHA256, pin)
+		}
+	}
+	tlsConfig, err := ibkr.NewTLSConfig(tlsOptions)
+	if err != nil {
+		fatal(err)
+	}
+	client := ibkr.NewClient(*baseURL, "", false).WithTLSConfig(tlsConfig)
+	a := &app{
+		ctx:      ctx,
+		service:  client.Service(),
+		wsClient: ibkr.NewWebsocketClient(*websocketURL, "", false).WithTLSConfig(tlsConfig).WithRESTClient(client),
+		output:   format,
+		account:  *account,
+		stdin:    bufio.NewReader(os.Stdin),
+		stdout:   os.Stdout,
+	}
+
+	args := flags.Args()
+	if len(args) == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	run, has := commands[args[0]]
+	if !has {
+		fatal(fmt.Errorf("unknown command %q", args[0]))
+	}
+	if err := run(a, args[1:]); err != nil {
+		fatal(err)
+	}
+}
+
+func subcommands(subs map[string]command) command {
+	return func(a *app, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("missing subcommand, want one of %s", strings.Join(names(subs), ", "))
+		}
+		run, has := subs[args[0]]
+		if !has {
+			return fmt.Errorf("unknown subcommand %q, want one of %s", args[0], strings.Join(names(subs), ", "))
+		}
+		return run(a, args[1:])
+	}
+}
+
+func names(subs map[string]command) []string {
+	var result []string
+	for name := range subs {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// errAborted :
+var errAborted = errors.New("aborted")
+
+// confirm :
+// Asks a y/n question on stdin; anything but y or yes declines.
+func (a *app) confirm(question string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, err := a.stdin.ReadString('\n')
+	if err != nil && answer == "" {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// accountId :
+// Returns the -account flag of a command, the global one, or the only account.
+func (a *app) accountId(flagValue string) (string, error) {
+	if flagValue != "" {
+		return flagValue, nil
+	}
+	if a.account != "" {
+		return a.account, nil
+	}
+	accounts, err := a.service.Portfolio().GetAccounts()
+	if err != nil {
+		return "", err
+	}
+	if len(*accounts) != 1 {
+		return "", errors.New("several accounts available, set -account")
+	}
+	return (*accounts)[0].AccountId, nil
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "ibkr:", err)
+	os.Exit(1)
+}
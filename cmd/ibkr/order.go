@@ -0,0 +1,279 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+)
+
+type orderTicket struct {
+	account    string
+	symbol     string
+	conid      int
+	secType    string
+	side       string
+	quantity   ibkr.Decimal
+	orderType  ibkr.OrderType
+	price      ibkr.Decimal
+	auxPrice   ibkr.Decimal
+	tif        ibkr.TimeInForce
+	outsideRTH bool
+}
+
+// orderPlace :
+// Resolves the contract, validates the order against its rules, previews it
+// and places it after confirmation. Every server prompt needs its own y/n.
+func orderPlace(a *app, args []string) error {
+	flags := newFlags("order place")
+	var (
+		account    = flags.String("account", "", "account id")
+		symbol     = flags.String("symbol", "", "symbol resolved with contract search")
+		conid      = flags.Int("conid", 0, "contract id, instead of -symbol")
+		secType    = flags.String("sectype", "STK", "security type of -symbol")
+		side       = flags.String("side", "", "BUY or SELL")
+		quantity   = decimalFlag(flags, "qty", "quantity")
+		orderType  = flags.String("type", "LMT", "order type, e.g. MKT, LMT, STP, STP LMT")
+		price      = decimalFlag(flags, "price", "limit price")
+		auxPrice   = decimalFlag(flags, "aux-price", "stop price")
+		tif        = flags.String("tif", "DAY", "time in force, e.g. DAY, GTC, IOC")
+		outsideRTH = flags.Bool("outside-rth", false, "allow filling outside regular trading hours")
+		dryRun     = flags.Bool("dry-run", false, "preview only, never submit")
+	)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	accountId, err := a.accountId(*account)
+	if err != nil {
+		return err
+	}
+	ticket := orderTicket{
+		account:    accountId,
+		symbol:     strings.ToUpper(*symbol),
+		conid:      *conid,
+		secType:    strings.ToUpper(*secType),
+		side:       strings.ToUpper(*side),
+		quantity:   *quantity,
+		orderType:  ibkr.OrderType(strings.ToUpper(*orderType)),
+		price:      *price,
+		auxPrice:   *auxPrice,
+		tif:        ibkr.TimeInForce(strings.ToUpper(*tif)),
+		outsideRTH: *outsideRTH,
+	}
+	if ticket.side != string(ibkr.OrderSideBuy) && ticket.side != string(ibkr.OrderSideSell) {
+		return errors.New("-side must be BUY or SELL")
+	}
+	if ticket.quantity.Sign() <= 0 {
+		return errors.New("-qty must be positive")
+	}
+
+	if ticket.conid == 0 {
+		if ticket.symbol == "" {
+			return errors.New("set -symbol or -conid")
+		}
+		if ticket.conid, err = a.resolveContract(ticket.symbol, ticket.secType); err != nil {
+			return err
+		}
+	}
+
+	isBuy := ticket.side == string(ibkr.OrderSideBuy)
+	rules, err := a.service.Contract().SearchContractRules(ibkr.SearchContractRulesQuery{ContractId: ticket.conid, IsBuy: &isBuy})
+	if err != nil {
+		return err
+	}
+	order := ticket.param()
+	if err := ibkr.ValidateOrder(order, rules); err != nil {
+		var invalid *ibkr.OrderValidationError
+		if !errors.As(err, &invalid) {
+			return err
+		}
+		problems := make([]string, 0, len(invalid.Violations))
+		for _, violation := range invalid.Violations {
+			problems = append(problems, violation.Field+": "+violation.Message)
+		}
+		return fmt.Errorf("order violates contract rules:\n  %s", strings.Join(problems, "\n  "))
+	}
+	preview, err := a.service.Order().PreviewOrder([]ibkr.PlaceOrderParam{order})
+	if err != nil {
+		return err
+	}
+	if preview.Error != nil && *preview.Error != "" {
+		return fmt.Errorf("preview rejected: %s", *preview.Error)
+	}
+	fmt.Fprintln(os.Stderr, ticket.describe())
+	if err := previewTable(preview).write(os.Stderr, outputTable); err != nil {
+		return err
+	}
+	if preview.Warn != nil && *preview.Warn != "" {
+		fmt.Fprintln(os.Stderr, "warning:", *preview.Warn)
+	}
+
+	if *dryRun {
+		fmt.Fprintln(os.Stderr, "dry run, order not submitted")
+		return nil
+	}
+	if !a.confirm("Submit this order?") {
+		return errAborted
+	}
+
+	resp, err := a.service.Order().PlaceOrder([]ibkr.PlaceOrderParam{order})
+	if err != nil {
+		return err
+	}
+	if resp.RejectResult != nil {
+		return fmt.Errorf("order rejected: %s", resp.RejectResult.Error)
+	}
+	var results []ibkr.PlaceOrderNormalResult
+	if resp.NormalResults != nil {
+		results = *resp.NormalResults
+	}
+	if resp.AlternateResults != nil {
+		if results, err = a.confirmPrompts(*resp.AlternateResults); err != nil {
+			return err
+		}
+	}
+
+	t := &table{raw: results, headers: []string{"orderId", "status"}}
+	for _, result := range results {
+		t.add(result.OrderId, result.OrderStatus)
+	}
+	return t.write(a.stdout, a.output)
+}
+
+// resolveContract :
+// Picks the contract of symbol with secType, asking when several match.
+func (a *app) resolveContract(symbol, secType string) (int, error) {
+	securityType := ibkr.SecurityType(secType)
+	items, err := a.service.Contract().SearchContractBySymbol(ibkr.SearchContractBySymbolQuery{Symbol: symbol, SecurityType: &securityType})
+	if err != nil {
+		return 0, err
+	}
+
+	var candidates []ibkr.SearchContractBySymbolItem
+	for _, item := range *items {
+		for _, section := range item.Sections {
+			if section.SecType == secType {
+				candidates = append(candidates, item)
+				break
+			}
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return 0, fmt.Errorf("no %s contract found for %s", secType, symbol)
+	case 1:
+		return strconv.Atoi(candidates[0].ContractId)
+	}
+
+	for n, candidate := range candidates {
+		fmt.Fprintf(os.Stderr, "%d) %s %s %s\n", n+1, candidate.ContractId, candidate.CompanyName, cell(candidate.Description))
+	}
+	fmt.Fprint(os.Stderr, "Contract number: ")
+	answer, _ := a.stdin.ReadString('\n')
+	n, err := strconv.Atoi(strings.TrimSpace(answer))
+	if err != nil || n < 1 || n > len(candidates) {
+		return 0, errAborted
+	}
+	return strconv.Atoi(candidates[n-1].ContractId)
+}
+
+// confirmPrompts :
+// Shows every server prompt for explicit confirmation until the order is placed.
+func (a *app) confirmPrompts(prompts []ibkr.PlaceOrderAlternateResult) ([]ibkr.PlaceOrderNormalResult, error) {
+	for len(prompts) > 0 {
+		prompt := prompts[0]
+		for _, message := range prompt.Message {
+			fmt.Fprintln(os.Stderr, message)
+		}
+		confirmed := a.confirm("Confirm?")
+		reply, err := a.service.Order().PlaceOrderReplyConfirmation(ibkr.PlaceOrderReplyConfirmationParam{
+			ReplyId:   prompt.Id,
+			Confirmed: confirmed,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !confirmed {
+			return nil, errAborted
+		}
+		if reply.Error != "" {
+			return nil, fmt.Errorf("order rejected: %s", reply.Error)
+		}
+		if len(reply.NormalResults) > 0 {
+			return reply.NormalResults, nil
+		}
+		prompts = append(prompts[1:], reply.AlternateResults...)
+	}
+	return nil, errors.New("order not confirmed by the gateway")
+}
+
+func (t orderTicket) param() ibkr.PlaceOrderParam {
+	conid := t.conid
+	order := ibkr.PlaceOrderParam{
+		AccountId:                  t.account,
+		ContractId:                 &conid,
+		OrderType:                  t.orderType,
+		Side:                       t.side,
+		Quantity:                   t.quantity,
+		TimeInForce:                t.tif,
+		OutsideRegularTradingHours: t.outsideRTH,
+	}
+	if t.price.Sign() > 0 {
+		price := t.price
+		order.Price = &price
+	}
+	if t.auxPrice.Sign() > 0 {
+		auxPrice := t.auxPrice
+		order.AuxPrice = &auxPrice
+	}
+	// -aux-price is the stop, but the gateway expects the stop of STP and
+	// MIT orders in price.
+	if (t.orderType == ibkr.OrderTypeStop || t.orderType == ibkr.OrderTypeMarketIfTouch) && order.AuxPrice != nil {
+		order.Price, order.AuxPrice = order.AuxPrice, nil
+	}
+	return order
+}
+
+func (t orderTicket) describe() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %v ", t.side, t.quantity)
+	if t.symbol != "" {
+		fmt.Fprintf(&b, "%s ", t.symbol)
+	}
+	fmt.Fprintf(&b, "(conid %d) %s", t.conid, t.orderType)
+	if t.price.Sign() > 0 {
+		fmt.Fprintf(&b, " @ %v", t.price)
+	}
+	if t.auxPrice.Sign() > 0 {
+		fmt.Fprintf(&b, " stop %v", t.auxPrice)
+	}
+	fmt.Fprintf(&b, " %s", t.tif)
+	if t.outsideRTH {
+		b.WriteString(" outside RTH")
+	}
+	fmt.Fprintf(&b, " in %s", t.account)
+	return b.String()
+}
+
+func previewTable(preview *ibkr.PreviewOrderResponse) *table {
+	t := &table{raw: preview, headers: []string{"", "current", "change", "after"}}
+	t.add("amount", preview.Amount.Amount, "", "")
+	t.add("commission", preview.Amount.Commission, "", "")
+	t.add("total", preview.Amount.Total, "", "")
+	for _, row := range []struct {
+		name   string
+		change ibkr.PreviewOrderChange
+	}{
+		{"equity", preview.Equity},
+		{"initial margin", preview.Initial},
+		{"maintenance margin", preview.Maintenance},
+		{"position", preview.Position},
+	} {
+		t.add(row.name, row.change.Current, row.change.Change, row.change.After)
+	}
+	return t
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+type outputFormat string
+
+const (
+	outputTable = outputFormat("table")
+	outputJSON  = outputFormat("json")
+	outputCSV   = outputFormat("csv")
+)
+
+func parseOutputFormat(s string) (outputFormat, error) {
+	switch format := outputFormat(strings.ToLower(s)); format {
+	case outputTable, outputJSON, outputCSV:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown output format %q, want table, json or csv", s)
+	}
+}
+
+// table :
+// The tabular view of a result; raw is written as is for JSON output.
+type table struct {
+	raw     interface{}
+	headers []string
+	rows    [][]string
+}
+
+func (t *table) add(values ...interface{}) {
+	row := make([]string, len(values))
+	for n, value := range values {
+		row[n] = cell(value)
+	}
+	t.rows = append(t.rows, row)
+}
+
+func cell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case *string:
+		if v == nil {
+			return ""
+		}
+		return *v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (t *table) write(w io.Writer, format outputFormat) error {
+	switch format {
+	case outputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(t.raw)
+	case outputCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(t.headers); err != nil {
+			return err
+		}
+		if err := writer.WriteAll(t.rows); err != nil {
+			return err
+		}
+		return writer.Error()
+	default:
+		writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, strings.Join(t.headers, "\t"))
+		for _, row := range t.rows {
+			fmt.Fprintln(writer, strings.Join(row, "\t"))
+		}
+		return writer.Flush()
+	}
+}
+
+// rowWriter :
+// Writes rows as they arrive, for streams: JSON output becomes one object per line.
+type rowWriter struct {
+	w       io.Writer
+	format  outputFormat
+	headers []string
+	csv     *csv.Writer
+	started bool
+}
+
+func newRowWriter(w io.Writer, format outputFormat, headers ...string) *rowWriter {
+	return &rowWriter{w: w, format: format, headers: headers, csv: csv.NewWriter(w)}
+}
+
+func (r *rowWriter) write(raw interface{}, values ...interface{}) error {
+	if r.format == outputJSON {
+		return json.NewEncoder(r.w).Encode(raw)
+	}
+	row := make([]string, len(values))
+	for n, value := range values {
+		row[n] = cell(value)
+	}
+	if r.format == outputCSV {
+		if !r.started {
+			r.started = true
+			if err := r.csv.Write(r.headers); err != nil {
+				return err
+			}
+		}
+		if err := r.csv.Write(row); err != nil {
+			return err
+		}
+		r.csv.Flush()
+		return r.csv.Error()
+	}
+	if !r.started {
+		r.started = true
+		if _, err := fmt.Fprintln(r.w, strings.Join(r.headers, "\t")); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(r.w, strings.Join(row, "\t"))
+	return err
+}
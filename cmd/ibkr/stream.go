@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+)
+
+// defaultQuoteFields are last, bid size, bid, ask, ask size and volume,
+// see https://www.interactivebrokers.com/campus/ibkr-api-page/cpapi-v1/#market-data-fields
+const defaultQuoteFields = "31,88,84,86,85,87"
+
+// streamQuotes :
+// Prints conflated quotes as they change until interrupted.
+func streamQuotes(a *app, args []string) error {
+	flags := newFlags("stream quotes")
+	fields := flags.String("fields", defaultQuoteFields, "comma separated market data fields")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return errors.New("want at least one conid")
+	}
+	param := ibkr.WebsocketPublicMarketDataParam{Fields: strings.Split(*fields, ",")}
+	for _, arg := range flags.Args() {
+		conid, err := strconv.Atoi(arg)
+		if err != nil {
+			return errors.New("invalid conid " + arg)
+		}
+		param.ContractIds = append(param.ContractIds, conid)
+	}
+
+	service, err := a.wsClient.Service().Public("")
+	if err != nil {
+		return err
+	}
+	conflator := ibkr.NewMarketDataConflator()
+	writer := newRowWriter(a.stdout, a.output, "conid", "last", "bidSize", "bid", "ask", "askSize", "volume")
+	if _, err := service.SubscribeMarketData(param, func(resp ibkr.WebsocketPublicMarketDataResponse) error {
+		if err := conflator.Handle(resp); err != nil {
+			return err
+		}
+		quote, has := conflator.Quote(resp.ContractId)
+		if !has {
+			return nil
+		}
+		return writer.write(quote, quote.ContractId, quote.LastPrice, quote.BidSize, quote.BidPrice,
+			quote.AskPrice, quote.AskSize, quote.VolumeOfDay)
+	}); err != nil {
+		_ = service.Close()
+		return err
+	}
+
+	var readErr error
+	if err := service.Start(a.ctx, func(isWebsocketClosed bool, err error) {
+		if !isWebsocketClosed {
+			readErr = err
+		}
+	}); err != nil {
+		return err
+	}
+	return readErr
+}
@@ -119,6 +119,8 @@ const (
 	OrderTypeStopWithProtection   = OrderType("STP PRT")
 	OrderTypeRelativeLimitCombo   = OrderType("REL + LMT")
 	OrderTypeRelativeMarketCombo  = OrderType("REL + MKT")
+	OrderTypeTrailing             = OrderType("TRAIL")
+	OrderTypeTrailingLimit        = OrderType("TRAILLMT")
 )
 
 const (
@@ -134,22 +136,28 @@ const (
 )
 
 const (
-	IBAlgorithmAccumulateDistribute        = IBAlgorithm("AD")
-	IBAlgorithmAccumulateDistributeAlt     = IBAlgorithm("AccuDistr")
-	IBAlgorithmAccumulateAdaptive          = IBAlgorithm("Adaptive")
-	IBAlgorithmArrivalPrice                = IBAlgorithm("ArrivalPx")
-	IBAlgorithmBalanceImpactRisk           = IBAlgorithm("BalanceImpactRisk")
-	IBAlgorithmClosePrice                  = IBAlgorithm("ClosePx")
-	IBAlgorithmDarkIce                     = IBAlgorithm("DarkIce")
-	IBAlgorithmMiddlePrice                 = IBAlgorithm("MIDPRICE")
-	IBAlgorithmMinimiseImpact              = IBAlgorithm("MinImpact")
-	IBAlgorithmPercentageOfVolume          = IBAlgorithm("PctVol")
-	IBAlgorithmPriceVariantPercentage      = IBAlgorithm("PctVolPx")
-	IBAlgorithmPricePriceVariantPercentage = IBAlgorithm("PctVolPx")
-	IBAlgorithmSizeVariantPercentage       = IBAlgorithm("PctVolSz")
-	IBAlgorithmTimeVariantPercentage       = IBAlgorithm("PctVolTm")
-	IBAlgorithmTWAP                        = IBAlgorithm("Twap")
-	IBAlgorithmVWAP                        = IBAlgorithm("Vwap")
+	IBAlgorithmAccumulateDistribute    = IBAlgorithm("AD")
+	IBAlgorithmAccumulateDistributeAlt = IBAlgorithm("AccuDistr")
+	IBAlgorithmAdaptive                = IBAlgorithm("Adaptive")
+	IBAlgorithmArrivalPrice            = IBAlgorithm("ArrivalPx")
+	IBAlgorithmBalanceImpactRisk       = IBAlgorithm("BalanceImpactRisk")
+	IBAlgorithmClosePrice              = IBAlgorithm("ClosePx")
+	IBAlgorithmDarkIce                 = IBAlgorithm("DarkIce")
+	IBAlgorithmMiddlePrice             = IBAlgorithm("MIDPRICE")
+	IBAlgorithmMinimiseImpact          = IBAlgorithm("MinImpact")
+	IBAlgorithmPercentageOfVolume      = IBAlgorithm("PctVol")
+	IBAlgorithmPriceVariantPercentage  = IBAlgorithm("PctVolPx")
+	IBAlgorithmSizeVariantPercentage   = IBAlgorithm("PctVolSz")
+	IBAlgorithmTimeVariantPercentage   = IBAlgorithm("PctVolTm")
+	IBAlgorithmTWAP                    = IBAlgorithm("Twap")
+	IBAlgorithmVWAP                    = IBAlgorithm("Vwap")
+
+	// IBAlgorithmAccumulateAdaptive is IBAlgorithmAdaptive under its former name.
+	//
+	// Deprecated: use IBAlgorithmAdaptive.
+	IBAlgorithmAccumulateAdaptive = IBAlgorithmAdaptive
+	// Deprecated: use IBAlgorithmPriceVariantPercentage, the same algorithm.
+	IBAlgorithmPricePriceVariantPercentage = IBAlgorithmPriceVariantPercentage
 )
 
 type AdaptivePriority string
@@ -160,6 +168,15 @@ const (
 	AdaptivePriorityPatient = AdaptivePriority("Patient")
 )
 
+type RiskAversion string
+
+const (
+	RiskAversionGetDone    = RiskAversion("Get Done")
+	RiskAversionAggressive = RiskAversion("Aggressive")
+	RiskAversionNeutral    = RiskAversion("Neutral")
+	RiskAversionPassive    = RiskAversion("Passive")
+)
+
 const (
 	TrailingTypeAmount  = TrailingType("amt")
 	TrailingTypePercent = TrailingType("%")
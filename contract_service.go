@@ -158,7 +158,7 @@ type GetContractAllInfoAndRulesResponse struct {
 	ContractId                int           `json:"con_id"`
 	MaturityDate              *string       `json:"maturity_date,omitempty"`
 	Industry                  string        `json:"industry"`
-	InstrumentType            string        `json:"instrument_type"'`
+	InstrumentType            string        `json:"instrument_type"`
 	TradingClass              string        `json:"trading_class"`
 	ValidExchanges            string        `json:"valid_exchanges"`
 	AllowSellLong             bool          `json:"allow_sell_long"`
@@ -198,7 +198,7 @@ type GetContractInfoResponse struct {
 	ContractId                int     `json:"con_id"`
 	MaturityDate              *string `json:"maturity_date,omitempty"`
 	Industry                  string  `json:"industry"`
-	InstrumentType            string  `json:"instrument_type"'`
+	InstrumentType            string  `json:"instrument_type"`
 	TradingClass              string  `json:"trading_class"`
 	ValidExchanges            string  `json:"valid_exchanges"`
 	AllowSellLong             bool    `json:"allow_sell_long"`
@@ -288,7 +288,7 @@ func (s *ContractService) GetAllContractIds(exchange ExchangeType) (*[]ContractI
 	param := url.Values{}
 	param.Add("exchange", string(exchange))
 
-	if err := s.client.getPublic("/trsrv/all-conids", param, &res); err != nil {
+	if err := s.client.getPublic(newOperation(OperationNameGetAllContractIds, "/trsrv/all-conids"), param, &res); err != nil {
 		return nil, err
 	}
 
@@ -300,12 +300,12 @@ func (s *ContractService) SearchSecurityDefinitionByContactId(contractIds []int)
 
 	conIds := make([]string, 0)
 	for _, contractId := range contractIds {
-		conIds = append(conIds, string(contractId))
+		conIds = append(conIds, strconv.Itoa(contractId))
 	}
 	param := url.Values{}
 	param.Add("conids", strings.Join(conIds, ","))
 
-	if err := s.client.getPublic("/trsrv/secdef", param, &res); err != nil {
+	if err := s.client.getPublic(newOperation(OperationNameSearchSecurityDefinition, "/trsrv/secdef"), param, &res); err != nil {
 		return nil, err
 	}
 
@@ -317,9 +317,9 @@ func (s *ContractService) GetContractInfoByContractId(contractId int) (*GetContr
 	var res GetContractInfoResponse
 
 	param := url.Values{}
-	path := fmt.Sprintf("/iserver/contract/%d/info", contractId)
+	operation := newOperation(OperationNameGetContractInfo, "/iserver/contract/{conid}/info", contractId)
 
-	if err := s.client.getPublic(path, param, &res); err != nil {
+	if err := s.client.getPublic(operation, param, &res); err != nil {
 		return nil, err
 	}
 
@@ -335,7 +335,7 @@ func (s *ContractService) GetCurrencyPairs(currency string) (*map[string][]Curre
 	param := url.Values{}
 	param.Add("currency", currency)
 
-	if err := s.client.getPublic("/iserver/currency/pairs", param, &res); err != nil {
+	if err := s.client.getPublic(newOperation(OperationNameGetCurrencyPairs, "/iserver/currency/pairs"), param, &res); err != nil {
 		return nil, err
 	}
 
@@ -352,7 +352,7 @@ func (s *ContractService) GetCurrencyExchangeRate(source, target string) (*GetCu
 	param.Add("source", source)
 	param.Add("target", target)
 
-	if err := s.client.getPublic("/iserver/exchangerate", param, &res); err != nil {
+	if err := s.client.getPublic(newOperation(OperationNameGetCurrencyExchangeRate, "/iserver/exchangerate"), param, &res); err != nil {
 		return nil, err
 	}
 
@@ -368,9 +368,9 @@ func (s *ContractService) GetContractFullAllInfoAndRules(contractId int, isBuy *
 		param.Add("isBuy", strconv.FormatBool(*isBuy))
 	}
 
-	path := fmt.Sprintf("/iserver/contract/%d/info-and-rules", contractId)
+	operation := newOperation(OperationNameGetContractInfoAndRules, "/iserver/contract/{conid}/info-and-rules", contractId)
 
-	if err := s.client.getPublic(path, param, &res); err != nil {
+	if err := s.client.getPublic(operation, param, &res); err != nil {
 		return nil, err
 	}
 
@@ -390,7 +390,7 @@ func (s *ContractService) SearchContractBySymbol(query SearchContractBySymbolQue
 		param.Add("secType", string(*query.SecurityType))
 	}
 
-	if err := s.client.getPublic("/iserver/secdef/search", param, &res); err != nil {
+	if err := s.client.getPublic(newOperation(OperationNameSearchContractBySymbol, "/iserver/secdef/search"), param, &res); err != nil {
 		return nil, err
 	}
 
@@ -420,7 +420,7 @@ func (s *ContractService) SearchContractRules(query SearchContractRulesQuery) (*
 		return nil, err
 	}
 
-	if err := s.client.postJSON("/iserver/contract/rules", body, &res); err != nil {
+	if err := s.client.postJSON(newOperation(OperationNameSearchContractRules, "/iserver/contract/rules"), body, &res); err != nil {
 		return nil, err
 	}
 
@@ -434,7 +434,7 @@ func (s *ContractService) GetSecurityFuturesBySymbol(symbols []string) (*map[str
 	param := url.Values{}
 	param.Add("symbols", strings.Join(symbols, ","))
 
-	if err := s.client.getPublic("/trsrv/futures", param, &res); err != nil {
+	if err := s.client.getPublic(newOperation(OperationNameGetSecurityFutures, "/trsrv/futures"), param, &res); err != nil {
 		return nil, err
 	}
 
@@ -448,7 +448,7 @@ func (s *ContractService) GetSecurityStocksBySymbol(symbols []string) (*map[stri
 	param := url.Values{}
 	param.Add("symbols", strings.Join(symbols, ","))
 
-	if err := s.client.getPublic("/trsrv/stocks", param, &res); err != nil {
+	if err := s.client.getPublic(newOperation(OperationNameGetSecurityStocks, "/trsrv/stocks"), param, &res); err != nil {
 		return nil, err
 	}
 
@@ -472,7 +472,7 @@ func (s *ContractService) GetTradingScheduleBySymbol(query TradingScheduleQuery)
 	if query.ExchangeFilter != nil {
 		param.Add("exchangeFilter", *query.ExchangeFilter)
 	}
-	if err := s.client.getPublic("/trsrv/secdef/schedule", param, &res); err != nil {
+	if err := s.client.getPublic(newOperation(OperationNameGetTradingSchedule, "/trsrv/secdef/schedule"), param, &res); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,316 @@
+package ibkr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal :
+// An exact decimal number for prices, quantities and amounts: an arbitrary
+// precision coefficient scaled by 10^-scale. The zero value is 0. Decimals
+// are immutable, every operation returns a new one.
+//
+// JSON accepts numbers and strings, since the gateway encodes the same field
+// both ways, with thousands separators ignored; null and "" decode to 0.
+// Decimals encode as JSON numbers.
+type Decimal struct {
+	coefficient *big.Int
+	scale       int32
+}
+
+// ErrDecimal :
+// Wrapped by ParseDecimal for malformed input.
+var ErrDecimal = errors.New("invalid decimal")
+
+// maxDecimalExponent bounds the exponent ParseDecimal accepts, as "1e999999999"
+// would otherwise allocate a coefficient of a billion digits.
+const maxDecimalExponent = 1000
+
+var (
+	bigTen       = big.NewInt(10)
+	decimalZero  = Decimal{}
+	decimalNulls = [][]byte{[]byte("null"), []byte(`""`)}
+)
+
+// NewDecimal :
+// Returns coefficient * 10^-scale, e.g. NewDecimal(12345, 2) is 123.45.
+func NewDecimal(coefficient int64, scale int32) Decimal {
+	return normalizeScale(big.NewInt(coefficient), scale)
+}
+
+// DecimalFromInt :
+func DecimalFromInt(value int64) Decimal {
+	return NewDecimal(value, 0)
+}
+
+// DecimalFromFloat :
+// Converts value through its shortest decimal representation, so 0.1 becomes
+// exactly 0.1. NaN and infinities become 0.
+func DecimalFromFloat(value float64) Decimal {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return decimalZero
+	}
+	d, _ := ParseDecimal(strconv.FormatFloat(value, 'f', -1, 64))
+	return d
+}
+
+// ParseDecimal :
+// Parses "123.45", "-0.5", "1,234.5" or "1.5e-3". Exponents beyond
+// ±1000 are rejected.
+func ParseDecimal(value string) (Decimal, error) {
+	s := strings.ReplaceAll(strings.TrimSpace(value), ",", "")
+	if s == "" {
+		return decimalZero, fmt.Errorf("%w: %q", ErrDecimal, value)
+	}
+
+	var exponent int64
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.ParseInt(s[i+1:], 10, 32)
+		if err != nil {
+			return decimalZero, fmt.Errorf("%w: %q", ErrDecimal, value)
+		}
+		if e > maxDecimalExponent || e < -maxDecimalExponent {
+			return decimalZero, fmt.Errorf("%w: exponent out of range in %q", ErrDecimal, value)
+		}
+		exponent, s = e, s[:i]
+	}
+
+	digits, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		digits, fraction = s[:i], s[i+1:]
+	}
+	if strings.ContainsAny(fraction, "+-") || (digits == "" || digits == "+" || digits == "-") && fraction == "" {
+		return decimalZero, fmt.Errorf("%w: %q", ErrDecimal, value)
+	}
+	coefficient, ok := new(big.Int).SetString(digits+fraction, 10)
+	if !ok {
+		return decimalZero, fmt.Errorf("%w: %q", ErrDecimal, value)
+	}
+	return normalizeScale(coefficient, int32(int64(len(fraction))-exponent)), nil
+}
+
+// MustParseDecimal :
+// Like ParseDecimal but panics, for constants.
+func MustParseDecimal(value string) Decimal {
+	d, err := ParseDecimal(value)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// normalizeScale :
+// Keeps the scale non-negative, so String never needs an exponent.
+func normalizeScale(coefficient *big.Int, scale int32) Decimal {
+	if scale < 0 {
+		coefficient = new(big.Int).Mul(coefficient, pow10(-scale))
+		scale = 0
+	}
+	return Decimal{coefficient: coefficient, scale: scale}
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+func (d Decimal) coeff() *big.Int {
+	if d.coefficient == nil {
+		return new(big.Int)
+	}
+	return d.coefficient
+}
+
+// rescale :
+// Returns the coefficient of d at a scale not below its own.
+func (d Decimal) rescale(scale int32) *big.Int {
+	if scale == d.scale {
+		return d.coeff()
+	}
+	return new(big.Int).Mul(d.coeff(), pow10(scale-d.scale))
+}
+
+func align(a, b Decimal) (*big.Int, *big.Int, int32) {
+	scale := max(a.scale, b.scale)
+	return a.rescale(scale), b.rescale(scale), scale
+}
+
+// Add :
+func (d Decimal) Add(other Decimal) Decimal {
+	a, b, scale := align(d, other)
+	return Decimal{coefficient: new(big.Int).Add(a, b), scale: scale}
+}
+
+// Sub :
+func (d Decimal) Sub(other Decimal) Decimal {
+	a, b, scale := align(d, other)
+	return Decimal{coefficient: new(big.Int).Sub(a, b), scale: scale}
+}
+
+// Mul :
+func (d Decimal) Mul(other Decimal) Decimal {
+	return Decimal{coefficient: new(big.Int).Mul(d.coeff(), other.coeff()), scale: d.scale + other.scale}
+}
+
+// Div :
+// Returns d / other rounded half away from zero to scale digits. It panics
+// if other is zero.
+func (d Decimal) Div(other Decimal, scale int32) Decimal {
+	if other.IsZero() {
+		panic("ibkr: decimal division by zero")
+	}
+	// d / other = (cd * 10^(scale+1+os-ds)) / co * 10^-(scale+1)
+	shift := scale + 1 + other.scale - d.scale
+	numerator := d.coeff()
+	denominator := other.coeff()
+	if shift >= 0 {
+		numerator = new(big.Int).Mul(numerator, pow10(shift))
+	} else {
+		denominator = new(big.Int).Mul(denominator, pow10(-shift))
+	}
+	quotient := new(big.Int).Quo(numerator, denominator)
+	return Decimal{coefficient: quotient, scale: scale + 1}.Round(scale)
+}
+
+// Neg :
+func (d Decimal) Neg() Decimal {
+	return Decimal{coefficient: new(big.Int).Neg(d.coeff()), scale: d.scale}
+}
+
+// Abs :
+func (d Decimal) Abs() Decimal {
+	return Decimal{coefficient: new(big.Int).Abs(d.coeff()), scale: d.scale}
+}
+
+// Round :
+// Rounds half away from zero to scale digits after the point.
+func (d Decimal) Round(scale int32) Decimal {
+	if scale >= d.scale {
+		return d
+	}
+	divisor := pow10(d.scale - scale)
+	quotient, remainder := new(big.Int).QuoRem(d.coeff(), divisor, new(big.Int))
+	if remainder.Sign() != 0 && new(big.Int).Abs(new(big.Int).Lsh(remainder, 1)).Cmp(divisor) >= 0 {
+		quotient.Add(quotient, big.NewInt(int64(d.Sign())))
+	}
+	return Decimal{coefficient: quotient, scale: scale}
+}
+
+// Truncate :
+// Drops the digits after scale.
+func (d Decimal) Truncate(scale int32) Decimal {
+	if scale >= d.scale {
+		return d
+	}
+	return Decimal{coefficient: new(big.Int).Quo(d.coeff(), pow10(d.scale-scale)), scale: scale}
+}
+
+// Cmp :
+// Returns -1, 0 or +1 as d is less than, equal to or greater than other.
+func (d Decimal) Cmp(other Decimal) int {
+	a, b, _ := align(d, other)
+	return a.Cmp(b)
+}
+
+// Equal :
+// Compares values, so 1.5 equals 1.50.
+func (d Decimal) Equal(other Decimal) bool {
+	return d.Cmp(other) == 0
+}
+
+// LessThan :
+func (d Decimal) LessThan(other Decimal) bool {
+	return d.Cmp(other) < 0
+}
+
+// GreaterThan :
+func (d Decimal) GreaterThan(other Decimal) bool {
+	return d.Cmp(other) > 0
+}
+
+// Sign :
+func (d Decimal) Sign() int {
+	return d.coeff().Sign()
+}
+
+// IsZero :
+// Also lets the omitzero JSON option leave out zero values.
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Scale :
+// Returns the number of digits after the point as parsed or computed.
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// Float64 :
+// Returns the nearest float64, for display and statistics only.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String :
+// Formats d without trailing zeros after the point, e.g. "123.45" or "-2".
+func (d Decimal) String() string {
+	s := d.StringFixed(d.scale)
+	if strings.IndexByte(s, '.') >= 0 {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+// StringFixed :
+// Formats d rounded to exactly scale digits after the point.
+func (d Decimal) StringFixed(scale int32) string {
+	if scale < 0 {
+		scale = 0
+	}
+	rounded := d.Round(scale)
+	digits := new(big.Int).Abs(rounded.rescale(scale)).String()
+	if scale > 0 {
+		if pad := int(scale) + 1 - len(digits); pad > 0 {
+			digits = strings.Repeat("0", pad) + digits
+		}
+		digits = digits[:len(digits)-int(scale)] + "." + digits[len(digits)-int(scale):]
+	}
+	if rounded.Sign() < 0 {
+		digits = "-" + digits
+	}
+	return digits
+}
+
+// MarshalJSON :
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalJSON :
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	for _, null := range decimalNulls {
+		if bytes.Equal(data, null) {
+			*d = decimalZero
+			return nil
+		}
+	}
+	value := string(data)
+	if len(data) >= 2 && data[0] == '"' {
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return err
+		}
+		value = unquoted
+	}
+	parsed, err := ParseDecimal(value)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
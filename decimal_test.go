@@ -0,0 +1,134 @@
+package ibkr
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		scale   int32
+		wantErr bool
+	}{
+		{"123.45", "123.45", 2, false},
+		{"-0.5", "-0.5", 1, false},
+		{"+7", "7", 0, false},
+		{" 1,234.5 ", "1234.5", 1, false},
+		{"1.50", "1.5", 2, false},
+		{".5", "0.5", 1, false},
+		{"5.", "5", 0, false},
+		{"1.5e-3", "0.0015", 4, false},
+		{"1.5E3", "1500", 0, false},
+		{"2e1000", "2" + strings.Repeat("0", 1000), 0, false},
+		{"1e-1000", "0." + strings.Repeat("0", 999) + "1", 1000, false},
+		{"1e1001", "", 0, true},
+		{"1e-1001", "", 0, true},
+		{"1e999999999", "", 0, true},
+		{"1e", "", 0, true},
+		{"", "", 0, true},
+		{"-", "", 0, true},
+		{".", "", 0, true},
+		{"1.-5", "", 0, true},
+		{"1.2.3", "", 0, true},
+		{"abc", "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseDecimal(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrDecimal) {
+					t.Errorf("got %v, %v, want ErrDecimal", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.want || got.Scale() != tt.scale {
+				t.Errorf("got %s scale %d, want %s scale %d", got, got.Scale(), tt.want, tt.scale)
+			}
+		})
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	d := MustParseDecimal
+	tests := []struct {
+		name string
+		got  Decimal
+		want string
+	}{
+		{"add", d("0.1").Add(d("0.2")), "0.3"},
+		{"sub", d("1").Sub(d("1.25")), "-0.25"},
+		{"mul", d("1.5").Mul(d("-0.2")), "-0.3"},
+		{"div", d("10").Div(d("3"), 4), "3.3333"},
+		{"div half up", d("2").Div(d("3"), 0), "1"},
+		{"div negative", d("-1").Div(d("8"), 2), "-0.13"},
+		{"round half away", d("-2.5").Round(0), "-3"},
+		{"round keeps", d("2.5").Round(3), "2.5"},
+		{"truncate", d("-2.59").Truncate(1), "-2.5"},
+		{"abs", d("-0.01").Abs(), "0.01"},
+		{"neg zero", Decimal{}.Neg(), "0"},
+		{"from float", DecimalFromFloat(0.1), "0.1"},
+		{"from int", DecimalFromInt(-42), "-42"},
+		{"new", NewDecimal(12345, 2), "123.45"},
+		{"negative scale", NewDecimal(15, -2), "1500"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got.String() != tt.want {
+				t.Errorf("got %s, want %s", tt.got, tt.want)
+			}
+		})
+	}
+
+	if !d("1.5").Equal(d("1.50")) || !d("1.49").LessThan(d("1.5")) || !d("2").GreaterThan(d("-3")) {
+		t.Error("comparison of decimals at different scales")
+	}
+	if got := d("1.005").StringFixed(2); got != "1.01" {
+		t.Errorf("StringFixed got %s, want 1.01", got)
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    string
+		wantErr bool
+	}{
+		{`123.45`, "123.45", false},
+		{`"123.45"`, "123.45", false},
+		{`"1,234"`, "1234", false},
+		{`null`, "0", false},
+		{`""`, "0", false},
+		{`"1e2000"`, "", true},
+		{`"n/a"`, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			var got Decimal
+			err := json.Unmarshal([]byte(tt.data), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	data, err := json.Marshal(struct {
+		Price Decimal `json:"price"`
+		Size  Decimal `json:"size,omitzero"`
+	}{Price: MustParseDecimal("0.50")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"price":0.5}` {
+		t.Errorf("marshal got %s", data)
+	}
+}
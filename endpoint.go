@@ -0,0 +1,33 @@
+package ibkr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Endpoint :
+// A REST endpoint of the gateway, e.g. Template "/iserver/account/{accountId}/orders"
+// and Path "/iserver/account/DU123/orders".
+type Endpoint struct {
+	Template string
+	Path     string
+}
+
+// newEndpoint :
+// Fills the placeholders of template with params in order.
+func newEndpoint(template string, params ...interface{}) Endpoint {
+	var path strings.Builder
+	rest := template
+	for _, param := range params {
+		start := strings.Index(rest, "{")
+		end := strings.Index(rest, "}")
+		if start < 0 || end < start {
+			break
+		}
+		path.WriteString(rest[:start])
+		path.WriteString(fmt.Sprint(param))
+		rest = rest[end+1:]
+	}
+	path.WriteString(rest)
+	return Endpoint{Template: template, Path: path.String()}
+}
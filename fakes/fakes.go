@@ -0,0 +1,154 @@
+// Package fakes provides programmable fakes of every service interface of
+// the SDK, so business logic written against ClientServiceI and
+// WebsocketClientServiceI can be unit-tested without a network.
+//
+// Each fake has a Func field per method. A method whose Func is nil returns
+// zero values, and ErrNotProgrammed if it returns an error. Every call is
+// recorded.
+//
+//	services := fakes.NewServices()
+//	services.Portfolio.GetPositionsFunc = func(param ibkr.GetPositionParam) (*[]ibkr.PositionInfo, error) {
+//		return &[]ibkr.PositionInfo{{ContractId: 265598, Position: ibkr.DecimalFromInt(10)}}, nil
+//	}
+//	err := rebalance(services.Client) // takes ibkr.ClientServiceI
+//	if services.Order.CallCount("PlaceOrder") != 1 { ... }
+//
+// Stream* methods can feed a subscription with ibkr.NewSubscription:
+//
+//	ws := fakes.NewWebsocketServices()
+//	ws.Public.StreamMarketDataFunc = func(param ibkr.WebsocketPublicMarketDataParam, options ibkr.DeliveryOptions) (*ibkr.Subscription[ibkr.WebsocketPublicMarketDataResponse], error) {
+//		return ibkr.NewSubscription(options, func(handler func(ibkr.WebsocketPublicMarketDataResponse) error) (func() error, error) {
+//			go handler(ibkr.WebsocketPublicMarketDataResponse{ContractId: 265598, LastPrice: "101.5"})
+//			return func() error { return nil }, nil
+//		})
+//	}
+//
+// The fakes are generated from the interfaces; run go generate after
+// changing one.
+package fakes
+
+//go:generate go run gen.go
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+)
+
+// ErrNotProgrammed :
+// Returned by a method whose Func is not set.
+var ErrNotProgrammed = errors.New("fakes: method not programmed")
+
+func notProgrammed(method string) error {
+	return fmt.Errorf("%w: %s", ErrNotProgrammed, method)
+}
+
+// Call :
+// One recorded method call.
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+type recorder struct {
+	mutex sync.Mutex
+	calls []Call
+}
+
+func (r *recorder) record(method string, args ...interface{}) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.calls = append(r.calls, Call{Method: method, Args: args})
+}
+
+// Calls :
+// Returns the calls made so far, in order.
+func (r *recorder) Calls() []Call {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+// CallCount :
+func (r *recorder) CallCount(method string) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	count := 0
+	for _, call := range r.calls {
+		if call.Method == method {
+			count++
+		}
+	}
+	return count
+}
+
+// ResetCalls :
+func (r *recorder) ResetCalls() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.calls = nil
+}
+
+// Services :
+// A ClientService whose accessors return the other fakes.
+type Services struct {
+	Client          *ClientService
+	Session         *SessionService
+	Account         *AccountService
+	Contract        *ContractService
+	Order           *OrdersService
+	OrderMonitoring *OrderMonitoringService
+	Portfolio       *PortfolioService
+}
+
+// NewServices :
+func NewServices() *Services {
+	s := &Services{
+		Client:          &ClientService{},
+		Session:         &SessionService{},
+		Account:         &AccountService{},
+		Contract:        &ContractService{},
+		Order:           &OrdersService{},
+		OrderMonitoring: &OrderMonitoringService{},
+		Portfolio:       &PortfolioService{},
+	}
+	s.Client.SessionFunc = func() ibkr.SessionServiceI { return s.Session }
+	s.Client.AccountFunc = func() ibkr.AccountServiceI { return s.Account }
+	s.Client.ContractFunc = func() ibkr.ContractServiceI { return s.Contract }
+	s.Client.OrderFunc = func() ibkr.OrdersServiceI { return s.Order }
+	s.Client.OrderMonitoringFunc = func() ibkr.OrderMonitoringServiceI { return s.OrderMonitoring }
+	s.Client.PortfolioFunc = func() ibkr.PortfolioServiceI { return s.Portfolio }
+	return s
+}
+
+// WebsocketServices :
+// A WebsocketClientService whose connections are the other fakes.
+type WebsocketServices struct {
+	Client  *WebsocketClientService
+	Public  *WebsocketPublicService
+	Private *WebsocketPrivateService
+}
+
+// NewWebsocketServices :
+func NewWebsocketServices() *WebsocketServices {
+	s := &WebsocketServices{
+		Client:  &WebsocketClientService{},
+		Public:  &WebsocketPublicService{},
+		Private: &WebsocketPrivateService{},
+	}
+	s.Client.PublicFunc = func(sessionToken string) (ibkr.WebsocketPublicServiceI, error) {
+		return s.Public, nil
+	}
+	s.Client.PublicWithSourceIPFunc = func(sessionToken, sourceIP string) (ibkr.WebsocketPublicServiceI, error) {
+		return s.Public, nil
+	}
+	s.Client.PrivateFunc = func(sessionToken string) (ibkr.WebsocketPrivateServiceI, error) {
+		return s.Private, nil
+	}
+	s.Client.PrivateWithSourceIPFunc = func(sessionToken, sourceIP string) (ibkr.WebsocketPrivateServiceI, error) {
+		return s.Private, nil
+	}
+	return s
+}
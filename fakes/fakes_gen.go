@@ -0,0 +1,1175 @@
+// Code generated by gen.go; DO NOT EDIT.
+
+package fakes
+
+import (
+	"context"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+)
+
+// AccountService :
+// A programmable fake of ibkr.AccountServiceI.
+type AccountService struct {
+	recorder
+
+	GetProfitAndLossFunc func() (*ibkr.AccountProfitAndLossResponse, error)
+}
+
+var _ ibkr.AccountServiceI = (*AccountService)(nil)
+
+// GetProfitAndLoss :
+func (f *AccountService) GetProfitAndLoss() (r0 *ibkr.AccountProfitAndLossResponse, r1 error) {
+	f.record("GetProfitAndLoss")
+	if f.GetProfitAndLossFunc == nil {
+		r1 = notProgrammed("AccountService.GetProfitAndLoss")
+		return
+	}
+	return f.GetProfitAndLossFunc()
+}
+
+// ClientService :
+// A programmable fake of ibkr.ClientServiceI.
+type ClientService struct {
+	recorder
+
+	SessionFunc         func() ibkr.SessionServiceI
+	AccountFunc         func() ibkr.AccountServiceI
+	ContractFunc        func() ibkr.ContractServiceI
+	OrderFunc           func() ibkr.OrdersServiceI
+	OrderMonitoringFunc func() ibkr.OrderMonitoringServiceI
+	PortfolioFunc       func() ibkr.PortfolioServiceI
+}
+
+var _ ibkr.ClientServiceI = (*ClientService)(nil)
+
+// Session :
+func (f *ClientService) Session() (r0 ibkr.SessionServiceI) {
+	f.record("Session")
+	if f.SessionFunc == nil {
+		return
+	}
+	return f.SessionFunc()
+}
+
+// Account :
+func (f *ClientService) Account() (r0 ibkr.AccountServiceI) {
+	f.record("Account")
+	if f.AccountFunc == nil {
+		return
+	}
+	return f.AccountFunc()
+}
+
+// Contract :
+func (f *ClientService) Contract() (r0 ibkr.ContractServiceI) {
+	f.record("Contract")
+	if f.ContractFunc == nil {
+		return
+	}
+	return f.ContractFunc()
+}
+
+// Order :
+func (f *ClientService) Order() (r0 ibkr.OrdersServiceI) {
+	f.record("Order")
+	if f.OrderFunc == nil {
+		return
+	}
+	return f.OrderFunc()
+}
+
+// OrderMonitoring :
+func (f *ClientService) OrderMonitoring() (r0 ibkr.OrderMonitoringServiceI) {
+	f.record("OrderMonitoring")
+	if f.OrderMonitoringFunc == nil {
+		return
+	}
+	return f.OrderMonitoringFunc()
+}
+
+// Portfolio :
+func (f *ClientService) Portfolio() (r0 ibkr.PortfolioServiceI) {
+	f.record("Portfolio")
+	if f.PortfolioFunc == nil {
+		return
+	}
+	return f.PortfolioFunc()
+}
+
+// ContractService :
+// A programmable fake of ibkr.ContractServiceI.
+type ContractService struct {
+	recorder
+
+	GetAllContractIdsFunc                   func(exchange ibkr.ExchangeType) (*[]ibkr.ContractIdItem, error)
+	SearchSecurityDefinitionByContactIdFunc func(contractIds []int) (*ibkr.SearchSecurityDefinitionResponse, error)
+	GetContractInfoByContractIdFunc         func(contractId int) (*ibkr.GetContractInfoResponse, error)
+	GetCurrencyPairsFunc                    func(currency string) (*map[string][]ibkr.CurrencyPairItem, error)
+	GetCurrencyExchangeRateFunc             func(source string, target string) (*ibkr.GetCurrencyExchangeRateResponse, error)
+	GetContractFullAllInfoAndRulesFunc      func(contractId int, isBuy *bool) (*ibkr.GetContractAllInfoAndRulesResponse, error)
+	SearchContractBySymbolFunc              func(query ibkr.SearchContractBySymbolQuery) (*[]ibkr.SearchContractBySymbolItem, error)
+	SearchContractRulesFunc                 func(query ibkr.SearchContractRulesQuery) (*ibkr.ContractRules, error)
+	GetSecurityFuturesBySymbolFunc          func(symbols []string) (*map[string][]ibkr.FuturesInfoItem, error)
+	GetSecurityStocksBySymbolFunc           func(symbols []string) (*map[string][]ibkr.StockInfoItem, error)
+	GetTradingScheduleBySymbolFunc          func(query ibkr.TradingScheduleQuery) (*[]ibkr.GetTradingScheduleResponse, error)
+}
+
+var _ ibkr.ContractServiceI = (*ContractService)(nil)
+
+// GetAllContractIds :
+func (f *ContractService) GetAllContractIds(exchange ibkr.ExchangeType) (r0 *[]ibkr.ContractIdItem, r1 error) {
+	f.record("GetAllContractIds", exchange)
+	if f.GetAllContractIdsFunc == nil {
+		r1 = notProgrammed("ContractService.GetAllContractIds")
+		return
+	}
+	return f.GetAllContractIdsFunc(exchange)
+}
+
+// SearchSecurityDefinitionByContactId :
+func (f *ContractService) SearchSecurityDefinitionByContactId(contractIds []int) (r0 *ibkr.SearchSecurityDefinitionResponse, r1 error) {
+	f.record("SearchSecurityDefinitionByContactId", contractIds)
+	if f.SearchSecurityDefinitionByContactIdFunc == nil {
+		r1 = notProgrammed("ContractService.SearchSecurityDefinitionByContactId")
+		return
+	}
+	return f.SearchSecurityDefinitionByContactIdFunc(contractIds)
+}
+
+// GetContractInfoByContractId :
+func (f *ContractService) GetContractInfoByContractId(contractId int) (r0 *ibkr.GetContractInfoResponse, r1 error) {
+	f.record("GetContractInfoByContractId", contractId)
+	if f.GetContractInfoByContractIdFunc == nil {
+		r1 = notProgrammed("ContractService.GetContractInfoByContractId")
+		return
+	}
+	return f.GetContractInfoByContractIdFunc(contractId)
+}
+
+// GetCurrencyPairs :
+func (f *ContractService) GetCurrencyPairs(currency string) (r0 *map[string][]ibkr.CurrencyPairItem, r1 error) {
+	f.record("GetCurrencyPairs", currency)
+	if f.GetCurrencyPairsFunc == nil {
+		r1 = notProgrammed("ContractService.GetCurrencyPairs")
+		return
+	}
+	return f.GetCurrencyPairsFunc(currency)
+}
+
+// GetCurrencyExchangeRate :
+func (f *ContractService) GetCurrencyExchangeRate(source string, target string) (r0 *ibkr.GetCurrencyExchangeRateResponse, r1 error) {
+	f.record("GetCurrencyExchangeRate", source, target)
+	if f.GetCurrencyExchangeRateFunc == nil {
+		r1 = notProgrammed("ContractService.GetCurrencyExchangeRate")
+		return
+	}
+	return f.GetCurrencyExchangeRateFunc(source, target)
+}
+
+// GetContractFullAllInfoAndRules :
+func (f *ContractService) GetContractFullAllInfoAndRules(contractId int, isBuy *bool) (r0 *ibkr.GetContractAllInfoAndRulesResponse, r1 error) {
+	f.record("GetContractFullAllInfoAndRules", contractId, isBuy)
+	if f.GetContractFullAllInfoAndRulesFunc == nil {
+		r1 = notProgrammed("ContractService.GetContractFullAllInfoAndRules")
+		return
+	}
+	return f.GetContractFullAllInfoAndRulesFunc(contractId, isBuy)
+}
+
+// SearchContractBySymbol :
+func (f *ContractService) SearchContractBySymbol(query ibkr.SearchContractBySymbolQuery) (r0 *[]ibkr.SearchContractBySymbolItem, r1 error) {
+	f.record("SearchContractBySymbol", query)
+	if f.SearchContractBySymbolFunc == nil {
+		r1 = notProgrammed("ContractService.SearchContractBySymbol")
+		return
+	}
+	return f.SearchContractBySymbolFunc(query)
+}
+
+// SearchContractRules :
+func (f *ContractService) SearchContractRules(query ibkr.SearchContractRulesQuery) (r0 *ibkr.ContractRules, r1 error) {
+	f.record("SearchContractRules", query)
+	if f.SearchContractRulesFunc == nil {
+		r1 = notProgrammed("ContractService.SearchContractRules")
+		return
+	}
+	return f.SearchContractRulesFunc(query)
+}
+
+// GetSecurityFuturesBySymbol :
+func (f *ContractService) GetSecurityFuturesBySymbol(symbols []string) (r0 *map[string][]ibkr.FuturesInfoItem, r1 error) {
+	f.record("GetSecurityFuturesBySymbol", symbols)
+	if f.GetSecurityFuturesBySymbolFunc == nil {
+		r1 = notProgrammed("ContractService.GetSecurityFuturesBySymbol")
+		return
+	}
+	return f.GetSecurityFuturesBySymbolFunc(symbols)
+}
+
+// GetSecurityStocksBySymbol :
+func (f *ContractService) GetSecurityStocksBySymbol(symbols []string) (r0 *map[string][]ibkr.StockInfoItem, r1 error) {
+	f.record("GetSecurityStocksBySymbol", symbols)
+	if f.GetSecurityStocksBySymbolFunc == nil {
+		r1 = notProgrammed("ContractService.GetSecurityStocksBySymbol")
+		return
+	}
+	return f.GetSecurityStocksBySymbolFunc(symbols)
+}
+
+// GetTradingScheduleBySymbol :
+func (f *ContractService) GetTradingScheduleBySymbol(query ibkr.TradingScheduleQuery) (r0 *[]ibkr.GetTradingScheduleResponse, r1 error) {
+	f.record("GetTradingScheduleBySymbol", query)
+	if f.GetTradingScheduleBySymbolFunc == nil {
+		r1 = notProgrammed("ContractService.GetTradingScheduleBySymbol")
+		return
+	}
+	return f.GetTradingScheduleBySymbolFunc(query)
+}
+
+// OrderMonitoringService :
+// A programmable fake of ibkr.OrderMonitoringServiceI.
+type OrderMonitoringService struct {
+	recorder
+
+	GetLiveOrdersFunc func(param ibkr.GetLiveOrdersParam) (*ibkr.GetLiveOrdersResponse, error)
+	GetTradesFunc     func(param ibkr.GetTradesParam) (*[]ibkr.TradeItem, error)
+	GetStatusFunc     func(orderId int) (*ibkr.OrderStatusItem, error)
+}
+
+var _ ibkr.OrderMonitoringServiceI = (*OrderMonitoringService)(nil)
+
+// GetLiveOrders :
+func (f *OrderMonitoringService) GetLiveOrders(param ibkr.GetLiveOrdersParam) (r0 *ibkr.GetLiveOrdersResponse, r1 error) {
+	f.record("GetLiveOrders", param)
+	if f.GetLiveOrdersFunc == nil {
+		r1 = notProgrammed("OrderMonitoringService.GetLiveOrders")
+		return
+	}
+	return f.GetLiveOrdersFunc(param)
+}
+
+// GetTrades :
+func (f *OrderMonitoringService) GetTrades(param ibkr.GetTradesParam) (r0 *[]ibkr.TradeItem, r1 error) {
+	f.record("GetTrades", param)
+	if f.GetTradesFunc == nil {
+		r1 = notProgrammed("OrderMonitoringService.GetTrades")
+		return
+	}
+	return f.GetTradesFunc(param)
+}
+
+// GetStatus :
+func (f *OrderMonitoringService) GetStatus(orderId int) (r0 *ibkr.OrderStatusItem, r1 error) {
+	f.record("GetStatus", orderId)
+	if f.GetStatusFunc == nil {
+		r1 = notProgrammed("OrderMonitoringService.GetStatus")
+		return
+	}
+	return f.GetStatusFunc(orderId)
+}
+
+// OrdersService :
+// A programmable fake of ibkr.OrdersServiceI.
+type OrdersService struct {
+	recorder
+
+	PlaceOrderFunc                  func(orders []ibkr.PlaceOrderParam) (*ibkr.PlaceOrderResponse, error)
+	PreviewOrderFunc                func(orders []ibkr.PlaceOrderParam) (*ibkr.PreviewOrderResponse, error)
+	CancelOrderFunc                 func(param ibkr.CancelOrderParam) (*ibkr.CancelOrderResponse, error)
+	PlaceOrderReplyConfirmationFunc func(param ibkr.PlaceOrderReplyConfirmationParam) (*ibkr.PlaceOrderReplyConfirmationResponse, error)
+	RespondServerPromptFunc         func(param ibkr.RespondServerPromptParam) (*ibkr.RespondServerPromptResponse, error)
+	SuppressMessagesFunc            func(messageIds []string) (*ibkr.SuppressMessagesResponse, error)
+}
+
+var _ ibkr.OrdersServiceI = (*OrdersService)(nil)
+
+// PlaceOrder :
+func (f *OrdersService) PlaceOrder(orders []ibkr.PlaceOrderParam) (r0 *ibkr.PlaceOrderResponse, r1 error) {
+	f.record("PlaceOrder", orders)
+	if f.PlaceOrderFunc == nil {
+		r1 = notProgrammed("OrdersService.PlaceOrder")
+		return
+	}
+	return f.PlaceOrderFunc(orders)
+}
+
+// PreviewOrder :
+func (f *OrdersService) PreviewOrder(orders []ibkr.PlaceOrderParam) (r0 *ibkr.PreviewOrderResponse, r1 error) {
+	f.record("PreviewOrder", orders)
+	if f.PreviewOrderFunc == nil {
+		r1 = notProgrammed("OrdersService.PreviewOrder")
+		return
+	}
+	return f.PreviewOrderFunc(orders)
+}
+
+// CancelOrder :
+func (f *OrdersService) CancelOrder(param ibkr.CancelOrderParam) (r0 *ibkr.CancelOrderResponse, r1 error) {
+	f.record("CancelOrder", param)
+	if f.CancelOrderFunc == nil {
+		r1 = notProgrammed("OrdersService.CancelOrder")
+		return
+	}
+	return f.CancelOrderFunc(param)
+}
+
+// PlaceOrderReplyConfirmation :
+func (f *OrdersService) PlaceOrderReplyConfirmation(param ibkr.PlaceOrderReplyConfirmationParam) (r0 *ibkr.PlaceOrderReplyConfirmationResponse, r1 error) {
+	f.record("PlaceOrderReplyConfirmation", param)
+	if f.PlaceOrderReplyConfirmationFunc == nil {
+		r1 = notProgrammed("OrdersService.PlaceOrderReplyConfirmation")
+		return
+	}
+	return f.PlaceOrderReplyConfirmationFunc(param)
+}
+
+// RespondServerPrompt :
+func (f *OrdersService) RespondServerPrompt(param ibkr.RespondServerPromptParam) (r0 *ibkr.RespondServerPromptResponse, r1 error) {
+	f.record("RespondServerPrompt", param)
+	if f.RespondServerPromptFunc == nil {
+		r1 = notProgrammed("OrdersService.RespondServerPrompt")
+		return
+	}
+	return f.RespondServerPromptFunc(param)
+}
+
+// SuppressMessages :
+func (f *OrdersService) SuppressMessages(messageIds []string) (r0 *ibkr.SuppressMessagesResponse, r1 error) {
+	f.record("SuppressMessages", messageIds)
+	if f.SuppressMessagesFunc == nil {
+		r1 = notProgrammed("OrdersService.SuppressMessages")
+		return
+	}
+	return f.SuppressMessagesFunc(messageIds)
+}
+
+// PortfolioService :
+// A programmable fake of ibkr.PortfolioServiceI.
+type PortfolioService struct {
+	recorder
+
+	GetAccountsFunc                              func() (*[]ibkr.PortfolioAccountInfo, error)
+	GetSubAccountsFunc                           func() (*[]ibkr.PortfolioAccountInfo, error)
+	GetSubAccountsWithLargeAccountStructuresFunc func() (*[]ibkr.PortfolioAccountInfo, error)
+	GetSpecificAccountFunc                       func(accountId string) (*ibkr.PortfolioAccountInfo, error)
+	GetCombinationPositionsFunc                  func(accountId string, nocache bool) (*[]ibkr.CombinationPositionItem, error)
+	GetPositionsFunc                             func(param ibkr.GetPositionParam) (*[]ibkr.PositionInfo, error)
+	GetPositionsNewFunc                          func(param ibkr.GetPositionParam) (*[]ibkr.PositionNewInfo, error)
+	GetPositionByContractIdFunc                  func(contractId int) (*ibkr.PositionInfo, error)
+	GetLedgerFunc                                func(accountId string) (*map[string]ibkr.AccountLedgerItem, error)
+}
+
+var _ ibkr.PortfolioServiceI = (*PortfolioService)(nil)
+
+// GetAccounts :
+func (f *PortfolioService) GetAccounts() (r0 *[]ibkr.PortfolioAccountInfo, r1 error) {
+	f.record("GetAccounts")
+	if f.GetAccountsFunc == nil {
+		r1 = notProgrammed("PortfolioService.GetAccounts")
+		return
+	}
+	return f.GetAccountsFunc()
+}
+
+// GetSubAccounts :
+func (f *PortfolioService) GetSubAccounts() (r0 *[]ibkr.PortfolioAccountInfo, r1 error) {
+	f.record("GetSubAccounts")
+	if f.GetSubAccountsFunc == nil {
+		r1 = notProgrammed("PortfolioService.GetSubAccounts")
+		return
+	}
+	return f.GetSubAccountsFunc()
+}
+
+// GetSubAccountsWithLargeAccountStructures :
+func (f *PortfolioService) GetSubAccountsWithLargeAccountStructures() (r0 *[]ibkr.PortfolioAccountInfo, r1 error) {
+	f.record("GetSubAccountsWithLargeAccountStructures")
+	if f.GetSubAccountsWithLargeAccountStructuresFunc == nil {
+		r1 = notProgrammed("PortfolioService.GetSubAccountsWithLargeAccountStructures")
+		return
+	}
+	return f.GetSubAccountsWithLargeAccountStructuresFunc()
+}
+
+// GetSpecificAccount :
+func (f *PortfolioService) GetSpecificAccount(accountId string) (r0 *ibkr.PortfolioAccountInfo, r1 error) {
+	f.record("GetSpecificAccount", accountId)
+	if f.GetSpecificAccountFunc == nil {
+		r1 = notProgrammed("PortfolioService.GetSpecificAccount")
+		return
+	}
+	return f.GetSpecificAccountFunc(accountId)
+}
+
+// GetCombinationPositions :
+func (f *PortfolioService) GetCombinationPositions(accountId string, nocache bool) (r0 *[]ibkr.CombinationPositionItem, r1 error) {
+	f.record("GetCombinationPositions", accountId, nocache)
+	if f.GetCombinationPositionsFunc == nil {
+		r1 = notProgrammed("PortfolioService.GetCombinationPositions")
+		return
+	}
+	return f.GetCombinationPositionsFunc(accountId, nocache)
+}
+
+// GetPositions :
+func (f *PortfolioService) GetPositions(param ibkr.GetPositionParam) (r0 *[]ibkr.PositionInfo, r1 error) {
+	f.record("GetPositions", param)
+	if f.GetPositionsFunc == nil {
+		r1 = notProgrammed("PortfolioService.GetPositions")
+		return
+	}
+	return f.GetPositionsFunc(param)
+}
+
+// GetPositionsNew :
+func (f *PortfolioService) GetPositionsNew(param ibkr.GetPositionParam) (r0 *[]ibkr.PositionNewInfo, r1 error) {
+	f.record("GetPositionsNew", param)
+	if f.GetPositionsNewFunc == nil {
+		r1 = notProgrammed("PortfolioService.GetPositionsNew")
+		return
+	}
+	return f.GetPositionsNewFunc(param)
+}
+
+// GetPositionByContractId :
+func (f *PortfolioService) GetPositionByContractId(contractId int) (r0 *ibkr.PositionInfo, r1 error) {
+	f.record("GetPositionByContractId", contractId)
+	if f.GetPositionByContractIdFunc == nil {
+		r1 = notProgrammed("PortfolioService.GetPositionByContractId")
+		return
+	}
+	return f.GetPositionByContractIdFunc(contractId)
+}
+
+// GetLedger :
+func (f *PortfolioService) GetLedger(accountId string) (r0 *map[string]ibkr.AccountLedgerItem, r1 error) {
+	f.record("GetLedger", accountId)
+	if f.GetLedgerFunc == nil {
+		r1 = notProgrammed("PortfolioService.GetLedger")
+		return
+	}
+	return f.GetLedgerFunc(accountId)
+}
+
+// SessionService :
+// A programmable fake of ibkr.SessionServiceI.
+type SessionService struct {
+	recorder
+
+	PostAuthStatusFunc     func() (*ibkr.AuthStatusInfo, error)
+	PostPingServerFunc     func() (*ibkr.PingServerResponse, error)
+	PostReauthenticateFunc func() (*ibkr.ReauthenticateResponse, error)
+	PostLogoutFunc         func() (*ibkr.LogoutResponse, error)
+}
+
+var _ ibkr.SessionServiceI = (*SessionService)(nil)
+
+// PostAuthStatus :
+func (f *SessionService) PostAuthStatus() (r0 *ibkr.AuthStatusInfo, r1 error) {
+	f.record("PostAuthStatus")
+	if f.PostAuthStatusFunc == nil {
+		r1 = notProgrammed("SessionService.PostAuthStatus")
+		return
+	}
+	return f.PostAuthStatusFunc()
+}
+
+// PostPingServer :
+func (f *SessionService) PostPingServer() (r0 *ibkr.PingServerResponse, r1 error) {
+	f.record("PostPingServer")
+	if f.PostPingServerFunc == nil {
+		r1 = notProgrammed("SessionService.PostPingServer")
+		return
+	}
+	return f.PostPingServerFunc()
+}
+
+// PostReauthenticate :
+func (f *SessionService) PostReauthenticate() (r0 *ibkr.ReauthenticateResponse, r1 error) {
+	f.record("PostReauthenticate")
+	if f.PostReauthenticateFunc == nil {
+		r1 = notProgrammed("SessionService.PostReauthenticate")
+		return
+	}
+	return f.PostReauthenticateFunc()
+}
+
+// PostLogout :
+func (f *SessionService) PostLogout() (r0 *ibkr.LogoutResponse, r1 error) {
+	f.record("PostLogout")
+	if f.PostLogoutFunc == nil {
+		r1 = notProgrammed("SessionService.PostLogout")
+		return
+	}
+	return f.PostLogoutFunc()
+}
+
+// WebsocketClientService :
+// A programmable fake of ibkr.WebsocketClientServiceI.
+type WebsocketClientService struct {
+	recorder
+
+	PublicFunc              func(sessionToken string) (ibkr.WebsocketPublicServiceI, error)
+	PublicWithSourceIPFunc  func(sessionToken string, sourceIP string) (ibkr.WebsocketPublicServiceI, error)
+	PrivateFunc             func(sessionToken string) (ibkr.WebsocketPrivateServiceI, error)
+	PrivateWithSourceIPFunc func(sessionToken string, sourceIP string) (ibkr.WebsocketPrivateServiceI, error)
+}
+
+var _ ibkr.WebsocketClientServiceI = (*WebsocketClientService)(nil)
+
+// Public :
+func (f *WebsocketClientService) Public(sessionToken string) (r0 ibkr.WebsocketPublicServiceI, r1 error) {
+	f.record("Public", sessionToken)
+	if f.PublicFunc == nil {
+		r1 = notProgrammed("WebsocketClientService.Public")
+		return
+	}
+	return f.PublicFunc(sessionToken)
+}
+
+// PublicWithSourceIP :
+func (f *WebsocketClientService) PublicWithSourceIP(sessionToken string, sourceIP string) (r0 ibkr.WebsocketPublicServiceI, r1 error) {
+	f.record("PublicWithSourceIP", sessionToken, sourceIP)
+	if f.PublicWithSourceIPFunc == nil {
+		r1 = notProgrammed("WebsocketClientService.PublicWithSourceIP")
+		return
+	}
+	return f.PublicWithSourceIPFunc(sessionToken, sourceIP)
+}
+
+// Private :
+func (f *WebsocketClientService) Private(sessionToken string) (r0 ibkr.WebsocketPrivateServiceI, r1 error) {
+	f.record("Private", sessionToken)
+	if f.PrivateFunc == nil {
+		r1 = notProgrammed("WebsocketClientService.Private")
+		return
+	}
+	return f.PrivateFunc(sessionToken)
+}
+
+// PrivateWithSourceIP :
+func (f *WebsocketClientService) PrivateWithSourceIP(sessionToken string, sourceIP string) (r0 ibkr.WebsocketPrivateServiceI, r1 error) {
+	f.record("PrivateWithSourceIP", sessionToken, sourceIP)
+	if f.PrivateWithSourceIPFunc == nil {
+		r1 = notProgrammed("WebsocketClientService.PrivateWithSourceIP")
+		return
+	}
+	return f.PrivateWithSourceIPFunc(sessionToken, sourceIP)
+}
+
+// WebsocketPrivateService :
+// A programmable fake of ibkr.WebsocketPrivateServiceI.
+type WebsocketPrivateService struct {
+	recorder
+
+	StartFunc                      func(p0 context.Context, p1 ibkr.ErrHandler) error
+	RunFunc                        func() error
+	PingFunc                       func() error
+	CloseFunc                      func() error
+	ShutdownFunc                   func() error
+	SetAccountUpdatesChanFunc      func(channel chan *ibkr.WebsocketUnsolicitedAccountUpdatesResponse)
+	SetAuthStatusChanFunc          func(channel chan *ibkr.WebsocketUnsolicitedAuthStatusResponse)
+	SetSystemChanFunc              func(channel chan *ibkr.WebsocketUnsolicitedSystemConnectionResponse)
+	SetBulletinsChanFunc           func(channel chan *ibkr.WebsocketUnsolicitedBulletinsResponse)
+	SetNotificationsChanFunc       func(channel chan *ibkr.WebsocketUnsolicitedNotificationsResponse)
+	SetUnhandledMessageHandlerFunc func(handler ibkr.UnhandledMessageHandler)
+	SetMessageErrorHandlerFunc     func(handler ibkr.MessageErrorHandler)
+	SetDeliveryOptionsFunc         func(topic string, options ibkr.DeliveryOptions) error
+	DeliveryStatsFunc              func(topic string) ibkr.DeliveryStats
+	SetMonitorFunc                 func(monitor *ibkr.WebsocketMonitor)
+	SubscribeAccountSummaryFunc    func(p0 ibkr.WebsocketPrivateAccountSummaryParam, p1 func(ibkr.WebsocketPrivateAccountSummaryResponse) error) (func() error, error)
+	UnsubscribeAccountSummaryFunc  func(p0 ibkr.WebsocketPrivateAccountSummaryParam) error
+	SubscribeAccountLedgerFunc     func(p0 ibkr.WebsocketPrivateAccountLedgerParam, p1 func(ibkr.WebsocketPrivateAccountLedgerResponse) error) (func() error, error)
+	UnsubscribeAccountLedgerFunc   func(p0 ibkr.WebsocketPrivateAccountLedgerParam) error
+	SubscribeOrderFunc             func(p0 ibkr.WebsocketPrivateOrderParam, p1 func(ibkr.WebsocketPrivateOrderResponse) error) (func() error, error)
+	SubscribeOrderV2Func           func(p0 func(ibkr.WebsocketPrivateOrderResponseV2) error) (func() error, error)
+	UnsubscribeOrderFunc           func(p0 ibkr.WebsocketPrivateOrderParam) error
+	SubscribePnLFunc               func(p0 func(ibkr.WebsocketPrivatePnLResponse) error) (func() error, error)
+	UnsubscribePnLFunc             func() error
+	SubscribeTradesDataFunc        func(p0 ibkr.WebsocketPrivateTradesDataParam, p1 func(ibkr.WebsocketPrivateTradesDataResponse) error) (func() error, error)
+	UnsubscribeTradesDataFunc      func(p0 ibkr.WebsocketPrivateTradesDataParam) error
+	StreamAccountSummaryFunc       func(p0 ibkr.WebsocketPrivateAccountSummaryParam, p1 ibkr.DeliveryOptions) (*ibkr.Subscription[ibkr.WebsocketPrivateAccountSummaryResponse], error)
+	StreamAccountLedgerFunc        func(p0 ibkr.WebsocketPrivateAccountLedgerParam, p1 ibkr.DeliveryOptions) (*ibkr.Subscription[ibkr.WebsocketPrivateAccountLedgerResponse], error)
+	StreamOrderFunc                func(p0 ibkr.WebsocketPrivateOrderParam, p1 ibkr.DeliveryOptions) (*ibkr.Subscription[ibkr.WebsocketPrivateOrderResponse], error)
+	StreamOrderV2Func              func(p0 ibkr.DeliveryOptions) (*ibkr.Subscription[ibkr.WebsocketPrivateOrderResponseV2], error)
+	StreamPnLFunc                  func(p0 ibkr.DeliveryOptions) (*ibkr.Subscription[ibkr.WebsocketPrivatePnLResponse], error)
+	StreamTradesDataFunc           func(p0 ibkr.WebsocketPrivateTradesDataParam, p1 ibkr.DeliveryOptions) (*ibkr.Subscription[ibkr.WebsocketPrivateTradesDataResponse], error)
+}
+
+var _ ibkr.WebsocketPrivateServiceI = (*WebsocketPrivateService)(nil)
+
+// Start :
+func (f *WebsocketPrivateService) Start(p0 context.Context, p1 ibkr.ErrHandler) (r0 error) {
+	f.record("Start", p0, p1)
+	if f.StartFunc == nil {
+		r0 = notProgrammed("WebsocketPrivateService.Start")
+		return
+	}
+	return f.StartFunc(p0, p1)
+}
+
+// Run :
+func (f *WebsocketPrivateService) Run() (r0 error) {
+	f.record("Run")
+	if f.RunFunc == nil {
+		r0 = notProgrammed("WebsocketPrivateService.Run")
+		return
+	}
+	return f.RunFunc()
+}
+
+// Ping :
+func (f *WebsocketPrivateService) Ping() (r0 error) {
+	f.record("Ping")
+	if f.PingFunc == nil {
+		r0 = notProgrammed("WebsocketPrivateService.Ping")
+		return
+	}
+	return f.PingFunc()
+}
+
+// Close :
+func (f *WebsocketPrivateService) Close() (r0 error) {
+	f.record("Close")
+	if f.CloseFunc == nil {
+		r0 = notProgrammed("WebsocketPrivateService.Close")
+		return
+	}
+	return f.CloseFunc()
+}
+
+// Shutdown :
+func (f *WebsocketPrivateService) Shutdown() (r0 error) {
+	f.record("Shutdown")
+	if f.ShutdownFunc == nil {
+		r0 = notProgrammed("WebsocketPrivateService.Shutdown")
+		return
+	}
+	return f.ShutdownFunc()
+}
+
+// SetAccountUpdatesChan :
+func (f *WebsocketPrivateService) SetAccountUpdatesChan(channel chan *ibkr.WebsocketUnsolicitedAccountUpdatesResponse) {
+	f.record("SetAccountUpdatesChan", channel)
+	if f.SetAccountUpdatesChanFunc == nil {
+		return
+	}
+	f.SetAccountUpdatesChanFunc(channel)
+}
+
+// SetAuthStatusChan :
+func (f *WebsocketPrivateService) SetAuthStatusChan(channel chan *ibkr.WebsocketUnsolicitedAuthStatusResponse) {
+	f.record("SetAuthStatusChan", channel)
+	if f.SetAuthStatusChanFunc == nil {
+		return
+	}
+	f.SetAuthStatusChanFunc(channel)
+}
+
+// SetSystemChan :
+func (f *WebsocketPrivateService) SetSystemChan(channel chan *ibkr.WebsocketUnsolicitedSystemConnectionResponse) {
+	f.record("SetSystemChan", channel)
+	if f.SetSystemChanFunc == nil {
+		return
+	}
+	f.SetSystemChanFunc(channel)
+}
+
+// SetBulletinsChan :
+func (f *WebsocketPrivateService) SetBulletinsChan(channel chan *ibkr.WebsocketUnsolicitedBulletinsResponse) {
+	f.record("SetBulletinsChan", channel)
+	if f.SetBulletinsChanFunc == nil {
+		return
+	}
+	f.SetBulletinsChanFunc(channel)
+}
+
+// SetNotificationsChan :
+func (f *WebsocketPrivateService) SetNotificationsChan(channel chan *ibkr.WebsocketUnsolicitedNotificationsResponse) {
+	f.record("SetNotificationsChan", channel)
+	if f.SetNotificationsChanFunc == nil {
+		return
+	}
+	f.SetNotificationsChanFunc(channel)
+}
+
+// SetUnhandledMessageHandler :
+func (f *WebsocketPrivateService) SetUnhandledMessageHandler(handler ibkr.UnhandledMessageHandler) {
+	f.record("SetUnhandledMessageHandler", handler)
+	if f.SetUnhandledMessageHandlerFunc == nil {
+		return
+	}
+	f.SetUnhandledMessageHandlerFunc(handler)
+}
+
+// SetMessageErrorHandler :
+func (f *WebsocketPrivateService) SetMessageErrorHandler(handler ibkr.MessageErrorHandler) {
+	f.record("SetMessageErrorHandler", handler)
+	if f.SetMessageErrorHandlerFunc == nil {
+		return
+	}
+	f.SetMessageErrorHandlerFunc(handler)
+}
+
+// SetDeliveryOptions :
+func (f *WebsocketPrivateService) SetDeliveryOptions(topic string, options ibkr.DeliveryOptions) (r0 error) {
+	f.record("SetDeliveryOptions", topic, options)
+	if f.SetDeliveryOptionsFunc == nil {
+		r0 = notProgrammed("WebsocketPrivateService.SetDeliveryOptions")
+		return
+	}
+	return f.SetDeliveryOptionsFunc(topic, options)
+}
+
+// DeliveryStats :
+func (f *WebsocketPrivateService) DeliveryStats(topic string) (r0 ibkr.DeliveryStats) {
+	f.record("DeliveryStats", topic)
+	if f.DeliveryStatsFunc == nil {
+		return
+	}
+	return f.DeliveryStatsFunc(topic)
+}
+
+// SetMonitor :
+func (f *WebsocketPrivateService) SetMonitor(monitor *ibkr.WebsocketMonitor) {
+	f.record("SetMonitor", monitor)
+	if f.SetMonitorFunc == nil {
+		return
+	}
+	f.SetMonitorFunc(monitor)
+}
+
+// SubscribeAccountSummary :
+func (f *WebsocketPrivateService) SubscribeAccountSummary(p0 ibkr.WebsocketPrivateAccountSummaryParam, p1 func(ibkr.WebsocketPrivateAccountSummaryResponse) error) (r0 func() error, r1 error) {
+	f.record("SubscribeAccountSummary", p0, p1)
+	if f.SubscribeAccountSummaryFunc == nil {
+		r1 = notProgrammed("WebsocketPrivateService.SubscribeAccountSummary")
+		return
+	}
+	return f.SubscribeAccountSummaryFunc(p0, p1)
+}
+
+// UnsubscribeAccountSummary :
+func (f *WebsocketPrivateService) UnsubscribeAccountSummary(p0 ibkr.WebsocketPrivateAccountSummaryParam) (r0 error) {
+	f.record("UnsubscribeAccountSummary", p0)
+	if f.UnsubscribeAccountSummaryFunc == nil {
+		r0 = notProgrammed("WebsocketPrivateService.UnsubscribeAccountSummary")
+		return
+	}
+	return f.UnsubscribeAccountSummaryFunc(p0)
+}
+
+// SubscribeAccountLedger :
+func (f *WebsocketPrivateService) SubscribeAccountLedger(p0 ibkr.WebsocketPrivateAccountLedgerParam, p1 func(ibkr.WebsocketPrivateAccountLedgerResponse) error) (r0 func() error, r1 error) {
+	f.record("SubscribeAccountLedger", p0, p1)
+	if f.SubscribeAccountLedgerFunc == nil {
+		r1 = notProgrammed("WebsocketPrivateService.SubscribeAccountLedger")
+		return
+	}
+	return f.SubscribeAccountLedgerFunc(p0, p1)
+}
+
+// UnsubscribeAccountLedger :
+func (f *WebsocketPrivateService) UnsubscribeAccountLedger(p0 ibkr.WebsocketPrivateAccountLedgerParam) (r0 error) {
+	f.record("UnsubscribeAccountLedger", p0)
+	if f.UnsubscribeAccountLedgerFunc == nil {
+		r0 = notProgrammed("WebsocketPrivateService.UnsubscribeAccountLedger")
+		return
+	}
+	return f.UnsubscribeAccountLedgerFunc(p0)
+}
+
+// SubscribeOrder :
+func (f *WebsocketPrivateService) SubscribeOrder(p0 ibkr.WebsocketPrivateOrderParam, p1 func(ibkr.WebsocketPrivateOrderResponse) error) (r0 func() error, r1 error) {
+	f.record("SubscribeOrder", p0, p1)
+	if f.SubscribeOrderFunc == nil {
+		r1 = notProgrammed("WebsocketPrivateService.SubscribeOrder")
+		return
+	}
+	return f.SubscribeOrderFunc(p0, p1)
+}
+
+// SubscribeOrderV2 :
+func (f *WebsocketPrivateService) SubscribeOrderV2(p0 func(ibkr.WebsocketPrivateOrderResponseV2) error) (r0 func() error, r1 error) {
+	f.record("SubscribeOrderV2", p0)
+	if f.SubscribeOrderV2Func == nil {
+		r1 = notProgrammed("WebsocketPrivateService.SubscribeOrderV2")
+		return
+	}
+	return f.SubscribeOrderV2Func(p0)
+}
+
+// UnsubscribeOrder :
+func (f *WebsocketPrivateService) UnsubscribeOrder(p0 ibkr.WebsocketPrivateOrderParam) (r0 error) {
+	f.record("UnsubscribeOrder", p0)
+	if f.UnsubscribeOrderFunc == nil {
+		r0 = notProgrammed("WebsocketPrivateService.UnsubscribeOrder")
+		return
+	}
+	return f.UnsubscribeOrderFunc(p0)
+}
+
+// SubscribePnL :
+func (f *WebsocketPrivateService) SubscribePnL(p0 func(ibkr.WebsocketPrivatePnLResponse) error) (r0 func() error, r1 error) {
+	f.record("SubscribePnL", p0)
+	if f.SubscribePnLFunc == nil {
+		r1 = notProgrammed("WebsocketPrivateService.SubscribePnL")
+		return
+	}
+	return f.SubscribePnLFunc(p0)
+}
+
+// UnsubscribePnL :
+func (f *WebsocketPrivateService) UnsubscribePnL() (r0 error) {
+	f.record("UnsubscribePnL")
+	if f.UnsubscribePnLFunc == nil {
+		r0 = notProgrammed("WebsocketPrivateService.UnsubscribePnL")
+		return
+	}
+	return f.UnsubscribePnLFunc()
+}
+
+// SubscribeTradesData :
+func (f *WebsocketPrivateService) SubscribeTradesData(p0 ibkr.WebsocketPrivateTradesDataParam, p1 func(ibkr.WebsocketPrivateTradesDataResponse) error) (r0 func() error, r1 error) {
+	f.record("SubscribeTradesData", p0, p1)
+	if f.SubscribeTradesDataFunc == nil {
+		r1 = notProgrammed("WebsocketPrivateService.SubscribeTradesData")
+		return
+	}
+	return f.SubscribeTradesDataFunc(p0, p1)
+}
+
+// UnsubscribeTradesData :
+func (f *WebsocketPrivateService) UnsubscribeTradesData(p0 ibkr.WebsocketPrivateTradesDataParam) (r0 error) {
+	f.record("UnsubscribeTradesData", p0)
+	if f.UnsubscribeTradesDataFunc == nil {
+		r0 = notProgrammed("WebsocketPrivateService.UnsubscribeTradesData")
+		return
+	}
+	return f.UnsubscribeTradesDataFunc(p0)
+}
+
+// StreamAccountSummary :
+func (f *WebsocketPrivateService) StreamAccountSummary(p0 ibkr.WebsocketPrivateAccountSummaryParam, p1 ibkr.DeliveryOptions) (r0 *ibkr.Subscription[ibkr.WebsocketPrivateAccountSummaryResponse], r1 error) {
+	f.record("StreamAccountSummary", p0, p1)
+	if f.StreamAccountSummaryFunc == nil {
+		r1 = notProgrammed("WebsocketPrivateService.StreamAccountSummary")
+		return
+	}
+	return f.StreamAccountSummaryFunc(p0, p1)
+}
+
+// StreamAccountLedger :
+func (f *WebsocketPrivateService) StreamAccountLedger(p0 ibkr.WebsocketPrivateAccountLedgerParam, p1 ibkr.DeliveryOptions) (r0 *ibkr.Subscription[ibkr.WebsocketPrivateAccountLedgerResponse], r1 error) {
+	f.record("StreamAccountLedger", p0, p1)
+	if f.StreamAccountLedgerFunc == nil {
+		r1 = notProgrammed("WebsocketPrivateService.StreamAccountLedger")
+		return
+	}
+	return f.StreamAccountLedgerFunc(p0, p1)
+}
+
+// StreamOrder :
+func (f *WebsocketPrivateService) StreamOrder(p0 ibkr.WebsocketPrivateOrderParam, p1 ibkr.DeliveryOptions) (r0 *ibkr.Subscription[ibkr.WebsocketPrivateOrderResponse], r1 error) {
+	f.record("StreamOrder", p0, p1)
+	if f.StreamOrderFunc == nil {
+		r1 = notProgrammed("WebsocketPrivateService.StreamOrder")
+		return
+	}
+	return f.StreamOrderFunc(p0, p1)
+}
+
+// StreamOrderV2 :
+func (f *WebsocketPrivateService) StreamOrderV2(p0 ibkr.DeliveryOptions) (r0 *ibkr.Subscription[ibkr.WebsocketPrivateOrderResponseV2], r1 error) {
+	f.record("StreamOrderV2", p0)
+	if f.StreamOrderV2Func == nil {
+		r1 = notProgrammed("WebsocketPrivateService.StreamOrderV2")
+		return
+	}
+	return f.StreamOrderV2Func(p0)
+}
+
+// StreamPnL :
+func (f *WebsocketPrivateService) StreamPnL(p0 ibkr.DeliveryOptions) (r0 *ibkr.Subscription[ibkr.WebsocketPrivatePnLResponse], r1 error) {
+	f.record("StreamPnL", p0)
+	if f.StreamPnLFunc == nil {
+		r1 = notProgrammed("WebsocketPrivateService.StreamPnL")
+		return
+	}
+	return f.StreamPnLFunc(p0)
+}
+
+// StreamTradesData :
+func (f *WebsocketPrivateService) StreamTradesData(p0 ibkr.WebsocketPrivateTradesDataParam, p1 ibkr.DeliveryOptions) (r0 *ibkr.Subscription[ibkr.WebsocketPrivateTradesDataResponse], r1 error) {
+	f.record("StreamTradesData", p0, p1)
+	if f.StreamTradesDataFunc == nil {
+		r1 = notProgrammed("WebsocketPrivateService.StreamTradesData")
+		return
+	}
+	return f.StreamTradesDataFunc(p0, p1)
+}
+
+// WebsocketPublicService :
+// A programmable fake of ibkr.WebsocketPublicServiceI.
+type WebsocketPublicService struct {
+	recorder
+
+	StartFunc                           func(p0 context.Context, p1 ibkr.ErrHandler) error
+	RunFunc                             func() error
+	PingFunc                            func() error
+	CloseFunc                           func() error
+	ShutdownFunc                        func() error
+	SetAccountUpdatesChanFunc           func(channel chan *ibkr.WebsocketUnsolicitedAccountUpdatesResponse)
+	SetAuthStatusChanFunc               func(channel chan *ibkr.WebsocketUnsolicitedAuthStatusResponse)
+	SetSystemChanFunc                   func(channel chan *ibkr.WebsocketUnsolicitedSystemConnectionResponse)
+	SetBulletinsChanFunc                func(channel chan *ibkr.WebsocketUnsolicitedBulletinsResponse)
+	SetNotificationsChanFunc            func(channel chan *ibkr.WebsocketUnsolicitedNotificationsResponse)
+	SetUnhandledMessageHandlerFunc      func(handler ibkr.UnhandledMessageHandler)
+	SetMessageErrorHandlerFunc          func(handler ibkr.MessageErrorHandler)
+	SetDeliveryOptionsFunc              func(topic string, options ibkr.DeliveryOptions) error
+	DeliveryStatsFunc                   func(topic string) ibkr.DeliveryStats
+	SetMonitorFunc                      func(monitor *ibkr.WebsocketMonitor)
+	SubscribeMarketDataFunc             func(p0 ibkr.WebsocketPublicMarketDataParam, p1 func(ibkr.WebsocketPublicMarketDataResponse) error) (func() error, error)
+	UnsubscribeMarketDataFunc           func(p0 ibkr.WebsocketPublicMarketDataParam) error
+	SubscribeHistoricalTickerFunc       func(p0 ibkr.WebsocketPublicHistoricalMarketDataParam, p1 func(ibkr.WebsocketPublicHistoricalMarketDataResponse) error) (func() error, error)
+	UnsubscribeHistoricalMarketDataFunc func(p0 ibkr.WebsocketPublicHistoricalMarketDataParam) error
+	SubscribeBookTraderFunc             func(p0 ibkr.WebsocketPublicBookTraderParam, p1 func(ibkr.WebsocketPublicBookTraderResponse) error) (func() error, error)
+	UnsubscribeBookTraderFunc           func(p0 ibkr.WebsocketPublicBookTraderParam) error
+	StreamMarketDataFunc                func(p0 ibkr.WebsocketPublicMarketDataParam, p1 ibkr.DeliveryOptions) (*ibkr.Subscription[ibkr.WebsocketPublicMarketDataResponse], error)
+	StreamHistoricalTickerFunc          func(p0 ibkr.WebsocketPublicHistoricalMarketDataParam, p1 ibkr.DeliveryOptions) (*ibkr.Subscription[ibkr.WebsocketPublicHistoricalMarketDataResponse], error)
+	StreamBookTraderFunc                func(p0 ibkr.WebsocketPublicBookTraderParam, p1 ibkr.DeliveryOptions) (*ibkr.Subscription[ibkr.WebsocketPublicBookTraderResponse], error)
+}
+
+var _ ibkr.WebsocketPublicServiceI = (*WebsocketPublicService)(nil)
+
+// Start :
+func (f *WebsocketPublicService) Start(p0 context.Context, p1 ibkr.ErrHandler) (r0 error) {
+	f.record("Start", p0, p1)
+	if f.StartFunc == nil {
+		r0 = notProgrammed("WebsocketPublicService.Start")
+		return
+	}
+	return f.StartFunc(p0, p1)
+}
+
+// Run :
+func (f *WebsocketPublicService) Run() (r0 error) {
+	f.record("Run")
+	if f.RunFunc == nil {
+		r0 = notProgrammed("WebsocketPublicService.Run")
+		return
+	}
+	return f.RunFunc()
+}
+
+// Ping :
+func (f *WebsocketPublicService) Ping() (r0 error) {
+	f.record("Ping")
+	if f.PingFunc == nil {
+		r0 = notProgrammed("WebsocketPublicService.Ping")
+		return
+	}
+	return f.PingFunc()
+}
+
+// Close :
+func (f *WebsocketPublicService) Close() (r0 error) {
+	f.record("Close")
+	if f.CloseFunc == nil {
+		r0 = notProgrammed("WebsocketPublicService.Close")
+		return
+	}
+	return f.CloseFunc()
+}
+
+// Shutdown :
+func (f *WebsocketPublicService) Shutdown() (r0 error) {
+	f.record("Shutdown")
+	if f.ShutdownFunc == nil {
+		r0 = notProgrammed("WebsocketPublicService.Shutdown")
+		return
+	}
+	return f.ShutdownFunc()
+}
+
+// SetAccountUpdatesChan :
+func (f *WebsocketPublicService) SetAccountUpdatesChan(channel chan *ibkr.WebsocketUnsolicitedAccountUpdatesResponse) {
+	f.record("SetAccountUpdatesChan", channel)
+	if f.SetAccountUpdatesChanFunc == nil {
+		return
+	}
+	f.SetAccountUpdatesChanFunc(channel)
+}
+
+// SetAuthStatusChan :
+func (f *WebsocketPublicService) SetAuthStatusChan(channel chan *ibkr.WebsocketUnsolicitedAuthStatusResponse) {
+	f.record("SetAuthStatusChan", channel)
+	if f.SetAuthStatusChanFunc == nil {
+		return
+	}
+	f.SetAuthStatusChanFunc(channel)
+}
+
+// SetSystemChan :
+func (f *WebsocketPublicService) SetSystemChan(channel chan *ibkr.WebsocketUnsolicitedSystemConnectionResponse) {
+	f.record("SetSystemChan", channel)
+	if f.SetSystemChanFunc == nil {
+		return
+	}
+	f.SetSystemChanFunc(channel)
+}
+
+// SetBulletinsChan :
+func (f *WebsocketPublicService) SetBulletinsChan(channel chan *ibkr.WebsocketUnsolicitedBulletinsResponse) {
+	f.record("SetBulletinsChan", channel)
+	if f.SetBulletinsChanFunc == nil {
+		return
+	}
+	f.SetBulletinsChanFunc(channel)
+}
+
+// SetNotificationsChan :
+func (f *WebsocketPublicService) SetNotificationsChan(channel chan *ibkr.WebsocketUnsolicitedNotificationsResponse) {
+	f.record("SetNotificationsChan", channel)
+	if f.SetNotificationsChanFunc == nil {
+		return
+	}
+	f.SetNotificationsChanFunc(channel)
+}
+
+// SetUnhandledMessageHandler :
+func (f *WebsocketPublicService) SetUnhandledMessageHandler(handler ibkr.UnhandledMessageHandler) {
+	f.record("SetUnhandledMessageHandler", handler)
+	if f.SetUnhandledMessageHandlerFunc == nil {
+		return
+	}
+	f.SetUnhandledMessageHandlerFunc(handler)
+}
+
+// SetMessageErrorHandler :
+func (f *WebsocketPublicService) SetMessageErrorHandler(handler ibkr.MessageErrorHandler) {
+	f.record("SetMessageErrorHandler", handler)
+	if f.SetMessageErrorHandlerFunc == nil {
+		return
+	}
+	f.SetMessageErrorHandlerFunc(handler)
+}
+
+// SetDeliveryOptions :
+func (f *WebsocketPublicService) SetDeliveryOptions(topic string, options ibkr.DeliveryOptions) (r0 error) {
+	f.record("SetDeliveryOptions", topic, options)
+	if f.SetDeliveryOptionsFunc == nil {
+		r0 = notProgrammed("WebsocketPublicService.SetDeliveryOptions")
+		return
+	}
+	return f.SetDeliveryOptionsFunc(topic, options)
+}
+
+// DeliveryStats :
+func (f *WebsocketPublicService) DeliveryStats(topic string) (r0 ibkr.DeliveryStats) {
+	f.record("DeliveryStats", topic)
+	if f.DeliveryStatsFunc == nil {
+		return
+	}
+	return f.DeliveryStatsFunc(topic)
+}
+
+// SetMonitor :
+func (f *WebsocketPublicService) SetMonitor(monitor *ibkr.WebsocketMonitor) {
+	f.record("SetMonitor", monitor)
+	if f.SetMonitorFunc == nil {
+		return
+	}
+	f.SetMonitorFunc(monitor)
+}
+
+// SubscribeMarketData :
+func (f *WebsocketPublicService) SubscribeMarketData(p0 ibkr.WebsocketPublicMarketDataParam, p1 func(ibkr.WebsocketPublicMarketDataResponse) error) (r0 func() error, r1 error) {
+	f.record("SubscribeMarketData", p0, p1)
+	if f.SubscribeMarketDataFunc == nil {
+		r1 = notProgrammed("WebsocketPublicService.SubscribeMarketData")
+		return
+	}
+	return f.SubscribeMarketDataFunc(p0, p1)
+}
+
+// UnsubscribeMarketData :
+func (f *WebsocketPublicService) UnsubscribeMarketData(p0 ibkr.WebsocketPublicMarketDataParam) (r0 error) {
+	f.record("UnsubscribeMarketData", p0)
+	if f.UnsubscribeMarketDataFunc == nil {
+		r0 = notProgrammed("WebsocketPublicService.UnsubscribeMarketData")
+		return
+	}
+	return f.UnsubscribeMarketDataFunc(p0)
+}
+
+// SubscribeHistoricalTicker :
+func (f *WebsocketPublicService) SubscribeHistoricalTicker(p0 ibkr.WebsocketPublicHistoricalMarketDataParam, p1 func(ibkr.WebsocketPublicHistoricalMarketDataResponse) error) (r0 func() error, r1 error) {
+	f.record("SubscribeHistoricalTicker", p0, p1)
+	if f.SubscribeHistoricalTickerFunc == nil {
+		r1 = notProgrammed("WebsocketPublicService.SubscribeHistoricalTicker")
+		return
+	}
+	return f.SubscribeHistoricalTickerFunc(p0, p1)
+}
+
+// UnsubscribeHistoricalMarketData :
+func (f *WebsocketPublicService) UnsubscribeHistoricalMarketData(p0 ibkr.WebsocketPublicHistoricalMarketDataParam) (r0 error) {
+	f.record("UnsubscribeHistoricalMarketData", p0)
+	if f.UnsubscribeHistoricalMarketDataFunc == nil {
+		r0 = notProgrammed("WebsocketPublicService.UnsubscribeHistoricalMarketData")
+		return
+	}
+	return f.UnsubscribeHistoricalMarketDataFunc(p0)
+}
+
+// SubscribeBookTrader :
+func (f *WebsocketPublicService) SubscribeBookTrader(p0 ibkr.WebsocketPublicBookTraderParam, p1 func(ibkr.WebsocketPublicBookTraderResponse) error) (r0 func() error, r1 error) {
+	f.record("SubscribeBookTrader", p0, p1)
+	if f.SubscribeBookTraderFunc == nil {
+		r1 = notProgrammed("WebsocketPublicService.SubscribeBookTrader")
+		return
+	}
+	return f.SubscribeBookTraderFunc(p0, p1)
+}
+
+// UnsubscribeBookTrader :
+func (f *WebsocketPublicService) UnsubscribeBookTrader(p0 ibkr.WebsocketPublicBookTraderParam) (r0 error) {
+	f.record("UnsubscribeBookTrader", p0)
+	if f.UnsubscribeBookTraderFunc == nil {
+		r0 = notProgrammed("WebsocketPublicService.UnsubscribeBookTrader")
+		return
+	}
+	return f.UnsubscribeBookTraderFunc(p0)
+}
+
+// StreamMarketData :
+func (f *WebsocketPublicService) StreamMarketData(p0 ibkr.WebsocketPublicMarketDataParam, p1 ibkr.DeliveryOptions) (r0 *ibkr.Subscription[ibkr.WebsocketPublicMarketDataResponse], r1 error) {
+	f.record("StreamMarketData", p0, p1)
+	if f.StreamMarketDataFunc == nil {
+		r1 = notProgrammed("WebsocketPublicService.StreamMarketData")
+		return
+	}
+	return f.StreamMarketDataFunc(p0, p1)
+}
+
+// StreamHistoricalTicker :
+func (f *WebsocketPublicService) StreamHistoricalTicker(p0 ibkr.WebsocketPublicHistoricalMarketDataParam, p1 ibkr.DeliveryOptions) (r0 *ibkr.Subscription[ibkr.WebsocketPublicHistoricalMarketDataResponse], r1 error) {
+	f.record("StreamHistoricalTicker", p0, p1)
+	if f.StreamHistoricalTickerFunc == nil {
+		r1 = notProgrammed("WebsocketPublicService.StreamHistoricalTicker")
+		return
+	}
+	return f.StreamHistoricalTickerFunc(p0, p1)
+}
+
+// StreamBookTrader :
+func (f *WebsocketPublicService) StreamBookTrader(p0 ibkr.WebsocketPublicBookTraderParam, p1 ibkr.DeliveryOptions) (r0 *ibkr.Subscription[ibkr.WebsocketPublicBookTraderResponse], r1 error) {
+	f.record("StreamBookTrader", p0, p1)
+	if f.StreamBookTraderFunc == nil {
+		r1 = notProgrammed("WebsocketPublicService.StreamBookTrader")
+		return
+	}
+	return f.StreamBookTraderFunc(p0, p1)
+}
@@ -0,0 +1,281 @@
+//go:build ignore
+
+// gen.go writes fakes_gen.go with one fake per service interface of package
+// ibkr. Run it with go generate after changing an interface. -output writes
+// to another file, which TestGenerated compares with fakes_gen.go.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const modulePath = "github.com/dictxwang/go-ibkr"
+
+type method struct {
+	name    string
+	params  []param
+	results []string
+}
+
+type param struct {
+	name     string
+	typ      string
+	variadic bool
+}
+
+type fake struct {
+	iface   string
+	methods []method
+}
+
+var output = flag.String("output", "fakes_gen.go", "file to write")
+
+func main() {
+	flag.Parse()
+
+	fset := token.NewFileSet()
+	packages, err := parser.ParseDir(fset, "..", func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	imports := map[string]string{}
+	var fakes []fake
+	for _, file := range packages["ibkr"].Files {
+		fileImports := map[string]string{}
+		for _, spec := range file.Imports {
+			path, _ := strconv.Unquote(spec.Path.Value)
+			name := filepath.Base(path)
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			fileImports[name] = path
+		}
+		ast.Inspect(file, func(node ast.Node) bool {
+			spec, ok := node.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			iface, ok := spec.Type.(*ast.InterfaceType)
+			if !ok || !strings.HasSuffix(spec.Name.Name, "ServiceI") {
+				return false
+			}
+			q := &qualifier{imports: fileImports, used: imports}
+			f := fake{iface: spec.Name.Name}
+			for _, field := range iface.Methods.List {
+				funcType := field.Type.(*ast.FuncType)
+				f.methods = append(f.methods, method{
+					name:    field.Names[0].Name,
+					params:  q.params(funcType.Params),
+					results: q.results(funcType.Results),
+				})
+			}
+			fakes = append(fakes, f)
+			return false
+		})
+	}
+	sort.Slice(fakes, func(i, j int) bool { return fakes[i].iface < fakes[j].iface })
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gen.go; DO NOT EDIT.\n\npackage fakes\n\nimport (\n")
+	var paths []string
+	for _, path := range imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Fprintf(&buf, "\t%q\n", path)
+	}
+	fmt.Fprintf(&buf, "\n\tibkr %q\n)\n", modulePath)
+	for _, f := range fakes {
+		writeFake(&buf, f)
+	}
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("%v\n%s", err, buf.String())
+	}
+	if err := os.WriteFile(*output, source, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func writeFake(buf *bytes.Buffer, f fake) {
+	name := strings.TrimSuffix(f.iface, "I")
+	fmt.Fprintf(buf, "\n// %s :\n// A programmable fake of ibkr.%s.\ntype %s struct {\n\trecorder\n\n", name, f.iface, name)
+	for _, m := range f.methods {
+		fmt.Fprintf(buf, "\t%sFunc func(%s) %s\n", m.name, m.paramList(), m.resultList(false))
+	}
+	fmt.Fprintf(buf, "}\n\nvar _ ibkr.%s = (*%s)(nil)\n", f.iface, name)
+
+	for _, m := range f.methods {
+		fmt.Fprintf(buf, "\n// %s :\nfunc (f *%s) %s(%s) %s {\n", m.name, name, m.name, m.paramList(), m.resultList(true))
+		var args, callArgs []string
+		for _, p := range m.params {
+			args = append(args, p.name)
+			if p.variadic {
+				callArgs = append(callArgs, p.name+"...")
+			} else {
+				callArgs = append(callArgs, p.name)
+			}
+		}
+		fmt.Fprintf(buf, "\tf.record(%s)\n", strings.Join(append([]string{strconv.Quote(m.name)}, args...), ", "))
+		fmt.Fprintf(buf, "\tif f.%sFunc == nil {\n", m.name)
+		if n := len(m.results); n > 0 && m.results[n-1] == "error" {
+			fmt.Fprintf(buf, "\t\tr%d = notProgrammed(%q)\n", n-1, name+"."+m.name)
+		}
+		if len(m.results) > 0 {
+			buf.WriteString("\t\treturn\n\t}\n")
+			fmt.Fprintf(buf, "\treturn f.%sFunc(%s)\n}\n", m.name, strings.Join(callArgs, ", "))
+		} else {
+			buf.WriteString("\t\treturn\n\t}\n")
+			fmt.Fprintf(buf, "\tf.%sFunc(%s)\n}\n", m.name, strings.Join(callArgs, ", "))
+		}
+	}
+}
+
+func (m method) paramList() string {
+	var list []string
+	for _, p := range m.params {
+		typ := p.typ
+		if p.variadic {
+			typ = "..." + typ
+		}
+		list = append(list, p.name+" "+typ)
+	}
+	return strings.Join(list, ", ")
+}
+
+// resultList :
+// Named results let an unprogrammed method return zero values of any type.
+func (m method) resultList(named bool) string {
+	if len(m.results) == 0 {
+		return ""
+	}
+	var list []string
+	for i, result := range m.results {
+		if named {
+			result = fmt.Sprintf("r%d %s", i, result)
+		}
+		list = append(list, result)
+	}
+	if len(list) == 1 && !named {
+		return list[0]
+	}
+	return "(" + strings.Join(list, ", ") + ")"
+}
+
+// qualifier :
+// Prints types of package ibkr as seen from package fakes.
+type qualifier struct {
+	imports map[string]string
+	used    map[string]string
+}
+
+func (q *qualifier) params(fields *ast.FieldList) []param {
+	var params []param
+	for _, field := range fields.List {
+		typ, variadic := field.Type, false
+		if ellipsis, ok := typ.(*ast.Ellipsis); ok {
+			typ, variadic = ellipsis.Elt, true
+		}
+		names := field.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{nil}
+		}
+		for _, ident := range names {
+			name := fmt.Sprintf("p%d", len(params))
+			if ident != nil && ident.Name != "f" && ident.Name != "_" {
+				name = ident.Name
+			}
+			params = append(params, param{name: name, typ: q.typeString(typ), variadic: variadic})
+		}
+	}
+	return params
+}
+
+func (q *qualifier) results(fields *ast.FieldList) []string {
+	if fields == nil {
+		return nil
+	}
+	var results []string
+	for _, field := range fields.List {
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			results = append(results, q.typeString(field.Type))
+		}
+	}
+	return results
+}
+
+func (q *qualifier) typeString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if types.Universe.Lookup(t.Name) != nil {
+			return t.Name
+		}
+		return "ibkr." + t.Name
+	case *ast.SelectorExpr:
+		pkg := t.X.(*ast.Ident).Name
+		q.used[pkg] = q.imports[pkg]
+		return pkg + "." + t.Sel.Name
+	case *ast.StarExpr:
+		return "*" + q.typeString(t.X)
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return "[]" + q.typeString(t.Elt)
+		}
+		return "[" + t.Len.(*ast.BasicLit).Value + "]" + q.typeString(t.Elt)
+	case *ast.MapType:
+		return "map[" + q.typeString(t.Key) + "]" + q.typeString(t.Value)
+	case *ast.ChanType:
+		switch t.Dir {
+		case ast.SEND:
+			return "chan<- " + q.typeString(t.Value)
+		case ast.RECV:
+			return "<-chan " + q.typeString(t.Value)
+		}
+		return "chan " + q.typeString(t.Value)
+	case *ast.IndexExpr:
+		return q.typeString(t.X) + "[" + q.typeString(t.Index) + "]"
+	case *ast.IndexListExpr:
+		var indices []string
+		for _, index := range t.Indices {
+			indices = append(indices, q.typeString(index))
+		}
+		return q.typeString(t.X) + "[" + strings.Join(indices, ", ") + "]"
+	case *ast.InterfaceType:
+		return "interface{}"
+	case *ast.FuncType:
+		m := method{params: q.params(t.Params), results: q.results(t.Results)}
+		var params []string
+		for _, p := range m.params {
+			if p.variadic {
+				params = append(params, "..."+p.typ)
+			} else {
+				params = append(params, p.typ)
+			}
+		}
+		return "func(" + strings.Join(params, ", ") + ") " + m.resultList(false)
+	}
+	log.Fatalf("unsupported type %T", expr)
+	return ""
+}
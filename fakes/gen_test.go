@@ -0,0 +1,35 @@
+package fakes_test
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestGenerated fails when fakes_gen.go is not what gen.go writes for the
+// current service interfaces, i.e. when go generate was not run after an
+// interface changed.
+func TestGenerated(t *testing.T) {
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("the go tool is not installed")
+	}
+	output := filepath.Join(t.TempDir(), "fakes_gen.go")
+	if out, err := exec.Command(goTool, "run", "gen.go", "-output", output).CombinedOutput(); err != nil {
+		t.Fatalf("gen.go: %v\n%s", err, out)
+	}
+
+	generated, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	committed, err := os.ReadFile("fakes_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(generated, committed) {
+		t.Error("fakes_gen.go is out of date with the service interfaces, run go generate ./fakes")
+	}
+}
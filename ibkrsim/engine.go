@@ -0,0 +1,503 @@
+package ibkrsim
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+)
+
+// averagePriceScale :
+// The digits after the point of reported average prices.
+const averagePriceScale = 8
+
+// order :
+// The simulator state of one order.
+type order struct {
+	id     int64
+	param  ibkr.PlaceOrderParam
+	status ibkr.OrderStatus
+	filled ibkr.Decimal
+	value  ibkr.Decimal // sum of quantity * price over all fills
+
+	// triggered is set once the stop of a STP, STP LMT or TRAIL order is hit.
+	triggered bool
+	// extreme is the best price seen by a TRAIL order, its stop trails it.
+	extreme ibkr.Decimal
+	// group is the OCA group of the order, 0 if it has none.
+	group int64
+
+	placed   time.Time
+	executed time.Time
+}
+
+func (o *order) buy() bool {
+	return strings.EqualFold(o.param.Side, string(ibkr.OrderSideBuy))
+}
+
+func (o *order) remaining() ibkr.Decimal {
+	return o.param.Quantity.Sub(o.filled)
+}
+
+func (o *order) active() bool {
+	return o.status == ibkr.OrderStatusPreSubmitted || o.status == ibkr.OrderStatusSubmitted
+}
+
+func (o *order) averagePrice() ibkr.Decimal {
+	if o.filled.IsZero() {
+		return ibkr.Decimal{}
+	}
+	return o.value.Div(o.filled, averagePriceScale)
+}
+
+func (o *order) timeInForce() ibkr.TimeInForce {
+	if o.param.TimeInForce == "" {
+		return ibkr.TimeInForceDAY
+	}
+	return o.param.TimeInForce
+}
+
+// stopped :
+// Reports whether the order waits for a stop price before it can execute.
+func (o *order) stopped() bool {
+	switch o.param.OrderType {
+	case ibkr.OrderTypeStop, ibkr.OrderTypeStopLimit, ibkr.OrderTypeTrailing:
+		return !o.triggered
+	}
+	return false
+}
+
+// limit :
+// Returns the limit price, or false for orders executing at market.
+func (o *order) limit() (ibkr.Decimal, bool) {
+	switch o.param.OrderType {
+	case ibkr.OrderTypeLimit, ibkr.OrderTypeStopLimit:
+		return *o.param.Price, true
+	}
+	return ibkr.Decimal{}, false
+}
+
+// stopPrice :
+// Price is the stop of a STP order; a STP LMT order carries its limit in
+// Price and its stop in AuxPrice, like the gateway expects.
+func (o *order) stopPrice() ibkr.Decimal {
+	switch o.param.OrderType {
+	case ibkr.OrderTypeStop:
+		return *o.param.Price
+	case ibkr.OrderTypeStopLimit:
+		return *o.param.AuxPrice
+	case ibkr.OrderTypeTrailing:
+		offset := *o.param.TrailingAmount
+		if o.param.TrailingType == ibkr.TrailingTypePercent {
+			offset = o.extreme.Mul(offset).Mul(ibkr.NewDecimal(1, 2))
+		}
+		if o.buy() {
+			return o.extreme.Add(offset)
+		}
+		return o.extreme.Sub(offset)
+	}
+	return ibkr.Decimal{}
+}
+
+// validate :
+// Returns the reason the simulator rejects param, or "".
+func validate(param ibkr.PlaceOrderParam) string {
+	if param.ContractId == nil {
+		return "conid is required"
+	}
+	if param.Quantity.Sign() <= 0 {
+		return "quantity must be positive"
+	}
+	if !strings.EqualFold(param.Side, string(ibkr.OrderSideBuy)) && !strings.EqualFold(param.Side, string(ibkr.OrderSideSell)) {
+		return fmt.Sprintf("unsupported side %q", param.Side)
+	}
+	switch param.OrderType {
+	case ibkr.OrderTypeMarket:
+	case ibkr.OrderTypeLimit, ibkr.OrderTypeStop:
+		if param.Price == nil {
+			return fmt.Sprintf("%s order requires price", param.OrderType)
+		}
+	case ibkr.OrderTypeStopLimit:
+		if param.Price == nil || param.AuxPrice == nil {
+			return "STP LMT order requires price and auxPrice"
+		}
+	case ibkr.OrderTypeTrailing:
+		if param.TrailingAmount == nil || param.TrailingAmount.Sign() <= 0 {
+			return "TRAIL order requires a positive trailingAmt"
+		}
+	default:
+		return fmt.Sprintf("unsupported order type %q", param.OrderType)
+	}
+	switch param.TimeInForce {
+	case "", ibkr.TimeInForceDAY, ibkr.TimeInForceGTC, ibkr.TimeInForceIOC, ibkr.TimeInForceFOK:
+	default:
+		return fmt.Sprintf("unsupported tif %q", param.TimeInForce)
+	}
+	return ""
+}
+
+// OnMarketData :
+// Feeds one smd update, live or recorded, and fills the orders it crosses.
+// It has the signature of a SubscribeMarketData handler.
+func (s *SimulatedOrders) OnMarketData(tick ibkr.WebsocketPublicMarketDataResponse) error {
+	contractId := tick.ContractId
+	if contractId == 0 {
+		topic := ibkr.ParseWebsocketTopic(tick.Topic)
+		if len(topic.Args) == 0 {
+			return nil
+		}
+		id, err := strconv.Atoi(topic.Args[0])
+		if err != nil {
+			return err
+		}
+		contractId = id
+	}
+
+	s.mutex.Lock()
+	if tick.UpdateTime != 0 {
+		s.now = time.UnixMilli(tick.UpdateTime)
+	} else {
+		s.now = s.clock()
+	}
+	quote := s.quotes[contractId]
+	quote.Merge(tick)
+	quote.ContractId = contractId
+	s.quotes[contractId] = quote
+
+	var e events
+	s.expire(&e)
+	book := newLiquidity(quote, tick)
+	for _, o := range s.orders {
+		if o.active() && *o.param.ContractId == contractId {
+			s.match(o, quote, book, &e)
+		}
+	}
+	s.mutex.Unlock()
+
+	s.publish(e)
+	return nil
+}
+
+// expire :
+// Cancels DAY orders placed before the current day.
+func (s *SimulatedOrders) expire(e *events) {
+	today := s.currentTime().In(s.location).Format(time.DateOnly)
+	for _, o := range s.orders {
+		if o.active() && o.timeInForce() == ibkr.TimeInForceDAY && o.placed.In(s.location).Format(time.DateOnly) != today {
+			s.setStatus(o, ibkr.OrderStatusCancelled, e)
+		}
+	}
+}
+
+// liquidity :
+// The size available on each side within one tick, shared by all orders so
+// two orders cannot take the same displayed size. Unknown sizes are infinite.
+type liquidity struct {
+	bid, ask, last *size
+	trade          bool // the tick carries a new last trade
+}
+
+// size :
+// A displayed size; unlimited when the tick did not carry one.
+type size struct {
+	value     ibkr.Decimal
+	unlimited bool
+}
+
+func (s *size) covers(quantity ibkr.Decimal) bool {
+	return s.unlimited || s.value.Cmp(quantity) >= 0
+}
+
+// take :
+// Returns the part of quantity the size covers and removes it.
+func (s *size) take(quantity ibkr.Decimal) ibkr.Decimal {
+	if s.unlimited {
+		return quantity
+	}
+	if s.value.LessThan(quantity) {
+		quantity = s.value
+	}
+	s.value = s.value.Sub(quantity)
+	return quantity
+}
+
+func newLiquidity(quote, tick ibkr.WebsocketPublicMarketDataResponse) *liquidity {
+	return &liquidity{
+		bid:   parseSize(quote.BidSize),
+		ask:   parseSize(quote.AskSize),
+		last:  parseSize(tick.LastSize),
+		trade: tick.LastPrice != "",
+	}
+}
+
+// match :
+// Triggers and fills o against quote as far as book allows. A FOK order
+// only fills when book covers all of it.
+func (s *SimulatedOrders) match(o *order, quote ibkr.WebsocketPublicMarketDataResponse, book *liquidity, e *events) {
+	if !s.released(o, e) || !o.trigger(quote) {
+		return
+	}
+	s.setStatus(o, ibkr.OrderStatusSubmitted, e)
+
+	price, available, ok := executable(o, quote, book)
+	if !ok {
+		return
+	}
+	quantity := o.remaining()
+	if o.timeInForce() == ibkr.TimeInForceFOK && !available.covers(quantity) {
+		return
+	}
+	quantity = available.take(quantity)
+	if quantity.Sign() <= 0 {
+		return
+	}
+	if _, limited := o.limit(); !limited && s.slippage != nil {
+		price = s.slippage(o.param, quantity, price)
+	}
+	s.fill(o, quantity, price, e)
+}
+
+// released :
+// Reports whether o may execute: a child order waits for its parent to fill
+// and is cancelled with it.
+func (s *SimulatedOrders) released(o *order, e *events) bool {
+	if o.param.ParentId == "" {
+		return true
+	}
+	parent := s.findByCustomOrderId(o.param.AccountId, o.param.ParentId)
+	if parent == nil {
+		return true
+	}
+	if parent.status == ibkr.OrderStatusCancelled {
+		s.setStatus(o, ibkr.OrderStatusCancelled, e)
+		return false
+	}
+	return parent.status == ibkr.OrderStatusFilled
+}
+
+// trigger :
+// Tracks the stop of o and reports whether it may execute.
+func (o *order) trigger(quote ibkr.WebsocketPublicMarketDataResponse) bool {
+	if !o.stopped() {
+		return true
+	}
+	reference, ok := parsePrice(quote.LastPrice)
+	if !ok && o.buy() {
+		reference, ok = parsePrice(quote.AskPrice)
+	} else if !ok {
+		reference, ok = parsePrice(quote.BidPrice)
+	}
+	if !ok {
+		return false
+	}
+	if o.param.OrderType == ibkr.OrderTypeTrailing {
+		if o.extreme.IsZero() || (o.buy() && reference.LessThan(o.extreme)) || (!o.buy() && reference.GreaterThan(o.extreme)) {
+			o.extreme = reference
+		}
+	}
+	stop := o.stopPrice()
+	if (o.buy() && reference.LessThan(stop)) || (!o.buy() && reference.GreaterThan(stop)) {
+		return false
+	}
+	o.triggered = true
+	return true
+}
+
+// executable :
+// Returns the price o executes at and the size available to it. Marketable
+// orders take the opposite quote; a resting limit also fills at its limit
+// when the market trades through it without quoting it.
+func executable(o *order, quote ibkr.WebsocketPublicMarketDataResponse, book *liquidity) (ibkr.Decimal, *size, bool) {
+	limit, limited := o.limit()
+	available := book.ask
+	side, hasSide := parsePrice(quote.AskPrice)
+	if !o.buy() {
+		side, hasSide = parsePrice(quote.BidPrice)
+		available = book.bid
+	}
+	last, hasLast := parsePrice(quote.LastPrice)
+
+	switch {
+	case hasSide && (!limited || (o.buy() && side.Cmp(limit) <= 0) || (!o.buy() && side.Cmp(limit) >= 0)):
+		return side, available, true
+	case limited && book.trade && hasLast && ((o.buy() && last.Cmp(limit) <= 0) || (!o.buy() && last.Cmp(limit) >= 0)):
+		return limit, book.last, true
+	case !limited && !hasSide && hasLast:
+		return last, book.last, true
+	}
+	return ibkr.Decimal{}, nil, false
+}
+
+// fill :
+// Executes quantity of o at price.
+func (s *SimulatedOrders) fill(o *order, quantity, price ibkr.Decimal, e *events) {
+	now := s.currentTime()
+	o.filled = o.filled.Add(quantity)
+	o.value = o.value.Add(quantity.Mul(price))
+	o.executed = now
+
+	var commission ibkr.Decimal
+	if s.commission != nil {
+		commission = s.commission(o.param, quantity, price)
+	}
+	side, verb := "B", "Bought"
+	if !o.buy() {
+		side, verb = "S", "Sold"
+	}
+	trade := ibkr.TradeItem{
+		ExecutionId:        fmt.Sprintf("sim.%d", s.nextExecutionId),
+		Symbol:             o.param.Ticker,
+		Side:               side,
+		OrderDescription:   fmt.Sprintf("%s %s @ %s", verb, quantity, price),
+		OrderRef:           o.param.CustomOrderId,
+		TradeTime:          now.UTC().Format("20060102-15:04:05"),
+		TradeTimeR:         now.UnixMilli(),
+		Size:               quantity,
+		Price:              price,
+		Submitter:          "ibkrsim",
+		Exchange:           "SIM",
+		Commission:         commission,
+		NetAmount:          quantity.Mul(price),
+		Account:            o.param.AccountId,
+		AccountCode:        o.param.AccountId,
+		SecType:            o.param.ContractSecurityType,
+		ListingExchange:    o.param.ListingExchange,
+		ContractId:         *o.param.ContractId,
+		ContractIdExchange: strconv.Itoa(*o.param.ContractId),
+	}
+	s.nextExecutionId++
+	s.trades = append(s.trades, trade)
+	e.trades = append(e.trades, trade)
+
+	if o.remaining().Sign() <= 0 {
+		s.setStatus(o, ibkr.OrderStatusFilled, e)
+	} else {
+		e.orders = append(e.orders, orderEvent(o))
+	}
+	s.cancelLinked(o, e)
+}
+
+// cancelLinked :
+// Cancels the orders an execution of o makes obsolete: the rest of its OCA
+// group on any execution, and the other children of its parent, like the
+// stop loss of a bracket, once o is filled.
+func (s *SimulatedOrders) cancelLinked(o *order, e *events) {
+	filled := o.status == ibkr.OrderStatusFilled
+	for _, other := range s.orders {
+		if other == o || !other.active() {
+			continue
+		}
+		oca := o.group != 0 && other.group == o.group
+		sibling := filled && o.param.ParentId != "" && other.param.ParentId == o.param.ParentId && other.param.AccountId == o.param.AccountId
+		if oca || sibling {
+			s.setStatus(other, ibkr.OrderStatusCancelled, e)
+		}
+	}
+}
+
+// setStatus :
+func (s *SimulatedOrders) setStatus(o *order, status ibkr.OrderStatus, e *events) {
+	if o.status == status {
+		return
+	}
+	o.status = status
+	e.orders = append(e.orders, orderEvent(o))
+}
+
+func (s *SimulatedOrders) findByCustomOrderId(accountId, customOrderId string) *order {
+	for _, o := range s.orders {
+		if o.param.AccountId == accountId && o.param.CustomOrderId == customOrderId {
+			return o
+		}
+	}
+	return nil
+}
+
+func (s *SimulatedOrders) find(orderId int64) *order {
+	for _, o := range s.orders {
+		if o.id == orderId {
+			return o
+		}
+	}
+	return nil
+}
+
+func orderEvent(o *order) ibkr.WebsocketPrivateOrder {
+	event := ibkr.WebsocketPrivateOrder{
+		AccountId:         o.param.AccountId,
+		ContractId:        *o.param.ContractId,
+		OrderId:           o.id,
+		SizeAndFills:      sizeAndFills(o),
+		Ticker:            o.param.Ticker,
+		SecurityType:      o.param.ContractSecurityType,
+		ListingExchange:   o.param.ListingExchange,
+		RemainingQuantity: o.remaining(),
+		FilledQuantity:    o.filled,
+		Status:            string(o.status),
+		OrigOrderType:     string(o.param.OrderType),
+		OrderType:         string(o.param.OrderType),
+		OrderRef:          o.param.CustomOrderId,
+		TimeInForce:       string(o.timeInForce()),
+		Side:              strings.ToUpper(o.param.Side),
+	}
+	if o.param.Price != nil {
+		event.Price = *o.param.Price
+	}
+	if !o.executed.IsZero() {
+		event.LastExecutionTime = o.executed.UTC().Format("060102150405")
+		event.LastExecutionTimeR = o.executed.UnixMilli()
+	}
+	return event
+}
+
+func tradeData(trade ibkr.TradeItem) ibkr.WebsocketPrivateTradesData {
+	return ibkr.WebsocketPrivateTradesData{
+		ExecutionId:        trade.ExecutionId,
+		Symbol:             trade.Symbol,
+		Side:               trade.Side,
+		OrderDescription:   trade.OrderDescription,
+		TradeTime:          trade.TradeTime,
+		TradeTimeR:         trade.TradeTimeR,
+		Size:               trade.Size,
+		OrderRef:           trade.OrderRef,
+		Price:              trade.Price,
+		Exchange:           trade.Exchange,
+		NetAmount:          trade.NetAmount,
+		Account:            trade.Account,
+		AccountCode:        trade.AccountCode,
+		SecType:            trade.SecType,
+		ContractId:         trade.ContractId,
+		ContractIdExchange: trade.ContractIdExchange,
+	}
+}
+
+func sizeAndFills(o *order) string {
+	return o.filled.String() + "/" + o.param.Quantity.String()
+}
+
+// parsePrice :
+// Parses an smd price, which is prefixed with C for a prior close and H for
+// a halted contract.
+func parsePrice(value string) (ibkr.Decimal, bool) {
+	value = strings.TrimLeft(value, "CH")
+	if value == "" {
+		return ibkr.Decimal{}, false
+	}
+	price, err := ibkr.ParseDecimal(value)
+	if err != nil {
+		return ibkr.Decimal{}, false
+	}
+	return price, true
+}
+
+// parseSize :
+// Parses an smd size; a missing or zero size is treated as unlimited.
+func parseSize(value string) *size {
+	parsed, ok := parsePrice(value)
+	if !ok || parsed.Sign() <= 0 {
+		return &size{unlimited: true}
+	}
+	return &size{value: parsed}
+}
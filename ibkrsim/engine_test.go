@@ -0,0 +1,389 @@
+package ibkrsim_test
+
+import (
+	"testing"
+	"time"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+	"github.com/dictxwang/go-ibkr/ibkrsim"
+)
+
+const conid = 265598
+
+var (
+	d        = ibkr.MustParseDecimal
+	newYork  = mustLoadLocation("America/New_York")
+	openTime = time.Date(2026, 10, 19, 10, 0, 0, 0, newYork)
+)
+
+func mustLoadLocation(name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// quote returns a tick with a bid and an ask of unlimited size.
+func quote(bid, ask string) ibkr.WebsocketPublicMarketDataResponse {
+	return ibkr.WebsocketPublicMarketDataResponse{BidPrice: bid, AskPrice: ask}
+}
+
+// trade returns a tick with a last trade and the bid and ask around it.
+func trade(last, bid, ask string) ibkr.WebsocketPublicMarketDataResponse {
+	return ibkr.WebsocketPublicMarketDataResponse{LastPrice: last, BidPrice: bid, AskPrice: ask}
+}
+
+func withAskSize(tick ibkr.WebsocketPublicMarketDataResponse, size string) ibkr.WebsocketPublicMarketDataResponse {
+	tick.AskSize = size
+	return tick
+}
+
+func at(tick ibkr.WebsocketPublicMarketDataResponse, t time.Time) ibkr.WebsocketPublicMarketDataResponse {
+	tick.UpdateTime = t.UnixMilli()
+	return tick
+}
+
+func build(b *ibkr.OrderBuilder) ibkr.PlaceOrderParam {
+	param, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return param
+}
+
+func buy(quantity string) *ibkr.OrderBuilder {
+	return ibkr.NewOrder("DU123", conid).Buy(d(quantity))
+}
+
+func sell(quantity string) *ibkr.OrderBuilder {
+	return ibkr.NewOrder("DU123", conid).Sell(d(quantity))
+}
+
+// result is the state of one simulated order: its status, filled quantity
+// and average price.
+type result struct {
+	status  ibkr.OrderStatus
+	filled  string
+	average string
+}
+
+func TestSimulatedOrdersMatching(t *testing.T) {
+	tests := []struct {
+		name string
+		// quote is fed before the orders are placed
+		quote  ibkr.WebsocketPublicMarketDataResponse
+		orders func() []ibkr.PlaceOrderParam
+		// before runs after the orders are placed, before the ticks
+		before func(*ibkrsim.SimulatedOrders)
+		ticks  []ibkr.WebsocketPublicMarketDataResponse
+		want   []result
+	}{
+		{
+			name:   "market buys at the ask",
+			quote:  quote("99.9", "100.1"),
+			orders: func() []ibkr.PlaceOrderParam { return []ibkr.PlaceOrderParam{build(buy("100").Market())} },
+			want:   []result{{ibkr.OrderStatusFilled, "100", "100.1"}},
+		},
+		{
+			name:   "limit rests until the ask reaches it",
+			quote:  quote("99.9", "100.1"),
+			orders: func() []ibkr.PlaceOrderParam { return []ibkr.PlaceOrderParam{build(buy("100").Limit(d("100")))} },
+			ticks:  []ibkr.WebsocketPublicMarketDataResponse{quote("99.8", "100.05"), quote("99.8", "99.95")},
+			want:   []result{{ibkr.OrderStatusFilled, "100", "99.95"}},
+		},
+		{
+			name:   "limit resting below the ask",
+			quote:  quote("99.9", "100.1"),
+			orders: func() []ibkr.PlaceOrderParam { return []ibkr.PlaceOrderParam{build(buy("100").Limit(d("100")))} },
+			want:   []result{{ibkr.OrderStatusSubmitted, "0", "0"}},
+		},
+		{
+			name:   "limit fills at its limit when the market trades through",
+			quote:  quote("99.9", "100.1"),
+			orders: func() []ibkr.PlaceOrderParam { return []ibkr.PlaceOrderParam{build(buy("100").Limit(d("100")))} },
+			ticks:  []ibkr.WebsocketPublicMarketDataResponse{{LastPrice: "99.95"}},
+			want:   []result{{ibkr.OrderStatusFilled, "100", "100"}},
+		},
+		{
+			name:   "partial fills up to the displayed size",
+			quote:  withAskSize(quote("99.9", "100"), "30"),
+			orders: func() []ibkr.PlaceOrderParam { return []ibkr.PlaceOrderParam{build(buy("100").Limit(d("100")))} },
+			ticks:  []ibkr.WebsocketPublicMarketDataResponse{withAskSize(quote("99.9", "100"), "50")},
+			want:   []result{{ibkr.OrderStatusSubmitted, "80", "100"}},
+		},
+		{
+			name:   "partial fills complete at a better price",
+			quote:  withAskSize(quote("99.9", "100"), "30"),
+			orders: func() []ibkr.PlaceOrderParam { return []ibkr.PlaceOrderParam{build(buy("100").Limit(d("100")))} },
+			ticks: []ibkr.WebsocketPublicMarketDataResponse{
+				withAskSize(quote("99.9", "100"), "50"),
+				withAskSize(quote("99.8", "99.9"), "100"),
+			},
+			want: []result{{ibkr.OrderStatusFilled, "100", "99.98"}},
+		},
+		{
+			name:   "IOC cancels what it cannot fill at once",
+			quote:  withAskSize(quote("99.9", "100"), "30"),
+			orders: func() []ibkr.PlaceOrderParam { return []ibkr.PlaceOrderParam{build(buy("100").Limit(d("100")).IOC())} },
+			ticks:  []ibkr.WebsocketPublicMarketDataResponse{withAskSize(quote("99.9", "100"), "100")},
+			want:   []result{{ibkr.OrderStatusCancelled, "30", "100"}},
+		},
+		{
+			name:   "FOK without the whole size",
+			quote:  withAskSize(quote("99.9", "100"), "30"),
+			orders: func() []ibkr.PlaceOrderParam { return []ibkr.PlaceOrderParam{build(buy("100").Limit(d("100")).FOK())} },
+			want:   []result{{ibkr.OrderStatusCancelled, "0", "0"}},
+		},
+		{
+			name:   "FOK with the whole size",
+			quote:  withAskSize(quote("99.9", "100"), "100"),
+			orders: func() []ibkr.PlaceOrderParam { return []ibkr.PlaceOrderParam{build(buy("100").Limit(d("100")).FOK())} },
+			want:   []result{{ibkr.OrderStatusFilled, "100", "100"}},
+		},
+		{
+			name:  "DAY expires overnight, GTC rests",
+			quote: quote("99.9", "100.1"),
+			orders: func() []ibkr.PlaceOrderParam {
+				return []ibkr.PlaceOrderParam{build(buy("100").Limit(d("90"))), build(buy("100").Limit(d("90")).GTC())}
+			},
+			ticks: []ibkr.WebsocketPublicMarketDataResponse{at(quote("99.9", "100.1"), openTime.AddDate(0, 0, 1))},
+			want:  []result{{ibkr.OrderStatusCancelled, "0", "0"}, {ibkr.OrderStatusSubmitted, "0", "0"}},
+		},
+		{
+			name:   "stop waits for the last price",
+			quote:  trade("100", "99.9", "100.1"),
+			orders: func() []ibkr.PlaceOrderParam { return []ibkr.PlaceOrderParam{build(sell("100").Stop(d("99")))} },
+			ticks:  []ibkr.WebsocketPublicMarketDataResponse{trade("99.5", "99.4", "99.6")},
+			want:   []result{{ibkr.OrderStatusPreSubmitted, "0", "0"}},
+		},
+		{
+			name:   "stop sells at the bid once triggered",
+			quote:  trade("100", "99.9", "100.1"),
+			orders: func() []ibkr.PlaceOrderParam { return []ibkr.PlaceOrderParam{build(sell("100").Stop(d("99")))} },
+			ticks:  []ibkr.WebsocketPublicMarketDataResponse{trade("99.5", "99.4", "99.6"), trade("99", "98.9", "99.1")},
+			want:   []result{{ibkr.OrderStatusFilled, "100", "98.9"}},
+		},
+		{
+			name:  "stop limit triggered below its limit",
+			quote: trade("100", "99.9", "100.1"),
+			orders: func() []ibkr.PlaceOrderParam {
+				return []ibkr.PlaceOrderParam{build(sell("100").StopLimit(d("99"), d("98.95")))}
+			},
+			ticks: []ibkr.WebsocketPublicMarketDataResponse{trade("98.9", "98.8", "99")},
+			want:  []result{{ibkr.OrderStatusSubmitted, "0", "0"}},
+		},
+		{
+			name:  "stop limit fills once the bid reaches its limit",
+			quote: trade("100", "99.9", "100.1"),
+			orders: func() []ibkr.PlaceOrderParam {
+				return []ibkr.PlaceOrderParam{build(sell("100").StopLimit(d("99"), d("98.95")))}
+			},
+			ticks: []ibkr.WebsocketPublicMarketDataResponse{trade("98.9", "98.8", "99"), quote("98.95", "99.05")},
+			want:  []result{{ibkr.OrderStatusFilled, "100", "98.95"}},
+		},
+		{
+			name:  "trailing stop follows the high",
+			quote: trade("100", "99.9", "100.1"),
+			orders: func() []ibkr.PlaceOrderParam {
+				return []ibkr.PlaceOrderParam{build(sell("100").TrailingStop(d("1"), ibkr.TrailingTypeAmount))}
+			},
+			ticks: []ibkr.WebsocketPublicMarketDataResponse{
+				trade("99.5", "99.4", "99.6"),
+				trade("105", "104.9", "105.1"),
+				trade("104.5", "104.4", "104.6"),
+				trade("104", "103.9", "104.1"),
+			},
+			want: []result{{ibkr.OrderStatusFilled, "100", "103.9"}},
+		},
+		{
+			name:  "trailing stop in percent",
+			quote: trade("100", "99.9", "100.1"),
+			orders: func() []ibkr.PlaceOrderParam {
+				return []ibkr.PlaceOrderParam{build(sell("100").TrailingStop(d("2"), ibkr.TrailingTypePercent))}
+			},
+			ticks: []ibkr.WebsocketPublicMarketDataResponse{
+				trade("110", "109.9", "110.1"),
+				trade("107.9", "107.8", "108"),
+				trade("107.8", "107.7", "107.9"),
+			},
+			want: []result{{ibkr.OrderStatusFilled, "100", "107.7"}},
+		},
+		{
+			name:  "OCA execution cancels the rest of the group",
+			quote: trade("100", "99.9", "100.1"),
+			orders: func() []ibkr.PlaceOrderParam {
+				group, err := ibkr.NewOCAGroup(build(sell("100").Limit(d("105"))), build(sell("100").Stop(d("95"))))
+				if err != nil {
+					panic(err)
+				}
+				return group.Orders
+			},
+			ticks: []ibkr.WebsocketPublicMarketDataResponse{trade("105", "105", "105.2")},
+			want:  []result{{ibkr.OrderStatusFilled, "100", "105"}, {ibkr.OrderStatusCancelled, "0", "0"}},
+		},
+		{
+			name:  "OCA partial execution cancels the rest of the group",
+			quote: quote("99.9", "100.1"),
+			orders: func() []ibkr.PlaceOrderParam {
+				group, err := ibkr.NewOCAGroup(build(buy("100").Limit(d("100"))), build(buy("100").Limit(d("99"))))
+				if err != nil {
+					panic(err)
+				}
+				return group.Orders
+			},
+			ticks: []ibkr.WebsocketPublicMarketDataResponse{withAskSize(quote("99.9", "100"), "40")},
+			want:  []result{{ibkr.OrderStatusSubmitted, "40", "100"}, {ibkr.OrderStatusCancelled, "0", "0"}},
+		},
+		{
+			name:   "bracket children wait for the entry",
+			quote:  quote("99.9", "100.1"),
+			orders: bracket,
+			want:   []result{{ibkr.OrderStatusSubmitted, "0", "0"}, {ibkr.OrderStatusPreSubmitted, "0", "0"}, {ibkr.OrderStatusPreSubmitted, "0", "0"}},
+		},
+		{
+			name:   "bracket take profit cancels the stop loss",
+			quote:  quote("99.9", "100.1"),
+			orders: bracket,
+			ticks:  []ibkr.WebsocketPublicMarketDataResponse{quote("99.9", "100"), quote("105", "105.2")},
+			want:   []result{{ibkr.OrderStatusFilled, "100", "100"}, {ibkr.OrderStatusFilled, "100", "105"}, {ibkr.OrderStatusCancelled, "0", "0"}},
+		},
+		{
+			name:   "bracket stop loss cancels the take profit",
+			quote:  quote("99.9", "100.1"),
+			orders: bracket,
+			ticks:  []ibkr.WebsocketPublicMarketDataResponse{quote("99.9", "100"), trade("95", "94.9", "95.1")},
+			want:   []result{{ibkr.OrderStatusFilled, "100", "100"}, {ibkr.OrderStatusCancelled, "0", "0"}, {ibkr.OrderStatusFilled, "100", "94.9"}},
+		},
+		{
+			name:   "cancelling the bracket entry cancels its children",
+			quote:  quote("99.9", "100.1"),
+			orders: bracket,
+			before: func(sim *ibkrsim.SimulatedOrders) {
+				if _, err := sim.CancelOrder(ibkr.CancelOrderParam{AccountId: "DU123", OrderId: 1}); err != nil {
+					panic(err)
+				}
+			},
+			ticks: []ibkr.WebsocketPublicMarketDataResponse{quote("99.9", "100")},
+			want:  []result{{ibkr.OrderStatusCancelled, "0", "0"}, {ibkr.OrderStatusCancelled, "0", "0"}, {ibkr.OrderStatusCancelled, "0", "0"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := ibkrsim.NewSimulatedOrders().WithClock(func() time.Time { return openTime }).WithLocation(newYork)
+			feed := func(tick ibkr.WebsocketPublicMarketDataResponse) {
+				tick.ContractId = conid
+				if tick.UpdateTime == 0 {
+					tick.UpdateTime = openTime.UnixMilli()
+				}
+				if err := sim.OnMarketData(tick); err != nil {
+					t.Fatal(err)
+				}
+			}
+			feed(tt.quote)
+			resp, err := sim.PlaceOrder(tt.orders())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.RejectResult != nil {
+				t.Fatalf("rejected: %s", resp.RejectResult.Error)
+			}
+			if tt.before != nil {
+				tt.before(sim)
+			}
+			for _, tick := range tt.ticks {
+				feed(tick)
+			}
+
+			live, err := sim.GetLiveOrders(ibkr.GetLiveOrdersParam{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(live.Orders) != len(tt.want) {
+				t.Fatalf("%d orders, want %d", len(live.Orders), len(tt.want))
+			}
+			for n, order := range live.Orders {
+				got := result{ibkr.OrderStatus(order.Status), order.FilledQuantity.String(), order.AveragePrice.String()}
+				if got != tt.want[n] {
+					t.Errorf("order %d: got %+v, want %+v", n+1, got, tt.want[n])
+				}
+			}
+		})
+	}
+}
+
+func bracket() []ibkr.PlaceOrderParam {
+	group, err := ibkr.NewBracket(
+		build(buy("100").Limit(d("100"))),
+		build(sell("100").Limit(d("105"))),
+		build(sell("100").Stop(d("95"))),
+	)
+	if err != nil {
+		panic(err)
+	}
+	return group.Orders
+}
+
+func TestSimulatedOrdersRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		param ibkr.PlaceOrderParam
+	}{
+		{"unsupported order type", build(buy("100").MarketIfTouched(d("95")))},
+		{"unsupported tif", build(buy("100").Market().OPG())},
+		{"limit without price", ibkr.PlaceOrderParam{AccountId: "DU123", ContractId: new(int), Side: "BUY", Quantity: d("1"), OrderType: ibkr.OrderTypeLimit}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := ibkrsim.NewSimulatedOrders().PlaceOrder([]ibkr.PlaceOrderParam{tt.param})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.RejectResult == nil || resp.RejectResult.Error == "" {
+				t.Errorf("got %+v, want a reject", resp)
+			}
+		})
+	}
+}
+
+func TestSimulatedOrdersEvents(t *testing.T) {
+	sim := ibkrsim.NewSimulatedOrders().
+		WithClock(func() time.Time { return openTime }).
+		WithCommission(func(ibkr.PlaceOrderParam, ibkr.Decimal, ibkr.Decimal) ibkr.Decimal { return d("1.25") }).
+		WithSlippage(func(_ ibkr.PlaceOrderParam, _, price ibkr.Decimal) ibkr.Decimal { return price.Add(d("0.01")) })
+
+	var statuses []string
+	var executions []ibkr.TradeItem
+	sim.SubscribeOrder(func(resp ibkr.WebsocketPrivateOrderResponse) error {
+		for _, order := range resp.Orders {
+			statuses = append(statuses, order.Status)
+		}
+		return nil
+	})
+	sim.SubscribeExecutions(func(trade ibkr.TradeItem) error {
+		executions = append(executions, trade)
+		return nil
+	})
+
+	if err := sim.OnMarketData(ibkr.WebsocketPublicMarketDataResponse{Topic: "smd+265598", BidPrice: "99.9", AskPrice: "100.1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sim.PlaceOrder([]ibkr.PlaceOrderParam{build(buy("10").Market())}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{string(ibkr.OrderStatusPreSubmitted), string(ibkr.OrderStatusSubmitted), string(ibkr.OrderStatusFilled)}
+	if len(statuses) != len(want) || statuses[0] != want[0] || statuses[1] != want[1] || statuses[2] != want[2] {
+		t.Errorf("statuses %v, want %v", statuses, want)
+	}
+	if len(executions) != 1 || executions[0].Price.String() != "100.11" || executions[0].Commission.String() != "1.25" || executions[0].Side != "B" {
+		t.Fatalf("executions %+v", executions)
+	}
+	trades, err := sim.GetTrades(ibkr.GetTradesParam{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*trades) != 1 || (*trades)[0].ExecutionId != executions[0].ExecutionId {
+		t.Errorf("trades %+v", *trades)
+	}
+}
@@ -0,0 +1,111 @@
+package ibkrsim
+
+import (
+	"fmt"
+	"time"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+)
+
+var statusFilters = map[ibkr.OrderStatusFilterValue]ibkr.OrderStatus{
+	ibkr.OrderStatusFilterValueInactive:      ibkr.OrderStatusInactive,
+	ibkr.OrderStatusFilterValuePendingSubmit: ibkr.OrderStatusPendingSubmit,
+	ibkr.OrderStatusFilterValuePreSubmitted:  ibkr.OrderStatusPreSubmitted,
+	ibkr.OrderStatusFilterValueSubmitted:     ibkr.OrderStatusSubmitted,
+	ibkr.OrderStatusFilterValueFilled:        ibkr.OrderStatusFilled,
+	ibkr.OrderStatusFilterValuePendingCancel: ibkr.OrderStatusPendingCancel,
+	ibkr.OrderStatusFilterValueCancelled:     ibkr.OrderStatusCancelled,
+	ibkr.OrderStatusFilterValueWarnState:     ibkr.OrderStatusWarnState,
+}
+
+// GetLiveOrders :
+// Returns all simulated orders, optionally filtered by status.
+func (s *SimulatedOrders) GetLiveOrders(param ibkr.GetLiveOrdersParam) (*ibkr.GetLiveOrdersResponse, error) {
+	statuses := map[ibkr.OrderStatus]bool{}
+	for _, filter := range param.StatusValueFilters {
+		statuses[statusFilters[filter]] = true
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	resp := ibkr.GetLiveOrdersResponse{Orders: []ibkr.LiveOrderItem{}, Snapshot: true}
+	for _, o := range s.orders {
+		if len(statuses) > 0 && !statuses[o.status] {
+			continue
+		}
+		event := orderEvent(o)
+		resp.Orders = append(resp.Orders, ibkr.LiveOrderItem{
+			AccountId:          event.AccountId,
+			ContractId:         event.ContractId,
+			OrderId:            event.OrderId,
+			SizeAndFills:       event.SizeAndFills,
+			Ticker:             event.Ticker,
+			SecurityType:       event.SecurityType,
+			ListingExchange:    event.ListingExchange,
+			RemainingQuantity:  event.RemainingQuantity,
+			FilledQuantity:     event.FilledQuantity,
+			Status:             event.Status,
+			OrigOrderType:      event.OrigOrderType,
+			LastExecutionTime:  event.LastExecutionTime,
+			LastExecutionTimeR: event.LastExecutionTimeR,
+			OrderType:          event.OrderType,
+			OrderRef:           event.OrderRef,
+			TimeInForce:        event.TimeInForce,
+			Side:               event.Side,
+			AveragePrice:       o.averagePrice(),
+		})
+	}
+	return &resp, nil
+}
+
+// GetTrades :
+// Returns the simulated executions of the current day, or of the last Days
+// days.
+func (s *SimulatedOrders) GetTrades(param ibkr.GetTradesParam) (*[]ibkr.TradeItem, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.currentTime().In(s.location)
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, s.location)
+	if param.Days != nil {
+		from = from.AddDate(0, 0, 1-*param.Days)
+	}
+
+	trades := []ibkr.TradeItem{}
+	for _, trade := range s.trades {
+		if !time.UnixMilli(trade.TradeTimeR).Before(from) {
+			trades = append(trades, trade)
+		}
+	}
+	return &trades, nil
+}
+
+// GetStatus :
+func (s *SimulatedOrders) GetStatus(orderId int) (*ibkr.OrderStatusItem, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	o := s.find(int64(orderId))
+	if o == nil {
+		return nil, fmt.Errorf("ibkrsim: order %d not found", orderId)
+	}
+	return &ibkr.OrderStatusItem{
+		OrderId:         o.id,
+		ContractId:      *o.param.ContractId,
+		Symbol:          o.param.Ticker,
+		Side:            o.param.Side,
+		ListingExchange: o.param.ListingExchange,
+		Size:            o.remaining(),
+		TotalSize:       o.param.Quantity,
+		AccountId:       o.param.AccountId,
+		OrderType:       string(o.param.OrderType),
+		CumulativeFill:  o.filled,
+		OrderStatus:     string(o.status),
+		TimeInFore:      string(o.timeInForce()),
+		SecType:         o.param.ContractSecurityType,
+		SizeAndFills:    sizeAndFills(o),
+		AveragePrice:    o.averagePrice(),
+		OrderTime:       o.placed.UTC().Format("060102150405"),
+	}, nil
+}
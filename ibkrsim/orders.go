@@ -0,0 +1,177 @@
+package ibkrsim
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+)
+
+// PlaceOrder :
+// Accepts orders the simulator supports and executes them against the latest
+// quote, in order once all of them are placed. Orders with IsSingleGroup
+// form one OCA group. The simulator raises no prompts, so the response never
+// carries AlternateResults.
+func (s *SimulatedOrders) PlaceOrder(orders []ibkr.PlaceOrderParam) (*ibkr.PlaceOrderResponse, error) {
+	if len(orders) == 0 {
+		return nil, errors.New("require order params")
+	}
+	for _, param := range orders {
+		if reason := validate(param); reason != "" {
+			return &ibkr.PlaceOrderResponse{RejectResult: &ibkr.PlaceOrderRejectResult{Error: reason}}, nil
+		}
+	}
+
+	var e events
+	placed := make([]*order, 0, len(orders))
+	s.mutex.Lock()
+	group := s.nextOrderId
+	for _, param := range orders {
+		o := &order{id: s.nextOrderId, param: param, placed: s.currentTime()}
+		if param.IsSingleGroup {
+			o.group = group
+		}
+		s.nextOrderId++
+		s.orders = append(s.orders, o)
+		placed = append(placed, o)
+		s.setStatus(o, ibkr.OrderStatusPreSubmitted, &e)
+	}
+
+	results := make([]ibkr.PlaceOrderNormalResult, 0, len(placed))
+	for _, o := range placed {
+		if quote, ok := s.quotes[*o.param.ContractId]; ok && o.active() {
+			s.match(o, quote, newLiquidity(quote, ibkr.WebsocketPublicMarketDataResponse{}), &e)
+		}
+		if tif := o.timeInForce(); (tif == ibkr.TimeInForceIOC || tif == ibkr.TimeInForceFOK) && o.active() {
+			s.setStatus(o, ibkr.OrderStatusCancelled, &e)
+		}
+		results = append(results, ibkr.PlaceOrderNormalResult{
+			OrderId:      strconv.FormatInt(o.id, 10),
+			LocalOrderId: o.param.CustomOrderId,
+			OrderStatus:  o.status,
+		})
+	}
+	s.mutex.Unlock()
+
+	s.publish(e)
+	return &ibkr.PlaceOrderResponse{NormalResults: &results}, nil
+}
+
+// PreviewOrder :
+// Estimates the amount and commission from the latest quote, and the position
+// change from the simulated executions.
+func (s *SimulatedOrders) PreviewOrder(orders []ibkr.PlaceOrderParam) (*ibkr.PreviewOrderResponse, error) {
+	if len(orders) == 0 {
+		return nil, errors.New("require order params")
+	}
+	var resp ibkr.PreviewOrderResponse
+	param := orders[0]
+	if reason := validate(param); reason != "" {
+		resp.Error = &reason
+		return &resp, nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	o := &order{param: param}
+	var price ibkr.Decimal
+	var ok bool
+	if quote, found := s.quotes[*param.ContractId]; found {
+		if price, ok = o.limit(); !ok {
+			field := quote.AskPrice
+			if !o.buy() {
+				field = quote.BidPrice
+			}
+			if price, ok = parsePrice(field); !ok {
+				price, ok = parsePrice(quote.LastPrice)
+			}
+		}
+	}
+	if !ok {
+		warn := "no market data for the contract"
+		resp.Warn = &warn
+	}
+
+	var commission ibkr.Decimal
+	if s.commission != nil {
+		commission = s.commission(param, param.Quantity, price)
+	}
+	amount := param.Quantity.Mul(price)
+	resp.Amount = ibkr.PreviewOrderAmount{
+		Amount:     amount.String(),
+		Commission: commission.String(),
+		Total:      amount.Add(commission).String(),
+	}
+
+	current := s.position(param.AccountId, *param.ContractId)
+	change := param.Quantity
+	if !o.buy() {
+		change = change.Neg()
+	}
+	resp.Position = ibkr.PreviewOrderChange{
+		Current: current.String(),
+		Change:  change.String(),
+		After:   current.Add(change).String(),
+	}
+	return &resp, nil
+}
+
+// position :
+// Returns the net quantity executed for accountId in contractId.
+func (s *SimulatedOrders) position(accountId string, contractId int) ibkr.Decimal {
+	var position ibkr.Decimal
+	for _, trade := range s.trades {
+		if trade.Account != accountId || trade.ContractId != contractId {
+			continue
+		}
+		if trade.Side == "B" {
+			position = position.Add(trade.Size)
+		} else {
+			position = position.Sub(trade.Size)
+		}
+	}
+	return position
+}
+
+// CancelOrder :
+func (s *SimulatedOrders) CancelOrder(param ibkr.CancelOrderParam) (*ibkr.CancelOrderResponse, error) {
+	var e events
+	s.mutex.Lock()
+	o := s.find(param.OrderId)
+	if o == nil || (param.AccountId != "" && o.param.AccountId != param.AccountId) {
+		s.mutex.Unlock()
+		return &ibkr.CancelOrderResponse{Error: fmt.Sprintf("OrderID %d doesn't exist", param.OrderId)}, nil
+	}
+	if !o.active() {
+		s.mutex.Unlock()
+		return &ibkr.CancelOrderResponse{Error: fmt.Sprintf("OrderID %d is %s", param.OrderId, o.status)}, nil
+	}
+	s.setStatus(o, ibkr.OrderStatusCancelled, &e)
+	s.mutex.Unlock()
+
+	s.publish(e)
+	return &ibkr.CancelOrderResponse{
+		Msg:        "Request was submitted",
+		OrderId:    o.id,
+		ContractId: *o.param.ContractId,
+		AccountId:  o.param.AccountId,
+	}, nil
+}
+
+// PlaceOrderReplyConfirmation :
+// The simulator raises no prompts, so there is nothing to confirm.
+func (s *SimulatedOrders) PlaceOrderReplyConfirmation(param ibkr.PlaceOrderReplyConfirmationParam) (*ibkr.PlaceOrderReplyConfirmationResponse, error) {
+	return &ibkr.PlaceOrderReplyConfirmationResponse{Error: fmt.Sprintf("reply %s not found", param.ReplyId)}, nil
+}
+
+// RespondServerPrompt :
+func (s *SimulatedOrders) RespondServerPrompt(param ibkr.RespondServerPromptParam) (*ibkr.RespondServerPromptResponse, error) {
+	return &ibkr.RespondServerPromptResponse{Result: "Success"}, nil
+}
+
+// SuppressMessages :
+func (s *SimulatedOrders) SuppressMessages(messageIds []string) (*ibkr.SuppressMessagesResponse, error) {
+	return &ibkr.SuppressMessagesResponse{Status: "submitted"}, nil
+}
@@ -0,0 +1,168 @@
+// Package ibkrsim provides a paper-trading simulator that implements the
+// order services of the SDK locally, so strategies can run against live or
+// recorded market data without sending orders to IBKR.
+//
+//	sim := ibkrsim.NewSimulatedOrders()
+//	var orders ibkr.OrdersServiceI = sim // instead of client.Service().Order()
+//	public.SubscribeMarketData(param, sim.OnMarketData)
+//	sim.SubscribeOrder(func(resp ibkr.WebsocketPrivateOrderResponse) error { ... })
+package ibkrsim
+
+import (
+	"sync"
+	"time"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+)
+
+var (
+	_ ibkr.OrdersServiceI          = (*SimulatedOrders)(nil)
+	_ ibkr.OrderMonitoringServiceI = (*SimulatedOrders)(nil)
+)
+
+// CommissionFunc :
+// Returns the commission of one execution.
+type CommissionFunc func(param ibkr.PlaceOrderParam, quantity, price ibkr.Decimal) ibkr.Decimal
+
+// SlippageFunc :
+// Returns the price a market execution of quantity quoted at price gets.
+type SlippageFunc func(param ibkr.PlaceOrderParam, quantity, price ibkr.Decimal) ibkr.Decimal
+
+// SimulatedOrders :
+// Matches orders against market data fed through OnMarketData. MKT, LMT,
+// STP, STP LMT and TRAIL orders are supported with DAY, GTC, IOC and FOK,
+// as are the brackets and OCA groups of ibkr.OrderGroup. Fills are limited
+// by the displayed bid/ask size, or by the size of the last trade, so large
+// orders fill partially over several ticks.
+type SimulatedOrders struct {
+	mutex sync.Mutex
+
+	clock      func() time.Time
+	location   *time.Location
+	commission CommissionFunc
+	slippage   SlippageFunc
+
+	nextOrderId     int64
+	nextExecutionId int64
+	orders          []*order
+	trades          []ibkr.TradeItem
+	quotes          map[int]ibkr.WebsocketPublicMarketDataResponse
+	now             time.Time
+
+	orderHandlers     []func(ibkr.WebsocketPrivateOrderResponse) error
+	tradeHandlers     []func(ibkr.WebsocketPrivateTradesDataResponse) error
+	executionHandlers []func(ibkr.TradeItem) error
+}
+
+// NewSimulatedOrders :
+func NewSimulatedOrders() *SimulatedOrders {
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		location = time.UTC
+	}
+	return &SimulatedOrders{
+		clock:           time.Now,
+		location:        location,
+		nextOrderId:     1,
+		nextExecutionId: 1,
+		quotes:          map[int]ibkr.WebsocketPublicMarketDataResponse{},
+	}
+}
+
+// WithClock :
+// Sets the time of orders placed and ticks without an update time. Recorded
+// ticks carry their own time.
+func (s *SimulatedOrders) WithClock(clock func() time.Time) *SimulatedOrders {
+	s.clock = clock
+	return s
+}
+
+// WithLocation :
+// Sets the time zone whose midnight expires DAY orders, America/New_York by
+// default.
+func (s *SimulatedOrders) WithLocation(location *time.Location) *SimulatedOrders {
+	s.location = location
+	return s
+}
+
+// WithCommission :
+func (s *SimulatedOrders) WithCommission(commission CommissionFunc) *SimulatedOrders {
+	s.commission = commission
+	return s
+}
+
+// WithSlippage :
+// Sets the slippage of executions at market: MKT orders and triggered STP
+// and TRAIL orders. Limit prices are never slipped.
+func (s *SimulatedOrders) WithSlippage(slippage SlippageFunc) *SimulatedOrders {
+	s.slippage = slippage
+	return s
+}
+
+// SubscribeOrder :
+// Receives synthetic sor events on every order change. Handlers run on the
+// goroutine that placed, cancelled or filled the order.
+func (s *SimulatedOrders) SubscribeOrder(handler func(ibkr.WebsocketPrivateOrderResponse) error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.orderHandlers = append(s.orderHandlers, handler)
+}
+
+// SubscribeTradesData :
+// Receives synthetic str events on every execution.
+func (s *SimulatedOrders) SubscribeTradesData(handler func(ibkr.WebsocketPrivateTradesDataResponse) error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.tradeHandlers = append(s.tradeHandlers, handler)
+}
+
+// SubscribeExecutions :
+// Receives every execution like GetTrades returns it, with its commission.
+func (s *SimulatedOrders) SubscribeExecutions(handler func(ibkr.TradeItem) error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.executionHandlers = append(s.executionHandlers, handler)
+}
+
+// events :
+// Collects what changed while the mutex is held, to be published after.
+type events struct {
+	orders []ibkr.WebsocketPrivateOrder
+	trades []ibkr.TradeItem
+}
+
+func (s *SimulatedOrders) publish(e events) {
+	s.mutex.Lock()
+	orderHandlers, tradeHandlers, executionHandlers := s.orderHandlers, s.tradeHandlers, s.executionHandlers
+	s.mutex.Unlock()
+
+	if len(e.orders) > 0 {
+		resp := ibkr.WebsocketPrivateOrderResponse{Orders: e.orders}
+		for _, handler := range orderHandlers {
+			_ = handler(resp)
+		}
+	}
+	if len(e.trades) > 0 {
+		resp := ibkr.WebsocketPrivateTradesDataResponse{Topic: ibkr.MessageTopicSubscribeTradesData}
+		for _, trade := range e.trades {
+			resp.Args = append(resp.Args, tradeData(trade))
+		}
+		for _, handler := range tradeHandlers {
+			_ = handler(resp)
+		}
+	}
+	for _, trade := range e.trades {
+		for _, handler := range executionHandlers {
+			_ = handler(trade)
+		}
+	}
+}
+
+// currentTime :
+// Returns the time of the latest tick, or the clock before the first one.
+func (s *SimulatedOrders) currentTime() time.Time {
+	if s.now.IsZero() {
+		return s.clock()
+	}
+	return s.now
+}
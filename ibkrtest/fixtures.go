@@ -0,0 +1,341 @@
+package ibkrtest
+
+import (
+	"net/http"
+	"strconv"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+)
+
+// positionsPageSize is the page size of /portfolio/{accountId}/positions/{pageId}
+const positionsPageSize = 100
+
+// OrderReplyChain :
+// The answers to one PlaceOrder call: every prompt has to be confirmed through
+// /iserver/reply/{replyId} before Results, or Reject, is returned.
+type OrderReplyChain struct {
+	Prompts []ibkr.PlaceOrderAlternateResult
+	Results []ibkr.PlaceOrderNormalResult
+	Reject  string
+}
+
+type state struct {
+	authStatus    ibkr.AuthStatusInfo
+	accounts      []ibkr.PortfolioAccountInfo
+	positions     map[string][]ibkr.PositionInfo
+	ledgers       map[string]map[string]ibkr.AccountLedgerItem
+	profitAndLoss ibkr.AccountProfitAndLossResponse
+	liveOrders    []ibkr.LiveOrderItem
+	trades        []ibkr.TradeItem
+	orderStatus   map[int]ibkr.OrderStatusItem
+	contractRules map[int]ibkr.ContractRules
+	contractInfo  map[int]ibkr.GetContractInfoResponse
+	searches      map[string][]ibkr.SearchContractBySymbolItem
+	preview       ibkr.PreviewOrderResponse
+
+	// replyChains are consumed one per PlaceOrder call
+	replyChains []OrderReplyChain
+	// pending maps a reply id to the rest of its chain
+	pending     map[string]OrderReplyChain
+	nextOrderId int
+}
+
+func newState() state {
+	return state{
+		authStatus:    ibkr.AuthStatusInfo{Authenticated: true, Connected: true},
+		positions:     map[string][]ibkr.PositionInfo{},
+		ledgers:       map[string]map[string]ibkr.AccountLedgerItem{},
+		profitAndLoss: ibkr.AccountProfitAndLossResponse{UserPnL: map[string]ibkr.AccountProfitAndLossInfo{}},
+		orderStatus:   map[int]ibkr.OrderStatusItem{},
+		contractRules: map[int]ibkr.ContractRules{},
+		contractInfo:  map[int]ibkr.GetContractInfoResponse{},
+		searches:      map[string][]ibkr.SearchContractBySymbolItem{},
+		pending:       map[string]OrderReplyChain{},
+		nextOrderId:   1000,
+	}
+}
+
+// SetAuthStatus :
+// Authenticated and connected by default.
+func (s *Server) SetAuthStatus(status ibkr.AuthStatusInfo) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.state.authStatus = status
+}
+
+// SetAccounts :
+func (s *Server) SetAccounts(accounts ...ibkr.PortfolioAccountInfo) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.state.accounts = accounts
+}
+
+// SetPositions :
+// Positions are served in pages of 100 like the gateway does.
+func (s *Server) SetPositions(accountId string, positions []ibkr.PositionInfo) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.state.positions[accountId] = positions
+}
+
+// SetLedger :
+func (s *Server) SetLedger(accountId string, ledger map[string]ibkr.AccountLedgerItem) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.state.ledgers[accountId] = ledger
+}
+
+// SetProfitAndLoss :
+// Sets the partitioned PnL of accountId, served under "accountId.Core".
+func (s *Server) SetProfitAndLoss(accountId string, pnl ibkr.AccountProfitAndLossInfo) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.state.profitAndLoss.UserPnL[accountId+".Core"] = pnl
+}
+
+// SetLiveOrders :
+func (s *Server) SetLiveOrders(orders ...ibkr.LiveOrderItem) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.state.liveOrders = orders
+}
+
+// SetTrades :
+func (s *Server) SetTrades(trades ...ibkr.TradeItem) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.state.trades = trades
+}
+
+// SetOrderStatus :
+func (s *Server) SetOrderStatus(orderId int, status ibkr.OrderStatusItem) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.state.orderStatus[orderId] = status
+}
+
+// SetContractRules :
+func (s *Server) SetContractRules(contractId int, rules ibkr.ContractRules) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.state.contractRules[contractId] = rules
+}
+
+// SetContractInfo :
+func (s *Server) SetContractInfo(contractId int, info ibkr.GetContractInfoResponse) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.state.contractInfo[contractId] = info
+}
+
+// SetContractSearch :
+// Sets the result of searching symbol.
+func (s *Server) SetContractSearch(symbol string, items ...ibkr.SearchContractBySymbolItem) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.state.searches[symbol] = items
+}
+
+// SetOrderPreview :
+func (s *Server) SetOrderPreview(preview ibkr.PreviewOrderResponse) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.state.preview = preview
+}
+
+// QueueOrderReplies :
+// Queues the answers of the next PlaceOrder calls. Without a queued chain an
+// order is accepted right away with a new order id.
+func (s *Server) QueueOrderReplies(chains ...OrderReplyChain) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.state.replyChains = append(s.state.replyChains, chains...)
+}
+
+func fixture[T any](s *Server, get func(state *state) T) T {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return get(&s.state)
+}
+
+func (s *Server) registerDefaults() {
+	ok := func(get func(state *state) interface{}) HandlerFunc {
+		return func(Request) (int, interface{}) {
+			return http.StatusOK, fixture(s, get)
+		}
+	}
+
+	s.handleDefault(http.MethodPost, "/iserver/auth/status", ok(func(state *state) interface{} {
+		return state.authStatus
+	}))
+	s.handleDefault(http.MethodPost, "/tickle", ok(func(state *state) interface{} {
+		return ibkr.PingServerResponse{Session: SessionToken, IServer: ibkr.IServerInfo{AuthStatus: state.authStatus}}
+	}))
+	s.handleDefault(http.MethodPost, "/iserver/reauthenticate", ok(func(*state) interface{} {
+		return ibkr.ReauthenticateResponse{Message: "triggered"}
+	}))
+	s.handleDefault(http.MethodPost, "/logout", ok(func(*state) interface{} {
+		return ibkr.LogoutResponse{Status: true}
+	}))
+	s.handleDefault(http.MethodGet, "/iserver/account/pnl/partitioned", ok(func(state *state) interface{} {
+		return state.profitAndLoss
+	}))
+	s.handleDefault(http.MethodGet, "/portfolio/accounts", ok(func(state *state) interface{} {
+		return nonNil(state.accounts)
+	}))
+	s.handleDefault(http.MethodGet, "/portfolio/subaccounts", ok(func(state *state) interface{} {
+		return nonNil(state.accounts)
+	}))
+	s.handleDefault(http.MethodGet, "/portfolio/{accountId}/positions/{pageId}", s.positionsPage)
+	s.handleDefault(http.MethodGet, "/portfolio/{accountId}/ledger", func(req Request) (int, interface{}) {
+		ledger := fixture(s, func(state *state) map[string]ibkr.AccountLedgerItem {
+			return state.ledgers[req.Params["accountId"]]
+		})
+		if ledger == nil {
+			ledger = map[string]ibkr.AccountLedgerItem{}
+		}
+		return http.StatusOK, ledger
+	})
+	s.handleDefault(http.MethodGet, "/iserver/account/orders", ok(func(state *state) interface{} {
+		return ibkr.GetLiveOrdersResponse{Orders: nonNil(state.liveOrders), Snapshot: true}
+	}))
+	s.handleDefault(http.MethodGet, "/iserver/account/trades", ok(func(state *state) interface{} {
+		return nonNil(state.trades)
+	}))
+	s.handleDefault(http.MethodGet, "/iserver/account/order/status/{orderId}", func(req Request) (int, interface{}) {
+		orderId, _ := strconv.Atoi(req.Params["orderId"])
+		status, has := lookup(s, func(state *state) map[int]ibkr.OrderStatusItem { return state.orderStatus }, orderId)
+		if !has {
+			return http.StatusNotFound, nil
+		}
+		return http.StatusOK, status
+	})
+	s.handleDefault(http.MethodPost, "/iserver/contract/rules", func(req Request) (int, interface{}) {
+		var query struct {
+			ContractId int `json:"conid"`
+		}
+		_ = req.JSON(&query)
+		rules, has := lookup(s, func(state *state) map[int]ibkr.ContractRules { return state.contractRules }, query.ContractId)
+		if !has {
+			message := "no rules for conid " + strconv.Itoa(query.ContractId)
+			return http.StatusOK, ibkr.ContractRules{Error: &message}
+		}
+		return http.StatusOK, rules
+	})
+	s.handleDefault(http.MethodGet, "/iserver/contract/{conid}/info", func(req Request) (int, interface{}) {
+		contractId, _ := strconv.Atoi(req.Params["conid"])
+		info, has := lookup(s, func(state *state) map[int]ibkr.GetContractInfoResponse { return state.contractInfo }, contractId)
+		if !has {
+			return http.StatusNotFound, nil
+		}
+		return http.StatusOK, info
+	})
+	s.handleDefault(http.MethodGet, "/iserver/secdef/search", func(req Request) (int, interface{}) {
+		items, _ := lookup(s, func(state *state) map[string][]ibkr.SearchContractBySymbolItem { return state.searches }, req.Query.Get("symbol"))
+		return http.StatusOK, nonNil(items)
+	})
+	s.handleDefault(http.MethodPost, "/iserver/account/{accountId}/orders/whatif", ok(func(state *state) interface{} {
+		return state.preview
+	}))
+	s.handleDefault(http.MethodPost, "/iserver/account/{accountId}/orders", s.placeOrder)
+	s.handleDefault(http.MethodPost, "/iserver/reply/{replyId}", s.replyOrder)
+	s.handleDefault(http.MethodDelete, "/iserver/account/{accountId}/order/{orderId}", func(req Request) (int, interface{}) {
+		orderId, _ := strconv.ParseInt(req.Params["orderId"], 10, 64)
+		return http.StatusOK, ibkr.CancelOrderResponse{
+			Msg:       "Request was submitted",
+			OrderId:   orderId,
+			AccountId: req.Params["accountId"],
+		}
+	})
+	s.handleDefault(http.MethodPost, "/iserver/questions/suppress", ok(func(*state) interface{} {
+		return ibkr.SuppressMessagesResponse{Status: "submitted"}
+	}))
+}
+
+func (s *Server) positionsPage(req Request) (int, interface{}) {
+	page, _ := strconv.Atoi(req.Params["pageId"])
+	positions := fixture(s, func(state *state) []ibkr.PositionInfo {
+		return state.positions[req.Params["accountId"]]
+	})
+	start, end := page*positionsPageSize, (page+1)*positionsPageSize
+	if start > len(positions) {
+		start = len(positions)
+	}
+	if end > len(positions) {
+		end = len(positions)
+	}
+	return http.StatusOK, nonNil(positions[start:end])
+}
+
+func (s *Server) placeOrder(req Request) (int, interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var chain OrderReplyChain
+	if len(s.state.replyChains) > 0 {
+		chain = s.state.replyChains[0]
+		s.state.replyChains = s.state.replyChains[1:]
+	}
+	return s.answerLocked(chain)
+}
+
+func (s *Server) replyOrder(req Request) (int, interface{}) {
+	var param struct {
+		Confirmed bool `json:"confirmed"`
+	}
+	_ = req.JSON(&param)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	replyId := req.Params["replyId"]
+	chain, has := s.state.pending[replyId]
+	if !has {
+		return http.StatusOK, ibkr.PlaceOrderReplyConfirmationResponse{Error: "unknown reply id " + replyId}
+	}
+	delete(s.state.pending, replyId)
+	if !param.Confirmed {
+		return http.StatusOK, ibkr.PlaceOrderReplyConfirmationResponse{Error: "order was not confirmed"}
+	}
+	return s.answerLocked(chain)
+}
+
+// answerLocked :
+// Answers with the next prompt of chain, or its results.
+func (s *Server) answerLocked(chain OrderReplyChain) (int, interface{}) {
+	if len(chain.Prompts) > 0 {
+		prompt := chain.Prompts[0]
+		if prompt.Id == "" {
+			prompt.Id = "reply-" + strconv.Itoa(s.state.nextOrderId)
+			s.state.nextOrderId++
+		}
+		chain.Prompts = chain.Prompts[1:]
+		s.state.pending[prompt.Id] = chain
+		return http.StatusOK, []ibkr.PlaceOrderAlternateResult{prompt}
+	}
+	if chain.Reject != "" {
+		return http.StatusOK, ibkr.PlaceOrderRejectResult{Error: chain.Reject}
+	}
+	results := chain.Results
+	if len(results) == 0 {
+		results = []ibkr.PlaceOrderNormalResult{{OrderId: strconv.Itoa(s.state.nextOrderId), OrderStatus: "PreSubmitted"}}
+		s.state.nextOrderId++
+	}
+	return http.StatusOK, results
+}
+
+func lookup[K comparable, V any](s *Server, get func(state *state) map[K]V, key K) (V, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	value, has := get(&s.state)[key]
+	return value, has
+}
+
+// nonNil :
+// Serves empty lists as [] instead of null.
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
@@ -0,0 +1,248 @@
+// Package ibkrtest provides an in-process fake Client Portal gateway, so code
+// built on the SDK can be tested offline.
+//
+//	server := ibkrtest.NewServer(t)
+//	server.SetPositions("DU123", positions)
+//	client := server.Client()
+//	...
+//	server.AssertCalled(t, http.MethodGet, "/portfolio/{accountId}/positions/{pageId}")
+package ibkrtest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+)
+
+// SessionToken is returned by /tickle and expected in the websocket cookie.
+const SessionToken = "ibkrtest-session"
+
+// Request :
+// A REST request received by the server.
+type Request struct {
+	Method   string
+	Path     string
+	Template string
+	// Params holds the placeholders of Template, e.g. "accountId".
+	Params map[string]string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// JSON :
+// Decodes the request body into v.
+func (r Request) JSON(v interface{}) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// HandlerFunc :
+// Answers a request with a status code and a body encoded as JSON; a []byte
+// body is written as is.
+type HandlerFunc func(req Request) (status int, body interface{})
+
+type route struct {
+	method   string
+	template string
+	handler  HandlerFunc
+}
+
+// Server :
+type Server struct {
+	*httptest.Server
+
+	mutex    sync.Mutex
+	routes   []route
+	requests []Request
+	state    state
+	ws       websocketState
+}
+
+// NewServer :
+// Starts a fake gateway closed with tb's cleanup. tb may be nil, then call Close.
+func NewServer(tb testing.TB) *Server {
+	s := newServer()
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	if tb != nil {
+		tb.Cleanup(s.Close)
+	}
+	return s
+}
+
+// NewTLSServer :
+// Like NewServer but serves https and wss with a self-signed certificate.
+func NewTLSServer(tb testing.TB) *Server {
+	s := newServer()
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(s.serveHTTP))
+	if tb != nil {
+		tb.Cleanup(s.Close)
+	}
+	return s
+}
+
+func newServer() *Server {
+	s := &Server{state: newState()}
+	s.ws = newWebsocketState()
+	s.registerDefaults()
+	return s
+}
+
+// Close :
+func (s *Server) Close() {
+	s.closeWebsockets()
+	s.Server.Close()
+}
+
+// Client :
+// Returns a REST client talking to the server.
+func (s *Server) Client() *ibkr.Client {
+	return ibkr.NewClient(s.URL, "", true)
+}
+
+// WebsocketClient :
+// Returns a websocket client talking to the server; the session token is
+// fetched through Client.
+func (s *Server) WebsocketClient() *ibkr.WebSocketClient {
+	wsURL := "ws" + strings.TrimPrefix(s.URL, "http")
+	return ibkr.NewWebsocketClient(wsURL, "", true).WithRESTClient(s.Client())
+}
+
+// Handle :
+// Answers method and template, e.g. "/iserver/account/{accountId}/orders",
+// with handler instead of the fixtures. Later handlers win.
+func (s *Server) Handle(method, template string, handler HandlerFunc) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.routes = append([]route{{method, template, handler}}, s.routes...)
+}
+
+func (s *Server) handleDefault(method, template string, handler HandlerFunc) {
+	s.routes = append(s.routes, route{method, template, handler})
+}
+
+// Requests :
+// Returns all REST requests received so far.
+func (s *Server) Requests() []Request {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// RequestsTo :
+// Returns the requests matching method and template.
+func (s *Server) RequestsTo(method, template string) []Request {
+	var result []Request
+	for _, req := range s.Requests() {
+		if req.Method == method && req.Template == template {
+			result = append(result, req)
+		}
+	}
+	return result
+}
+
+// Reset :
+// Forgets the recorded requests and websocket messages.
+func (s *Server) Reset() {
+	s.mutex.Lock()
+	s.requests = nil
+	s.mutex.Unlock()
+	s.ws.reset()
+}
+
+// AssertCalled :
+func (s *Server) AssertCalled(tb testing.TB, method, template string) []Request {
+	tb.Helper()
+	requests := s.RequestsTo(method, template)
+	if len(requests) == 0 {
+		tb.Errorf("ibkrtest: expected %s %s to be called", method, template)
+	}
+	return requests
+}
+
+// AssertNotCalled :
+func (s *Server) AssertNotCalled(tb testing.TB, method, template string) {
+	tb.Helper()
+	if requests := s.RequestsTo(method, template); len(requests) > 0 {
+		tb.Errorf("ibkrtest: expected %s %s not to be called, called %d times", method, template, len(requests))
+	}
+}
+
+// AssertCallCount :
+func (s *Server) AssertCallCount(tb testing.TB, method, template string, count int) {
+	tb.Helper()
+	if requests := s.RequestsTo(method, template); len(requests) != count {
+		tb.Errorf("ibkrtest: expected %s %s to be called %d times, called %d times", method, template, count, len(requests))
+	}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, ibkr.DefaultPrefixEndpoint)
+	if path == "/ws" {
+		s.serveWebsocket(w, r)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	req := Request{
+		Method: r.Method,
+		Path:   path,
+		Query:  r.URL.Query(),
+		Header: r.Header.Clone(),
+		Body:   body,
+	}
+
+	s.mutex.Lock()
+	var handler HandlerFunc
+	for _, route := range s.routes {
+		if route.method != r.Method {
+			continue
+		}
+		if params, ok := matchTemplate(route.template, path); ok {
+			req.Template, req.Params, handler = route.template, params, route.handler
+			break
+		}
+	}
+	s.requests = append(s.requests, req)
+	s.mutex.Unlock()
+
+	if handler == nil {
+		http.NotFound(w, r)
+		return
+	}
+	status, response := handler(req)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	switch v := response.(type) {
+	case nil:
+	case []byte:
+		_, _ = w.Write(v)
+	default:
+		_ = json.NewEncoder(w).Encode(v)
+	}
+}
+
+// matchTemplate :
+// Matches path against a template like "/portfolio/{accountId}/ledger".
+func matchTemplate(template, path string) (map[string]string, bool) {
+	templateParts := strings.Split(template, "/")
+	pathParts := strings.Split(path, "/")
+	if len(templateParts) != len(pathParts) {
+		return nil, false
+	}
+	params := map[string]string{}
+	for n, part := range templateParts {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			params[part[1:len(part)-1]] = pathParts[n]
+		} else if part != pathParts[n] {
+			return nil, false
+		}
+	}
+	return params, true
+}
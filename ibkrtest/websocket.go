@@ -0,0 +1,256 @@
+package ibkrtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+	"github.com/gorilla/websocket"
+)
+
+type websocketConnection struct {
+	conn       *websocket.Conn
+	writeMutex sync.Mutex
+}
+
+func (c *websocketConnection) write(message []byte) error {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+	return c.conn.WriteMessage(websocket.TextMessage, message)
+}
+
+type websocketState struct {
+	mutex       sync.Mutex
+	connections map[*websocketConnection]bool
+	// messages are the text messages received from clients, e.g. "smd+265598+{...}"
+	messages []string
+	received chan struct{}
+	ticks    map[int][]ibkr.WebsocketPublicMarketDataResponse
+}
+
+func newWebsocketState() websocketState {
+	return websocketState{
+		connections: map[*websocketConnection]bool{},
+		received:    make(chan struct{}),
+		ticks:       map[int][]ibkr.WebsocketPublicMarketDataResponse{},
+	}
+}
+
+func (w *websocketState) reset() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.messages = nil
+}
+
+var upgrader = websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+
+func (s *Server) serveWebsocket(w http.ResponseWriter, r *http.Request) {
+	if !strings.Contains(r.Header.Get("Cookie"), SessionToken) {
+		http.Error(w, "missing session cookie", http.StatusUnauthorized)
+		return
+	}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	c := &websocketConnection{conn: conn}
+
+	s.ws.mutex.Lock()
+	s.ws.connections[c] = true
+	s.ws.mutex.Unlock()
+	defer func() {
+		s.ws.mutex.Lock()
+		delete(s.ws.connections, c)
+		s.ws.mutex.Unlock()
+		_ = conn.Close()
+	}()
+
+	_ = c.write([]byte(`{"topic":"system","success":"ibkrtest"}`))
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		s.receive(c, string(message))
+	}
+}
+
+func (s *Server) receive(c *websocketConnection, message string) {
+	s.ws.mutex.Lock()
+	s.ws.messages = append(s.ws.messages, message)
+	close(s.ws.received)
+	s.ws.received = make(chan struct{})
+
+	var ticks []ibkr.WebsocketPublicMarketDataResponse
+	if strings.HasPrefix(message, ibkr.MessageTopicSubscribeMarketData+"+") {
+		parts := strings.SplitN(message, "+", 3)
+		if contractId, err := strconv.Atoi(parts[1]); err == nil {
+			ticks = s.ws.ticks[contractId]
+		}
+	}
+	s.ws.mutex.Unlock()
+
+	for _, tick := range ticks {
+		if message, err := marketDataMessage(tick); err == nil {
+			_ = c.write(message)
+		}
+	}
+}
+
+func (s *Server) closeWebsockets() {
+	s.ws.mutex.Lock()
+	defer s.ws.mutex.Unlock()
+	for c := range s.ws.connections {
+		_ = c.conn.Close()
+	}
+}
+
+// DropWebsockets :
+// Closes every websocket connection without a close frame, like a network failure.
+func (s *Server) DropWebsockets() {
+	s.closeWebsockets()
+}
+
+// WebsocketConnections :
+// Returns the number of open websocket connections.
+func (s *Server) WebsocketConnections() int {
+	s.ws.mutex.Lock()
+	defer s.ws.mutex.Unlock()
+	return len(s.ws.connections)
+}
+
+// WebsocketMessages :
+// Returns the messages sent by clients, e.g. "smd+265598+{...}" or "uor+{}".
+func (s *Server) WebsocketMessages() []string {
+	s.ws.mutex.Lock()
+	defer s.ws.mutex.Unlock()
+	return append([]string(nil), s.ws.messages...)
+}
+
+// WaitForWebsocketMessage :
+// Waits until a client sent a message starting with prefix, e.g. "smd+265598",
+// and returns it.
+func (s *Server) WaitForWebsocketMessage(tb testing.TB, prefix string, timeout time.Duration) string {
+	tb.Helper()
+	deadline := time.After(timeout)
+	for {
+		s.ws.mutex.Lock()
+		for _, message := range s.ws.messages {
+			if strings.HasPrefix(message, prefix) {
+				s.ws.mutex.Unlock()
+				return message
+			}
+		}
+		received := s.ws.received
+		s.ws.mutex.Unlock()
+
+		select {
+		case <-received:
+		case <-deadline:
+			tb.Fatalf("ibkrtest: no websocket message %q within %s", prefix, timeout)
+			return ""
+		}
+	}
+}
+
+// SetMarketDataTicks :
+// Sets the ticks sent in order whenever a client subscribes to conid.
+func (s *Server) SetMarketDataTicks(contractId int, ticks ...ibkr.WebsocketPublicMarketDataResponse) {
+	for n := range ticks {
+		ticks[n].ContractId = contractId
+	}
+	s.ws.mutex.Lock()
+	defer s.ws.mutex.Unlock()
+	s.ws.ticks[contractId] = ticks
+}
+
+// Push :
+// Sends message, encoded as JSON unless it is a []byte or string, to every
+// connected client.
+func (s *Server) Push(message interface{}) error {
+	var buf []byte
+	switch v := message.(type) {
+	case []byte:
+		buf = v
+	case string:
+		buf = []byte(v)
+	default:
+		var err error
+		if buf, err = json.Marshal(v); err != nil {
+			return err
+		}
+	}
+
+	s.ws.mutex.Lock()
+	connections := make([]*websocketConnection, 0, len(s.ws.connections))
+	for c := range s.ws.connections {
+		connections = append(connections, c)
+	}
+	s.ws.mutex.Unlock()
+
+	for _, c := range connections {
+		if err := c.write(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PushMarketData :
+// Sends a smd tick of tick.ContractId.
+func (s *Server) PushMarketData(tick ibkr.WebsocketPublicMarketDataResponse) error {
+	message, err := marketDataMessage(tick)
+	if err != nil {
+		return err
+	}
+	return s.Push(message)
+}
+
+// PushOrders :
+// Sends a sor update.
+func (s *Server) PushOrders(orders ...ibkr.WebsocketPrivateOrder) error {
+	return s.Push(topicMessage{Topic: ibkr.MessageTopicSubscribeOrder, Args: orders})
+}
+
+// PushTrades :
+// Sends a str update.
+func (s *Server) PushTrades(trades ...ibkr.WebsocketPrivateTradesData) error {
+	return s.Push(topicMessage{Topic: ibkr.MessageTopicSubscribeTradesData, Args: trades})
+}
+
+// PushPnL :
+// Sends a spl update of accountId.
+func (s *Server) PushPnL(accountId string, pnl ibkr.AccountProfitAndLossInfo) error {
+	return s.Push(topicMessage{
+		Topic: ibkr.MessageTopicSubscribePnL,
+		Args:  map[string]ibkr.AccountProfitAndLossInfo{accountId + ".Core": pnl},
+	})
+}
+
+// PushHeartbeat :
+// Sends a system heartbeat.
+func (s *Server) PushHeartbeat() error {
+	return s.Push(ibkr.WebsocketUnsolicitedSystemConnectionResponse{
+		Topic: ibkr.UnsolicitedMessageTopicSystemConnection,
+		HB:    time.Now().UnixMilli(),
+	})
+}
+
+type topicMessage struct {
+	Topic string      `json:"topic"`
+	Args  interface{} `json:"args"`
+}
+
+func marketDataMessage(tick ibkr.WebsocketPublicMarketDataResponse) ([]byte, error) {
+	tick.Topic = fmt.Sprintf("%s+%d", ibkr.MessageTopicSubscribeMarketData, tick.ContractId)
+	if tick.UpdateTime == 0 {
+		tick.UpdateTime = time.Now().UnixMilli()
+	}
+	return json.Marshal(tick)
+}
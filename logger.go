@@ -1,27 +1,56 @@
 package ibkr
 
 import (
-	"io"
-	"log"
+	"log/slog"
 	"os"
+	"regexp"
+	"sync/atomic"
 )
 
-var logger *log.Logger = newNoopLogger()
+var logger atomic.Pointer[slog.Logger]
 
-func SetLogger(l *log.Logger) {
+func init() {
+	logger.Store(newNoopLogger())
+}
+
+// SetLogger :
+// Sets the logger of every client without a logger of its own. nil disables
+// logging, which is the default.
+func SetLogger(l *slog.Logger) {
 	if l != nil {
 		// Use provided logger.
-		logger = l
+		logger.Store(l)
 	} else {
 		// Disable logging.
-		logger = newNoopLogger()
+		logger.Store(newNoopLogger())
 	}
 }
 
-func newDefaultLogger() *log.Logger {
-	return log.New(os.Stderr, "ibkr-golang", log.LstdFlags)
+func packageLogger() *slog.Logger {
+	return logger.Load()
+}
+
+func newDefaultLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})).
+		With("logger", "ibkr-golang")
 }
 
-func newNoopLogger() *log.Logger {
-	return log.New(io.Discard, "", log.LstdFlags)
+func newNoopLogger() *slog.Logger {
+	return slog.New(slog.DiscardHandler)
+}
+
+var (
+	accountIdPattern     = regexp.MustCompile(`\b([A-Z]{1,3})\d{2,}(\d{3})\b`)
+	sessionTokenPattern  = regexp.MustCompile(`("(?:session|sessionId|token|access_token|secret)"\s*:\s*")[^"]*(")`)
+	sessionCookiePattern = regexp.MustCompile(`(api=)\{[^;]*\}`)
+)
+
+// Redact :
+// Masks account ids, e.g. DU1234567 becomes DU***567, and session tokens the
+// way log output does.
+func Redact(text string) string {
+	text = accountIdPattern.ReplaceAllString(text, "$1***$2")
+	text = sessionTokenPattern.ReplaceAllString(text, "$1[REDACTED]$2")
+	text = sessionCookiePattern.ReplaceAllString(text, "$1[REDACTED]")
+	return text
 }
@@ -0,0 +1,30 @@
+package ibkr
+
+import "net/http"
+
+// Handler :
+// Sends a request of operation and returns the raw response.
+type Handler func(operation Operation, req *http.Request) (*http.Response, error)
+
+// Middleware :
+// Wraps every REST call, e.g. to add headers, audit orders or inject faults.
+// It may return without calling next.
+type Middleware func(next Handler) Handler
+
+// Use :
+// Appends middleware; the first one added is the outermost.
+func (c *Client) Use(middleware ...Middleware) *Client {
+	c.middleware = append(c.middleware, middleware...)
+	return c
+}
+
+// handler :
+func (c *Client) handler() Handler {
+	handler := Handler(func(_ Operation, req *http.Request) (*http.Response, error) {
+		return c.httpClient.Do(req)
+	})
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		handler = c.middleware[i](handler)
+	}
+	return handler
+}
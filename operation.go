@@ -0,0 +1,75 @@
+package ibkr
+
+import "context"
+
+// OperationName :
+// Names a REST call of the services, stable across gateway paths.
+type OperationName string
+
+const (
+	OperationNameGetProfitAndLoss                         = OperationName("GetProfitAndLoss")
+	OperationNameGetAllContractIds                        = OperationName("GetAllContractIds")
+	OperationNameSearchSecurityDefinition                 = OperationName("SearchSecurityDefinition")
+	OperationNameGetContractInfo                          = OperationName("GetContractInfo")
+	OperationNameGetCurrencyPairs                         = OperationName("GetCurrencyPairs")
+	OperationNameGetCurrencyExchangeRate                  = OperationName("GetCurrencyExchangeRate")
+	OperationNameGetContractInfoAndRules                  = OperationName("GetContractInfoAndRules")
+	OperationNameSearchContractBySymbol                   = OperationName("SearchContractBySymbol")
+	OperationNameSearchContractRules                      = OperationName("SearchContractRules")
+	OperationNameGetSecurityFutures                       = OperationName("GetSecurityFutures")
+	OperationNameGetSecurityStocks                        = OperationName("GetSecurityStocks")
+	OperationNameGetTradingSchedule                       = OperationName("GetTradingSchedule")
+	OperationNameGetOrderStatus                           = OperationName("GetOrderStatus")
+	OperationNameGetTrades                                = OperationName("GetTrades")
+	OperationNameGetLiveOrders                            = OperationName("GetLiveOrders")
+	OperationNamePlaceOrder                               = OperationName("PlaceOrder")
+	OperationNamePreviewOrder                             = OperationName("PreviewOrder")
+	OperationNameCancelOrder                              = OperationName("CancelOrder")
+	OperationNamePlaceOrderReplyConfirmation              = OperationName("PlaceOrderReplyConfirmation")
+	OperationNameRespondServerPrompt                      = OperationName("RespondServerPrompt")
+	OperationNameSuppressMessages                         = OperationName("SuppressMessages")
+	OperationNameGetAccounts                              = OperationName("GetAccounts")
+	OperationNameGetSubAccounts                           = OperationName("GetSubAccounts")
+	OperationNameGetSubAccountsWithLargeAccountStructures = OperationName("GetSubAccountsWithLargeAccountStructures")
+	OperationNameGetSpecificAccount                       = OperationName("GetSpecificAccount")
+	OperationNameGetCombinationPositions                  = OperationName("GetCombinationPositions")
+	OperationNameGetPositions                             = OperationName("GetPositions")
+	OperationNameGetPositionsNew                          = OperationName("GetPositionsNew")
+	OperationNameGetPositionByContractId                  = OperationName("GetPositionByContractId")
+	OperationNameGetLedger                                = OperationName("GetLedger")
+	OperationNamePostAuthStatus                           = OperationName("PostAuthStatus")
+	OperationNamePostReauthenticate                       = OperationName("PostReauthenticate")
+	OperationNamePostLogout                               = OperationName("PostLogout")
+	OperationNamePostPingServer                           = OperationName("PostPingServer")
+)
+
+// Operation :
+// A REST call as seen by middleware: its name and gateway endpoint.
+type Operation struct {
+	Name     OperationName
+	Endpoint Endpoint
+}
+
+func newOperation(name OperationName, template string, params ...interface{}) Operation {
+	return Operation{Name: name, Endpoint: newEndpoint(template, params...)}
+}
+
+type operationContextKey struct{}
+
+func withOperation(ctx context.Context, operation Operation) context.Context {
+	return context.WithValue(ctx, operationContextKey{}, operation)
+}
+
+// OperationFromContext :
+// Returns the operation of a request sent by a service.
+func OperationFromContext(ctx context.Context) (Operation, bool) {
+	operation, ok := ctx.Value(operationContextKey{}).(Operation)
+	return operation, ok
+}
+
+// EndpointFromContext :
+// Returns the endpoint of a request sent by a service.
+func EndpointFromContext(ctx context.Context) (Endpoint, bool) {
+	operation, ok := OperationFromContext(ctx)
+	return operation.Endpoint, ok
+}
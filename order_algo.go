@@ -0,0 +1,348 @@
+package ibkr
+
+// AlgoParams :
+// The typed parameters of one IB algorithm, set on an order with
+// OrderBuilder.Algo. Zero fields are left out, so the algorithm uses its
+// default. Times are like "09:30:00 US/Eastern" and percentages of volume
+// are fractions, e.g. 0.1 for 10%.
+type AlgoParams interface {
+	Algorithm() IBAlgorithm
+	Parameters() map[string]interface{}
+}
+
+// algoParameters :
+// Collects the non-zero parameters of an algorithm.
+type algoParameters map[string]interface{}
+
+func (p algoParameters) withDecimal(name string, value Decimal) algoParameters {
+	if !value.IsZero() {
+		p[name] = value
+	}
+	return p
+}
+
+func (p algoParameters) withInt(name string, value int) algoParameters {
+	if value != 0 {
+		p[name] = value
+	}
+	return p
+}
+
+func (p algoParameters) withString(name, value string) algoParameters {
+	if value != "" {
+		p[name] = value
+	}
+	return p
+}
+
+func (p algoParameters) withBool(name string, value bool) algoParameters {
+	if value {
+		p[name] = value
+	}
+	return p
+}
+
+// AdaptiveParams :
+type AdaptiveParams struct {
+	Priority AdaptivePriority
+}
+
+func (p AdaptiveParams) Algorithm() IBAlgorithm {
+	return IBAlgorithmAdaptive
+}
+
+func (p AdaptiveParams) Parameters() map[string]interface{} {
+	return algoParameters{}.withString("adaptivePriority", string(p.Priority))
+}
+
+// VWAPParams :
+type VWAPParams struct {
+	MaxPctVol        Decimal
+	StartTime        string
+	EndTime          string
+	AllowPastEndTime bool
+	NoTakeLiq        bool
+	SpeedUp          bool
+}
+
+func (p VWAPParams) Algorithm() IBAlgorithm {
+	return IBAlgorithmVWAP
+}
+
+func (p VWAPParams) Parameters() map[string]interface{} {
+	return algoParameters{}.
+		withDecimal("maxPctVol", p.MaxPctVol).
+		withString("startTime", p.StartTime).
+		withString("endTime", p.EndTime).
+		withBool("allowPastEndTime", p.AllowPastEndTime).
+		withBool("noTakeLiq", p.NoTakeLiq).
+		withBool("speedUp", p.SpeedUp)
+}
+
+// TWAPParams :
+// StrategyType is one of Marketable, Matching Midpoint, Matching Same Side
+// and Matching Last.
+type TWAPParams struct {
+	StrategyType     string
+	StartTime        string
+	EndTime          string
+	AllowPastEndTime bool
+}
+
+func (p TWAPParams) Algorithm() IBAlgorithm {
+	return IBAlgorithmTWAP
+}
+
+func (p TWAPParams) Parameters() map[string]interface{} {
+	return algoParameters{}.
+		withString("strategyType", p.StrategyType).
+		withString("startTime", p.StartTime).
+		withString("endTime", p.EndTime).
+		withBool("allowPastEndTime", p.AllowPastEndTime)
+}
+
+// ArrivalPriceParams :
+type ArrivalPriceParams struct {
+	MaxPctVol        Decimal
+	RiskAversion     RiskAversion
+	StartTime        string
+	EndTime          string
+	ForceCompletion  bool
+	AllowPastEndTime bool
+}
+
+func (p ArrivalPriceParams) Algorithm() IBAlgorithm {
+	return IBAlgorithmArrivalPrice
+}
+
+func (p ArrivalPriceParams) Parameters() map[string]interface{} {
+	return algoParameters{}.
+		withDecimal("maxPctVol", p.MaxPctVol).
+		withString("riskAversion", string(p.RiskAversion)).
+		withString("startTime", p.StartTime).
+		withString("endTime", p.EndTime).
+		withBool("forceCompletion", p.ForceCompletion).
+		withBool("allowPastEndTime", p.AllowPastEndTime)
+}
+
+// ClosePriceParams :
+type ClosePriceParams struct {
+	MaxPctVol       Decimal
+	RiskAversion    RiskAversion
+	StartTime       string
+	ForceCompletion bool
+}
+
+func (p ClosePriceParams) Algorithm() IBAlgorithm {
+	return IBAlgorithmClosePrice
+}
+
+func (p ClosePriceParams) Parameters() map[string]interface{} {
+	return algoParameters{}.
+		withDecimal("maxPctVol", p.MaxPctVol).
+		withString("riskAversion", string(p.RiskAversion)).
+		withString("startTime", p.StartTime).
+		withBool("forceCompletion", p.ForceCompletion)
+}
+
+// BalanceImpactRiskParams :
+type BalanceImpactRiskParams struct {
+	MaxPctVol       Decimal
+	RiskAversion    RiskAversion
+	ForceCompletion bool
+}
+
+func (p BalanceImpactRiskParams) Algorithm() IBAlgorithm {
+	return IBAlgorithmBalanceImpactRisk
+}
+
+func (p BalanceImpactRiskParams) Parameters() map[string]interface{} {
+	return algoParameters{}.
+		withDecimal("maxPctVol", p.MaxPctVol).
+		withString("riskAversion", string(p.RiskAversion)).
+		withBool("forceCompletion", p.ForceCompletion)
+}
+
+// MinimiseImpactParams :
+type MinimiseImpactParams struct {
+	MaxPctVol Decimal
+}
+
+func (p MinimiseImpactParams) Algorithm() IBAlgorithm {
+	return IBAlgorithmMinimiseImpact
+}
+
+func (p MinimiseImpactParams) Parameters() map[string]interface{} {
+	return algoParameters{}.withDecimal("maxPctVol", p.MaxPctVol)
+}
+
+// DarkIceParams :
+type DarkIceParams struct {
+	DisplaySize      int
+	StartTime        string
+	EndTime          string
+	AllowPastEndTime bool
+}
+
+func (p DarkIceParams) Algorithm() IBAlgorithm {
+	return IBAlgorithmDarkIce
+}
+
+func (p DarkIceParams) Parameters() map[string]interface{} {
+	return algoParameters{}.
+		withInt("displaySize", p.DisplaySize).
+		withString("startTime", p.StartTime).
+		withString("endTime", p.EndTime).
+		withBool("allowPastEndTime", p.AllowPastEndTime)
+}
+
+// AccumulateDistributeParams :
+// TimeBetweenOrders is in seconds.
+type AccumulateDistributeParams struct {
+	ComponentSize     int
+	TimeBetweenOrders int
+	RandomizeTime20   bool
+	RandomizeSize55   bool
+	GiveUp            int
+	CatchUp           bool
+	WaitForFill       bool
+	ActiveTimeStart   string
+	ActiveTimeEnd     string
+}
+
+func (p AccumulateDistributeParams) Algorithm() IBAlgorithm {
+	return IBAlgorithmAccumulateDistribute
+}
+
+func (p AccumulateDistributeParams) Parameters() map[string]interface{} {
+	return algoParameters{}.
+		withInt("componentSize", p.ComponentSize).
+		withInt("timeBetweenOrders", p.TimeBetweenOrders).
+		withBool("randomizeTime20", p.RandomizeTime20).
+		withBool("randomizeSize55", p.RandomizeSize55).
+		withInt("giveUp", p.GiveUp).
+		withBool("catchUp", p.CatchUp).
+		withBool("waitForFill", p.WaitForFill).
+		withString("activeTimeStart", p.ActiveTimeStart).
+		withString("activeTimeEnd", p.ActiveTimeEnd)
+}
+
+// AccumulateDistributeAltParams :
+// The parameters of AccumulateDistributeParams for the algorithm named
+// AccuDistr, as some gateways list Accumulate/Distribute under that name.
+type AccumulateDistributeAltParams AccumulateDistributeParams
+
+func (p AccumulateDistributeAltParams) Algorithm() IBAlgorithm {
+	return IBAlgorithmAccumulateDistributeAlt
+}
+
+func (p AccumulateDistributeAltParams) Parameters() map[string]interface{} {
+	return AccumulateDistributeParams(p).Parameters()
+}
+
+// PercentageOfVolumeParams :
+type PercentageOfVolumeParams struct {
+	PctVol    Decimal
+	StartTime string
+	EndTime   string
+	NoTakeLiq bool
+}
+
+func (p PercentageOfVolumeParams) Algorithm() IBAlgorithm {
+	return IBAlgorithmPercentageOfVolume
+}
+
+func (p PercentageOfVolumeParams) Parameters() map[string]interface{} {
+	return algoParameters{}.
+		withDecimal("pctVol", p.PctVol).
+		withString("startTime", p.StartTime).
+		withString("endTime", p.EndTime).
+		withBool("noTakeLiq", p.NoTakeLiq)
+}
+
+// PriceVariantPercentageParams :
+// Trades PctVol of the volume, changed by DeltaPctVol as the price moves,
+// within MinPctVol4Px and MaxPctVol4Px.
+type PriceVariantPercentageParams struct {
+	PctVol       Decimal
+	DeltaPctVol  Decimal
+	MinPctVol4Px Decimal
+	MaxPctVol4Px Decimal
+	StartTime    string
+	EndTime      string
+	NoTakeLiq    bool
+}
+
+func (p PriceVariantPercentageParams) Algorithm() IBAlgorithm {
+	return IBAlgorithmPriceVariantPercentage
+}
+
+func (p PriceVariantPercentageParams) Parameters() map[string]interface{} {
+	return algoParameters{}.
+		withDecimal("pctVol", p.PctVol).
+		withDecimal("deltaPctVol", p.DeltaPctVol).
+		withDecimal("minPctVol4Px", p.MinPctVol4Px).
+		withDecimal("maxPctVol4Px", p.MaxPctVol4Px).
+		withString("startTime", p.StartTime).
+		withString("endTime", p.EndTime).
+		withBool("noTakeLiq", p.NoTakeLiq)
+}
+
+// SizeVariantPercentageParams :
+// Moves from StartPctVol to EndPctVol of the volume as the order fills.
+type SizeVariantPercentageParams struct {
+	StartPctVol Decimal
+	EndPctVol   Decimal
+	StartTime   string
+	EndTime     string
+	NoTakeLiq   bool
+}
+
+func (p SizeVariantPercentageParams) Algorithm() IBAlgorithm {
+	return IBAlgorithmSizeVariantPercentage
+}
+
+func (p SizeVariantPercentageParams) Parameters() map[string]interface{} {
+	return algoParameters{}.
+		withDecimal("startPctVol", p.StartPctVol).
+		withDecimal("endPctVol", p.EndPctVol).
+		withString("startTime", p.StartTime).
+		withString("endTime", p.EndTime).
+		withBool("noTakeLiq", p.NoTakeLiq)
+}
+
+// TimeVariantPercentageParams :
+// Moves from StartPctVol to EndPctVol of the volume between StartTime and
+// EndTime.
+type TimeVariantPercentageParams struct {
+	StartPctVol Decimal
+	EndPctVol   Decimal
+	StartTime   string
+	EndTime     string
+	NoTakeLiq   bool
+}
+
+func (p TimeVariantPercentageParams) Algorithm() IBAlgorithm {
+	return IBAlgorithmTimeVariantPercentage
+}
+
+func (p TimeVariantPercentageParams) Parameters() map[string]interface{} {
+	return algoParameters{}.
+		withDecimal("startPctVol", p.StartPctVol).
+		withDecimal("endPctVol", p.EndPctVol).
+		withString("startTime", p.StartTime).
+		withString("endTime", p.EndTime).
+		withBool("noTakeLiq", p.NoTakeLiq)
+}
+
+// MiddlePriceParams :
+// The midprice algorithm takes no parameters.
+type MiddlePriceParams struct{}
+
+func (p MiddlePriceParams) Algorithm() IBAlgorithm {
+	return IBAlgorithmMiddlePrice
+}
+
+func (p MiddlePriceParams) Parameters() map[string]interface{} {
+	return algoParameters{}
+}
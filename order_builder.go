@@ -0,0 +1,357 @@
+package ibkr
+
+// OrderBuilder :
+// Builds a PlaceOrderParam one typed step at a time, so only valid field
+// combinations can be produced:
+//
+//	param, err := ibkr.NewOrder("U1234567", 265598).
+//		Buy(ibkr.DecimalFromInt(100)).
+//		Limit(ibkr.MustParseDecimal("187.25")).
+//		GTC().
+//		OutsideRTH().
+//		Build()
+//
+// Each order type method sets the price fields of its type and clears the
+// others, Buy and Sell clear a cash quantity and BuyCash and SellCash clear
+// the quantity. The time in force defaults to DAY.
+type OrderBuilder struct {
+	param PlaceOrderParam
+}
+
+// NewOrder :
+func NewOrder(accountId string, contractId int) *OrderBuilder {
+	return &OrderBuilder{param: PlaceOrderParam{
+		AccountId:   accountId,
+		ContractId:  &contractId,
+		TimeInForce: TimeInForceDAY,
+	}}
+}
+
+// Buy :
+func (b *OrderBuilder) Buy(quantity Decimal) *OrderBuilder {
+	return b.quantity(OrderSideBuy, quantity)
+}
+
+// Sell :
+func (b *OrderBuilder) Sell(quantity Decimal) *OrderBuilder {
+	return b.quantity(OrderSideSell, quantity)
+}
+
+// BuyCash :
+// Buys for amount in the contract currency instead of a quantity.
+func (b *OrderBuilder) BuyCash(amount Decimal) *OrderBuilder {
+	return b.cash(OrderSideBuy, amount)
+}
+
+// SellCash :
+func (b *OrderBuilder) SellCash(amount Decimal) *OrderBuilder {
+	return b.cash(OrderSideSell, amount)
+}
+
+func (b *OrderBuilder) quantity(side OrderSide, quantity Decimal) *OrderBuilder {
+	b.param.Side = string(side)
+	b.param.Quantity = quantity
+	b.param.CashQty = nil
+	return b
+}
+
+func (b *OrderBuilder) cash(side OrderSide, amount Decimal) *OrderBuilder {
+	b.param.Side = string(side)
+	b.param.Quantity = Decimal{}
+	b.param.CashQty = &amount
+	return b
+}
+
+// orderType :
+// Sets the order type with its prices, nil for the ones it does not use.
+func (b *OrderBuilder) orderType(orderType OrderType, price, auxPrice *Decimal) *OrderBuilder {
+	b.param.OrderType = orderType
+	b.param.Price = price
+	b.param.AuxPrice = auxPrice
+	b.param.TrailingAmount = nil
+	b.param.TrailingType = ""
+	return b
+}
+
+// Market :
+func (b *OrderBuilder) Market() *OrderBuilder {
+	return b.orderType(OrderTypeMarket, nil, nil)
+}
+
+// MarketToLimit :
+// Executes at market and rests the remainder as a limit at the fill price.
+func (b *OrderBuilder) MarketToLimit() *OrderBuilder {
+	return b.orderType(OrderTypeMarketToLimit, nil, nil)
+}
+
+// MarketWithProtection :
+func (b *OrderBuilder) MarketWithProtection() *OrderBuilder {
+	return b.orderType(OrderTypeMarketWithProtection, nil, nil)
+}
+
+// MarketOnClose :
+func (b *OrderBuilder) MarketOnClose() *OrderBuilder {
+	return b.orderType(OrderTypeMarketOnClose, nil, nil)
+}
+
+// MarketIfTouched :
+// Sends a market order once the market touches trigger.
+func (b *OrderBuilder) MarketIfTouched(trigger Decimal) *OrderBuilder {
+	return b.orderType(OrderTypeMarketIfTouch, &trigger, nil)
+}
+
+// Limit :
+func (b *OrderBuilder) Limit(price Decimal) *OrderBuilder {
+	return b.orderType(OrderTypeLimit, &price, nil)
+}
+
+// LimitOnClose :
+func (b *OrderBuilder) LimitOnClose(price Decimal) *OrderBuilder {
+	return b.orderType(OrderTypeLimitOnClose, &price, nil)
+}
+
+// LimitIfTouched :
+// Sends a limit order at price once the market touches trigger.
+func (b *OrderBuilder) LimitIfTouched(trigger, price Decimal) *OrderBuilder {
+	return b.orderType(OrderTypeLimitIfTouched, &price, &trigger)
+}
+
+// Stop :
+func (b *OrderBuilder) Stop(stop Decimal) *OrderBuilder {
+	return b.orderType(OrderTypeStop, &stop, nil)
+}
+
+// StopWithProtection :
+func (b *OrderBuilder) StopWithProtection(stop Decimal) *OrderBuilder {
+	return b.orderType(OrderTypeStopWithProtection, &stop, nil)
+}
+
+// StopLimit :
+// Sends a limit order at price once the market reaches stop.
+func (b *OrderBuilder) StopLimit(stop, price Decimal) *OrderBuilder {
+	return b.orderType(OrderTypeStopLimit, &price, &stop)
+}
+
+// TrailingStop :
+// Trails the market by amount in price, or in percent for
+// TrailingTypePercent.
+func (b *OrderBuilder) TrailingStop(amount Decimal, trailingType TrailingType) *OrderBuilder {
+	b.orderType(OrderTypeTrailing, nil, nil)
+	b.param.TrailingAmount = &amount
+	b.param.TrailingType = trailingType
+	return b
+}
+
+// TrailingStopLimit :
+// Like TrailingStop, sending a limit order limitOffset away from the stop
+// once it is hit.
+func (b *OrderBuilder) TrailingStopLimit(amount Decimal, trailingType TrailingType, limitOffset Decimal) *OrderBuilder {
+	b.orderType(OrderTypeTrailingLimit, nil, &limitOffset)
+	b.param.TrailingAmount = &amount
+	b.param.TrailingType = trailingType
+	return b
+}
+
+// Relative :
+// Pegs to the best bid for BUY or ask for SELL plus offset, never past
+// limit. A zero limit leaves the order uncapped.
+func (b *OrderBuilder) Relative(offset, limit Decimal) *OrderBuilder {
+	var price *Decimal
+	if !limit.IsZero() {
+		price = &limit
+	}
+	return b.orderType(OrderTypeRelative, price, &offset)
+}
+
+// PassiveRelative :
+// Pegs to the best ask for BUY or bid for SELL minus offset.
+func (b *OrderBuilder) PassiveRelative(offset Decimal) *OrderBuilder {
+	return b.orderType(OrderTypePassiveRelative, nil, &offset)
+}
+
+// PeggedToMarket :
+func (b *OrderBuilder) PeggedToMarket(offset Decimal) *OrderBuilder {
+	return b.orderType(OrderTypePeggedToMarket, nil, &offset)
+}
+
+// PeggedToStock :
+// The Web API takes no delta or reference prices, the defaults of the
+// account apply.
+func (b *OrderBuilder) PeggedToStock() *OrderBuilder {
+	return b.orderType(OrderTypePeggedToStock, nil, nil)
+}
+
+// PeggedToBenchmark :
+// The Web API takes no benchmark, the defaults of the account apply.
+func (b *OrderBuilder) PeggedToBenchmark() *OrderBuilder {
+	return b.orderType(OrderTypePeggedToBenchmark, nil, nil)
+}
+
+// BoxTop :
+func (b *OrderBuilder) BoxTop() *OrderBuilder {
+	return b.orderType(OrderTypeBoxTop, nil, nil)
+}
+
+// RelativeLimitCombo :
+func (b *OrderBuilder) RelativeLimitCombo(price Decimal) *OrderBuilder {
+	return b.orderType(OrderTypeRelativeLimitCombo, &price, nil)
+}
+
+// RelativeMarketCombo :
+func (b *OrderBuilder) RelativeMarketCombo() *OrderBuilder {
+	return b.orderType(OrderTypeRelativeMarketCombo, nil, nil)
+}
+
+// Algo :
+// Routes the order through an IB algorithm, replacing a previous one.
+func (b *OrderBuilder) Algo(params AlgoParams) *OrderBuilder {
+	b.param.Strategy = string(params.Algorithm())
+	b.param.StrategyParameters = params.Parameters()
+	return b
+}
+
+// TimeInForce :
+func (b *OrderBuilder) TimeInForce(tif TimeInForce) *OrderBuilder {
+	b.param.TimeInForce = tif
+	return b
+}
+
+// Day :
+func (b *OrderBuilder) Day() *OrderBuilder {
+	return b.TimeInForce(TimeInForceDAY)
+}
+
+// GTC :
+func (b *OrderBuilder) GTC() *OrderBuilder {
+	return b.TimeInForce(TimeInForceGTC)
+}
+
+// IOC :
+func (b *OrderBuilder) IOC() *OrderBuilder {
+	return b.TimeInForce(TimeInForceIOC)
+}
+
+// FOK :
+func (b *OrderBuilder) FOK() *OrderBuilder {
+	return b.TimeInForce(TimeInForceFOK)
+}
+
+// OPG :
+func (b *OrderBuilder) OPG() *OrderBuilder {
+	return b.TimeInForce(TimeInForceOPG)
+}
+
+// OutsideRTH :
+func (b *OrderBuilder) OutsideRTH() *OrderBuilder {
+	b.param.OutsideRegularTradingHours = true
+	return b
+}
+
+// AllOrNone :
+func (b *OrderBuilder) AllOrNone() *OrderBuilder {
+	b.param.AllOrNone = true
+	return b
+}
+
+// CustomOrderId :
+// Sets the cOID, unique per account and day.
+func (b *OrderBuilder) CustomOrderId(customOrderId string) *OrderBuilder {
+	b.param.CustomOrderId = customOrderId
+	return b
+}
+
+// ParentId :
+// Attaches the order to the order whose cOID is parentId.
+func (b *OrderBuilder) ParentId(parentId string) *OrderBuilder {
+	b.param.ParentId = parentId
+	return b
+}
+
+// ListingExchange :
+func (b *OrderBuilder) ListingExchange(exchange string) *OrderBuilder {
+	b.param.ListingExchange = exchange
+	return b
+}
+
+// Ticker :
+func (b *OrderBuilder) Ticker(ticker string) *OrderBuilder {
+	b.param.Ticker = ticker
+	return b
+}
+
+// Referrer :
+func (b *OrderBuilder) Referrer(referrer string) *OrderBuilder {
+	b.param.Referrer = referrer
+	return b
+}
+
+// RoundPrices :
+// Rounds the prices set so far to the increments of rounder, with last the
+// current price of the contract, see PriceRounder.RoundOrder.
+func (b *OrderBuilder) RoundPrices(rounder *PriceRounder, last Decimal) *OrderBuilder {
+	rounder.RoundOrder(&b.param, last)
+	return b
+}
+
+// Build :
+// Returns the order, or an *OrderValidationError if it has no side, order
+// type or positive size, or uses an algorithm with an order type other than
+// MKT and LMT.
+func (b *OrderBuilder) Build() (PlaceOrderParam, error) {
+	e := &OrderValidationError{}
+	if b.param.Side == "" {
+		e.add("side", "call Buy, Sell, BuyCash or SellCash")
+	}
+	if b.param.OrderType == "" {
+		e.add("orderType", "call an order type method like Market or Limit")
+	}
+	if b.param.CashQty != nil && b.param.CashQty.Sign() <= 0 {
+		e.add("cashQty", "must be positive")
+	} else if b.param.CashQty == nil && b.param.Quantity.Sign() <= 0 {
+		e.add("quantity", "must be positive")
+	}
+	if b.param.Strategy != "" && b.param.OrderType != OrderTypeMarket && b.param.OrderType != OrderTypeLimit {
+		e.add("strategy", "%s needs a MKT or LMT order, not %s", b.param.Strategy, b.param.OrderType)
+	}
+	if len(e.Violations) > 0 {
+		return PlaceOrderParam{}, e
+	}
+	return b.clone(), nil
+}
+
+// BuildFor :
+// Like Build, then checks the order against the rules of its contract with
+// ValidateOrder.
+func (b *OrderBuilder) BuildFor(rules *ContractRules) (PlaceOrderParam, error) {
+	param, err := b.Build()
+	if err != nil {
+		return PlaceOrderParam{}, err
+	}
+	if err := ValidateOrder(param, rules); err != nil {
+		return PlaceOrderParam{}, err
+	}
+	return param, nil
+}
+
+// clone :
+// Copies the pointers and the parameters, so later calls on the builder do
+// not change orders it built.
+func (b *OrderBuilder) clone() PlaceOrderParam {
+	param := b.param
+	for _, field := range []**Decimal{&param.Price, &param.AuxPrice, &param.TrailingAmount, &param.CashQty} {
+		if *field != nil {
+			value := **field
+			*field = &value
+		}
+	}
+	contractId := *param.ContractId
+	param.ContractId = &contractId
+	if param.StrategyParameters != nil {
+		parameters := make(map[string]interface{}, len(param.StrategyParameters))
+		for name, value := range param.StrategyParameters {
+			parameters[name] = value
+		}
+		param.StrategyParameters = parameters
+	}
+	return param
+}
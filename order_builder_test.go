@@ -0,0 +1,189 @@
+package ibkr_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+)
+
+// prices returns the price fields of param as strings, "" for nil.
+func prices(param ibkr.PlaceOrderParam) [3]string {
+	var fields [3]string
+	for i, value := range []*ibkr.Decimal{param.Price, param.AuxPrice, param.TrailingAmount} {
+		if value != nil {
+			fields[i] = value.String()
+		}
+	}
+	return fields
+}
+
+func TestOrderBuilderOrderTypes(t *testing.T) {
+	tests := []struct {
+		name      string
+		build     func(*ibkr.OrderBuilder) *ibkr.OrderBuilder
+		orderType ibkr.OrderType
+		// prices are Price, AuxPrice and TrailingAmount
+		prices [3]string
+	}{
+		{"market", (*ibkr.OrderBuilder).Market, ibkr.OrderTypeMarket, [3]string{}},
+		{"limit", func(b *ibkr.OrderBuilder) *ibkr.OrderBuilder { return b.Limit(d("187.25")) }, ibkr.OrderTypeLimit, [3]string{"187.25", "", ""}},
+		{"stop", func(b *ibkr.OrderBuilder) *ibkr.OrderBuilder { return b.Stop(d("180")) }, ibkr.OrderTypeStop, [3]string{"180", "", ""}},
+		{"stop limit", func(b *ibkr.OrderBuilder) *ibkr.OrderBuilder { return b.StopLimit(d("180"), d("179.5")) }, ibkr.OrderTypeStopLimit, [3]string{"179.5", "180", ""}},
+		{"market if touched", func(b *ibkr.OrderBuilder) *ibkr.OrderBuilder { return b.MarketIfTouched(d("185")) }, ibkr.OrderTypeMarketIfTouch, [3]string{"185", "", ""}},
+		{"limit if touched", func(b *ibkr.OrderBuilder) *ibkr.OrderBuilder { return b.LimitIfTouched(d("185"), d("185.5")) }, ibkr.OrderTypeLimitIfTouched, [3]string{"185.5", "185", ""}},
+		{"trailing stop", func(b *ibkr.OrderBuilder) *ibkr.OrderBuilder {
+			return b.TrailingStop(d("1.5"), ibkr.TrailingTypeAmount)
+		}, ibkr.OrderTypeTrailing, [3]string{"", "", "1.5"}},
+		{"trailing stop limit", func(b *ibkr.OrderBuilder) *ibkr.OrderBuilder {
+			return b.TrailingStopLimit(d("1.5"), ibkr.TrailingTypeAmount, d("0.1"))
+		}, ibkr.OrderTypeTrailingLimit, [3]string{"", "0.1", "1.5"}},
+		{"relative", func(b *ibkr.OrderBuilder) *ibkr.OrderBuilder { return b.Relative(d("0.01"), d("190")) }, ibkr.OrderTypeRelative, [3]string{"190", "0.01", ""}},
+		{"relative uncapped", func(b *ibkr.OrderBuilder) *ibkr.OrderBuilder { return b.Relative(d("0.01"), ibkr.Decimal{}) }, ibkr.OrderTypeRelative, [3]string{"", "0.01", ""}},
+		{"pegged to market", func(b *ibkr.OrderBuilder) *ibkr.OrderBuilder { return b.PeggedToMarket(ibkr.Decimal{}) }, ibkr.OrderTypePeggedToMarket, [3]string{"", "0", ""}},
+		{"last type wins", func(b *ibkr.OrderBuilder) *ibkr.OrderBuilder {
+			return b.TrailingStop(d("1"), ibkr.TrailingTypeAmount).Limit(d("187"))
+		}, ibkr.OrderTypeLimit, [3]string{"187", "", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			param, err := tt.build(ibkr.NewOrder("DU123", 265598).Buy(d("100"))).Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if param.OrderType != tt.orderType || prices(param) != tt.prices {
+				t.Errorf("got %s %v, want %s %v", param.OrderType, prices(param), tt.orderType, tt.prices)
+			}
+			if param.TimeInForce != ibkr.TimeInForceDAY {
+				t.Errorf("time in force %s, want DAY", param.TimeInForce)
+			}
+		})
+	}
+}
+
+func TestOrderBuilderBuild(t *testing.T) {
+	tests := []struct {
+		name  string
+		build *ibkr.OrderBuilder
+		want  []string
+	}{
+		{"complete", ibkr.NewOrder("DU123", 265598).Sell(d("1")).Market(), nil},
+		{"cash", ibkr.NewOrder("DU123", 265598).BuyCash(d("1000")).Market(), nil},
+		{"empty", ibkr.NewOrder("DU123", 265598), []string{"side", "orderType", "quantity"}},
+		{"cash replaces quantity", ibkr.NewOrder("DU123", 265598).Buy(d("1")).BuyCash(ibkr.Decimal{}).Market(), []string{"cashQty"}},
+		{"quantity replaces cash", ibkr.NewOrder("DU123", 265598).BuyCash(d("1000")).Buy(ibkr.Decimal{}).Market(), []string{"quantity"}},
+		{"algo needs MKT or LMT", ibkr.NewOrder("DU123", 265598).Buy(d("1")).Stop(d("180")).Algo(ibkr.VWAPParams{}), []string{"strategy"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.build.Build()
+			if got := violations(t, err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("violations %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderBuilderBuildForOffsets(t *testing.T) {
+	rules := stockContractRules()
+	tests := []struct {
+		name  string
+		build func(*ibkr.OrderBuilder) *ibkr.OrderBuilder
+		want  []string
+	}{
+		{"pegged to market without offset", func(b *ibkr.OrderBuilder) *ibkr.OrderBuilder { return b.PeggedToMarket(ibkr.Decimal{}) }, nil},
+		{"relative without offset", func(b *ibkr.OrderBuilder) *ibkr.OrderBuilder { return b.Relative(ibkr.Decimal{}, d("190")) }, nil},
+		{"passive relative", func(b *ibkr.OrderBuilder) *ibkr.OrderBuilder { return b.PassiveRelative(d("0.0005")) }, nil},
+		{"negative offset", func(b *ibkr.OrderBuilder) *ibkr.OrderBuilder { return b.PeggedToMarket(d("-0.01")) }, []string{"auxPrice"}},
+		{"offset off the band of the limit", func(b *ibkr.OrderBuilder) *ibkr.OrderBuilder { return b.Relative(d("0.005"), d("190")) }, []string{"auxPrice"}},
+		{"trailing limit offset", func(b *ibkr.OrderBuilder) *ibkr.OrderBuilder {
+			return b.TrailingStopLimit(d("1"), ibkr.TrailingTypeAmount, ibkr.Decimal{})
+		}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.build(ibkr.NewOrder("DU123", 265598).Buy(d("100"))).BuildFor(&rules)
+			if got := violations(t, err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("violations %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderBuilderRoundPrices(t *testing.T) {
+	tests := []struct {
+		name   string
+		build  func(*ibkr.OrderBuilder) *ibkr.OrderBuilder
+		prices [3]string
+	}{
+		{"relative offset at the band of last", func(b *ibkr.OrderBuilder) *ibkr.OrderBuilder { return b.Relative(d("0.0123"), d("190.019")) }, [3]string{"190.01", "0.01", ""}},
+		{"pegged to market zero offset", func(b *ibkr.OrderBuilder) *ibkr.OrderBuilder { return b.PeggedToMarket(ibkr.Decimal{}) }, [3]string{"", "0", ""}},
+		{"passive relative", func(b *ibkr.OrderBuilder) *ibkr.OrderBuilder { return b.PassiveRelative(d("0.026")) }, [3]string{"", "0.03", ""}},
+		{"trailing limit", func(b *ibkr.OrderBuilder) *ibkr.OrderBuilder {
+			return b.TrailingStopLimit(d("1.234"), ibkr.TrailingTypeAmount, d("0.051"))
+		}, [3]string{"", "0.05", "1.23"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			param, err := tt.build(ibkr.NewOrder("DU123", 265598).Buy(d("100"))).RoundPrices(stockRounder(), d("187")).Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := prices(param); got != tt.prices {
+				t.Errorf("got %v, want %v", got, tt.prices)
+			}
+		})
+	}
+}
+
+func TestOrderBuilderAlgo(t *testing.T) {
+	tests := []struct {
+		name       string
+		params     ibkr.AlgoParams
+		strategy   ibkr.IBAlgorithm
+		parameters map[string]interface{}
+	}{
+		{"adaptive", ibkr.AdaptiveParams{Priority: ibkr.AdaptivePriorityPatient}, ibkr.IBAlgorithmAdaptive, map[string]interface{}{"adaptivePriority": "Patient"}},
+		{"adaptive default", ibkr.AdaptiveParams{}, "Adaptive", map[string]interface{}{}},
+		{"vwap", ibkr.VWAPParams{MaxPctVol: d("0.1"), NoTakeLiq: true}, "Vwap", map[string]interface{}{"maxPctVol": d("0.1"), "noTakeLiq": true}},
+		{"accumulate distribute", ibkr.AccumulateDistributeParams{ComponentSize: 100, CatchUp: true}, "AD", map[string]interface{}{"componentSize": 100, "catchUp": true}},
+		{"accumulate distribute alt", ibkr.AccumulateDistributeAltParams{ComponentSize: 100, TimeBetweenOrders: 60}, "AccuDistr", map[string]interface{}{"componentSize": 100, "timeBetweenOrders": 60}},
+		{"price variant", ibkr.PriceVariantPercentageParams{PctVol: d("0.1")}, ibkr.IBAlgorithmPricePriceVariantPercentage, map[string]interface{}{"pctVol": d("0.1")}},
+		{"midprice", ibkr.MiddlePriceParams{}, "MIDPRICE", map[string]interface{}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			param, err := ibkr.NewOrder("DU123", 265598).Buy(d("100")).Limit(d("187")).Algo(tt.params).Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if param.Strategy != string(tt.strategy) || !reflect.DeepEqual(param.StrategyParameters, tt.parameters) {
+				t.Errorf("got %s %v, want %s %v", param.Strategy, param.StrategyParameters, tt.strategy, tt.parameters)
+			}
+		})
+	}
+}
+
+func TestOrderBuilderBuildCopies(t *testing.T) {
+	builder := ibkr.NewOrder("DU123", 265598).Buy(d("100")).Limit(d("187")).Algo(ibkr.AdaptiveParams{Priority: ibkr.AdaptivePriorityNormal})
+	first, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder.RoundPrices(ibkr.NewPriceRounder(&ibkr.ContractRules{Increment: 5}), d("187"))
+	first.StrategyParameters["adaptivePriority"] = "Urgent"
+	second, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Price.String() != "187" || second.Price.String() != "185" {
+		t.Errorf("prices %s and %s, want 187 and 185", first.Price, second.Price)
+	}
+	if second.StrategyParameters["adaptivePriority"] != "Normal" {
+		t.Errorf("changing a built order changed the builder: %v", second.StrategyParameters)
+	}
+	if _, err := builder.BuildFor(nil); !errors.As(err, new(*ibkr.OrderValidationError)) {
+		t.Errorf("BuildFor(nil) got %v", err)
+	}
+}
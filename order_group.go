@@ -0,0 +1,213 @@
+package ibkr
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// OrderLegRole :
+// The part an order plays in an OrderGroup, also the suffix of its cOID.
+type OrderLegRole string
+
+const (
+	OrderLegRoleEntry        = OrderLegRole("entry")
+	OrderLegRoleTakeProfit   = OrderLegRole("tp")
+	OrderLegRoleStopLoss     = OrderLegRole("sl")
+	OrderLegRoleTrailingStop = OrderLegRole("trail")
+	OrderLegRoleOCA          = OrderLegRole("oca")
+)
+
+// ErrOrderGroupResults :
+// Returned by OrderGroup.Results when the reply does not match the legs.
+var ErrOrderGroupResults = errors.New("order results do not match the order group")
+
+// OrderGroup :
+// Linked orders to submit in a single PlaceOrder call:
+//
+//	group, err := ibkr.NewBracket(entry, takeProfit, stopLoss)
+//	resp, err := client.Service().Order().PlaceOrder(group.Orders)
+//	// confirm resp.AlternateResults with PlaceOrderReplyConfirmation
+//	legs, err := group.Results(*resp.NormalResults)
+//
+// Every leg has a cOID made of the group Id and its role, e.g. "a1b2c3d4-sl";
+// the entry of a bracket keeps a cOID it already has and lends it to the Id.
+type OrderGroup struct {
+	Id     string
+	Orders []PlaceOrderParam
+	Roles  []OrderLegRole
+}
+
+// OrderLegResult :
+// The reply for one leg of an OrderGroup.
+type OrderLegResult struct {
+	Role          OrderLegRole
+	CustomOrderId string
+	OrderId       string
+	OrderStatus   OrderStatus
+}
+
+// NewBracket :
+// Links takeProfit and stopLoss to entry as children. They must close
+// entry: same account and contract, opposite side, and the entry quantity
+// if they have none. The gateway submits the children once entry fills and
+// cancels the one left when the other fills.
+func NewBracket(entry, takeProfit, stopLoss PlaceOrderParam) (*OrderGroup, error) {
+	group := newOrderGroup(entry.CustomOrderId)
+	if err := group.addEntry(entry); err != nil {
+		return nil, err
+	}
+	if err := group.addChild(OrderLegRoleTakeProfit, takeProfit); err != nil {
+		return nil, err
+	}
+	if err := group.addChild(OrderLegRoleStopLoss, stopLoss); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// NewAttachedTrailingStop :
+// Attaches a TRAIL order closing entry once it fills, trailing by amount in
+// price or in percent for TrailingTypePercent.
+func NewAttachedTrailingStop(entry PlaceOrderParam, amount Decimal, trailingType TrailingType) (*OrderGroup, error) {
+	group := newOrderGroup(entry.CustomOrderId)
+	if err := group.addEntry(entry); err != nil {
+		return nil, err
+	}
+	trailingStop := PlaceOrderParam{
+		OrderType:                  OrderTypeTrailing,
+		TrailingAmount:             &amount,
+		TrailingType:               trailingType,
+		TimeInForce:                TimeInForceGTC,
+		OutsideRegularTradingHours: entry.OutsideRegularTradingHours,
+	}
+	if err := group.addChild(OrderLegRoleTrailingStop, trailingStop); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// NewOCAGroup :
+// Makes orders one-cancels-all: the first execution of one cancels the
+// others. Their cOIDs end with their position, e.g. "a1b2c3d4-oca1".
+func NewOCAGroup(orders ...PlaceOrderParam) (*OrderGroup, error) {
+	if len(orders) < 2 {
+		return nil, errors.New("an OCA group needs at least two orders")
+	}
+	group := newOrderGroup("")
+	for n, order := range orders {
+		if order.AccountId != orders[0].AccountId {
+			return nil, fmt.Errorf("OCA order %d: account %s differs from %s", n+1, order.AccountId, orders[0].AccountId)
+		}
+		if order.ParentId != "" {
+			return nil, fmt.Errorf("OCA order %d: has parent %s", n+1, order.ParentId)
+		}
+		order.IsSingleGroup = true
+		order.CustomOrderId = fmt.Sprintf("%s-%s%d", group.Id, OrderLegRoleOCA, n+1)
+		group.add(OrderLegRoleOCA, order)
+	}
+	return group, nil
+}
+
+func newOrderGroup(id string) *OrderGroup {
+	if id == "" {
+		var random [4]byte
+		_, _ = rand.Read(random[:])
+		id = hex.EncodeToString(random[:])
+	}
+	return &OrderGroup{Id: id}
+}
+
+func (g *OrderGroup) add(role OrderLegRole, order PlaceOrderParam) {
+	g.Orders = append(g.Orders, order)
+	g.Roles = append(g.Roles, role)
+}
+
+func (g *OrderGroup) addEntry(entry PlaceOrderParam) error {
+	if entry.ParentId != "" {
+		return fmt.Errorf("entry: has parent %s", entry.ParentId)
+	}
+	if entry.CustomOrderId == "" {
+		entry.CustomOrderId = g.Id + "-" + string(OrderLegRoleEntry)
+	}
+	g.add(OrderLegRoleEntry, entry)
+	return nil
+}
+
+// addChild :
+// Attaches child to the entry, filling in what it inherits.
+func (g *OrderGroup) addChild(role OrderLegRole, child PlaceOrderParam) error {
+	entry := g.Orders[0]
+	if child.AccountId == "" {
+		child.AccountId = entry.AccountId
+	}
+	if child.ContractId == nil && child.ContractIdExchange == "" {
+		child.ContractId, child.ContractIdExchange = entry.ContractId, entry.ContractIdExchange
+	}
+	if child.Side == "" {
+		child.Side = string(OrderSideSell)
+		if !isBuy(entry.Side) {
+			child.Side = string(OrderSideBuy)
+		}
+	}
+	if child.Quantity.IsZero() && child.CashQty == nil {
+		child.Quantity = entry.Quantity
+	}
+
+	switch {
+	case child.AccountId != entry.AccountId:
+		return fmt.Errorf("%s: account %s differs from the entry %s", role, child.AccountId, entry.AccountId)
+	case !sameContract(child, entry):
+		return fmt.Errorf("%s: contract differs from the entry", role)
+	case isBuy(child.Side) == isBuy(entry.Side):
+		return fmt.Errorf("%s: side %s must be opposite to the entry %s", role, child.Side, entry.Side)
+	}
+	child.ParentId = entry.CustomOrderId
+	child.CustomOrderId = g.Id + "-" + string(role)
+	g.add(role, child)
+	return nil
+}
+
+func sameContract(a, b PlaceOrderParam) bool {
+	if a.ContractId != nil && b.ContractId != nil {
+		return *a.ContractId == *b.ContractId
+	}
+	return a.ContractId == nil && b.ContractId == nil && strings.EqualFold(a.ContractIdExchange, b.ContractIdExchange)
+}
+
+// Results :
+// Pairs the results of PlaceOrder, or of the PlaceOrderReplyConfirmation
+// that placed the orders, with the legs. Results carrying local_order_id are
+// matched by cOID, the others by position.
+func (g *OrderGroup) Results(results []PlaceOrderNormalResult) ([]OrderLegResult, error) {
+	if len(results) != len(g.Orders) {
+		return nil, fmt.Errorf("%w: %d results for %d orders", ErrOrderGroupResults, len(results), len(g.Orders))
+	}
+	legs := make([]OrderLegResult, len(g.Orders))
+	for n, order := range g.Orders {
+		legs[n] = OrderLegResult{Role: g.Roles[n], CustomOrderId: order.CustomOrderId}
+	}
+	for n, result := range results {
+		index := n
+		if result.LocalOrderId != "" {
+			index = g.index(result.LocalOrderId)
+			if index < 0 {
+				return nil, fmt.Errorf("%w: unknown cOID %s", ErrOrderGroupResults, result.LocalOrderId)
+			}
+		}
+		legs[index].OrderId = result.OrderId
+		legs[index].OrderStatus = result.OrderStatus
+	}
+	return legs, nil
+}
+
+func (g *OrderGroup) index(customOrderId string) int {
+	for n, order := range g.Orders {
+		if order.CustomOrderId == customOrderId {
+			return n
+		}
+	}
+	return -1
+}
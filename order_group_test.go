@@ -0,0 +1,210 @@
+package ibkr_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+	"github.com/dictxwang/go-ibkr/ibkrtest"
+)
+
+func entryOrder(customOrderId string) ibkr.PlaceOrderParam {
+	param, _ := ibkr.NewOrder("DU123", 265598).Buy(d("100")).Limit(d("187")).CustomOrderId(customOrderId).Build()
+	return param
+}
+
+func TestNewBracket(t *testing.T) {
+	takeProfit := ibkr.PlaceOrderParam{OrderType: ibkr.OrderTypeLimit, Price: decimalPtr("195")}
+	stopLoss := ibkr.PlaceOrderParam{OrderType: ibkr.OrderTypeStop, Price: decimalPtr("180")}
+
+	group, err := ibkr.NewBracket(entryOrder("mine"), takeProfit, stopLoss)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if group.Id != "mine" {
+		t.Errorf("id %s, want the cOID of the entry", group.Id)
+	}
+	wantRoles := []ibkr.OrderLegRole{ibkr.OrderLegRoleEntry, ibkr.OrderLegRoleTakeProfit, ibkr.OrderLegRoleStopLoss}
+	if !reflect.DeepEqual(group.Roles, wantRoles) {
+		t.Errorf("roles %v, want %v", group.Roles, wantRoles)
+	}
+	for n, want := range []struct{ customOrderId, parentId, side, quantity string }{
+		{"mine", "", "BUY", "100"},
+		{"mine-tp", "mine", "SELL", "100"},
+		{"mine-sl", "mine", "SELL", "100"},
+	} {
+		order := group.Orders[n]
+		if order.CustomOrderId != want.customOrderId || order.ParentId != want.parentId || order.Side != want.side || order.Quantity.String() != want.quantity {
+			t.Errorf("leg %d: cOID %s parent %s %s %s, want %+v", n, order.CustomOrderId, order.ParentId, order.Side, order.Quantity, want)
+		}
+		if order.AccountId != "DU123" || *order.ContractId != 265598 {
+			t.Errorf("leg %d: account %s conid %d", n, order.AccountId, *order.ContractId)
+		}
+	}
+
+	generated, err := ibkr.NewBracket(entryOrder(""), takeProfit, stopLoss)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(generated.Id) != 8 || generated.Orders[0].CustomOrderId != generated.Id+"-entry" || generated.Orders[2].ParentId != generated.Id+"-entry" {
+		t.Errorf("generated id %q, cOIDs %s and parent %s", generated.Id, generated.Orders[0].CustomOrderId, generated.Orders[2].ParentId)
+	}
+}
+
+func TestNewBracketErrors(t *testing.T) {
+	other := 8314
+	tests := []struct {
+		name       string
+		entry      func(*ibkr.PlaceOrderParam)
+		takeProfit ibkr.PlaceOrderParam
+	}{
+		{"entry with parent", func(p *ibkr.PlaceOrderParam) { p.ParentId = "other" }, ibkr.PlaceOrderParam{}},
+		{"same side", nil, ibkr.PlaceOrderParam{Side: "buy"}},
+		{"other account", nil, ibkr.PlaceOrderParam{AccountId: "DU999"}},
+		{"other contract", nil, ibkr.PlaceOrderParam{ContractId: &other}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := entryOrder("")
+			if tt.entry != nil {
+				tt.entry(&entry)
+			}
+			if _, err := ibkr.NewBracket(entry, tt.takeProfit, ibkr.PlaceOrderParam{}); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestNewAttachedTrailingStop(t *testing.T) {
+	group, err := ibkr.NewAttachedTrailingStop(entryOrder("e"), d("1.5"), ibkr.TrailingTypeAmount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trailing := group.Orders[1]
+	if trailing.OrderType != ibkr.OrderTypeTrailing || trailing.TrailingAmount.String() != "1.5" || trailing.ParentId != "e" ||
+		trailing.CustomOrderId != "e-trail" || trailing.Side != "SELL" || trailing.TimeInForce != ibkr.TimeInForceGTC {
+		t.Errorf("trailing stop %+v", trailing)
+	}
+}
+
+func TestNewOCAGroup(t *testing.T) {
+	if _, err := ibkr.NewOCAGroup(entryOrder("")); err == nil {
+		t.Error("a single order must not form an OCA group")
+	}
+	other := entryOrder("")
+	other.AccountId = "DU999"
+	if _, err := ibkr.NewOCAGroup(entryOrder(""), other); err == nil {
+		t.Error("orders of different accounts must not form an OCA group")
+	}
+
+	group, err := ibkr.NewOCAGroup(entryOrder("a"), entryOrder("b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n, want := range []string{group.Id + "-oca1", group.Id + "-oca2"} {
+		if order := group.Orders[n]; !order.IsSingleGroup || order.CustomOrderId != want {
+			t.Errorf("order %d: single group %v cOID %s", n, order.IsSingleGroup, order.CustomOrderId)
+		}
+	}
+}
+
+func TestOrderGroupResults(t *testing.T) {
+	group, err := ibkr.NewBracket(entryOrder("g"), ibkr.PlaceOrderParam{OrderType: ibkr.OrderTypeLimit, Price: decimalPtr("195")}, ibkr.PlaceOrderParam{OrderType: ibkr.OrderTypeStop, Price: decimalPtr("180")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		results []ibkr.PlaceOrderNormalResult
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "by position",
+			results: []ibkr.PlaceOrderNormalResult{{OrderId: "1"}, {OrderId: "2"}, {OrderId: "3"}},
+			want:    []string{"1", "2", "3"},
+		},
+		{
+			name:    "by cOID",
+			results: []ibkr.PlaceOrderNormalResult{{OrderId: "3", LocalOrderId: "g-sl"}, {OrderId: "1", LocalOrderId: "g"}, {OrderId: "2", LocalOrderId: "g-tp"}},
+			want:    []string{"1", "2", "3"},
+		},
+		{
+			name:    "too few",
+			results: []ibkr.PlaceOrderNormalResult{{OrderId: "1"}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown cOID",
+			results: []ibkr.PlaceOrderNormalResult{{OrderId: "1"}, {OrderId: "2"}, {OrderId: "3", LocalOrderId: "other"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			legs, err := group.Results(tt.results)
+			if tt.wantErr {
+				if !errors.Is(err, ibkr.ErrOrderGroupResults) {
+					t.Errorf("got %v, want ErrOrderGroupResults", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var orderIds []string
+			for n, leg := range legs {
+				orderIds = append(orderIds, leg.OrderId)
+				if leg.Role != group.Roles[n] || leg.CustomOrderId != group.Orders[n].CustomOrderId {
+					t.Errorf("leg %d: %+v", n, leg)
+				}
+			}
+			if !reflect.DeepEqual(orderIds, tt.want) {
+				t.Errorf("order ids %v, want %v", orderIds, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderGroupResultsAfterConfirmation(t *testing.T) {
+	server := ibkrtest.NewServer(t)
+	server.QueueOrderReplies(ibkrtest.OrderReplyChain{
+		Prompts: []ibkr.PlaceOrderAlternateResult{{Id: "reply-1", Message: []string{"Are you sure?"}}},
+		Results: []ibkr.PlaceOrderNormalResult{
+			{OrderId: "12", LocalOrderId: "g-tp", OrderStatus: ibkr.OrderStatusPreSubmitted},
+			{OrderId: "11", LocalOrderId: "g", OrderStatus: ibkr.OrderStatusSubmitted},
+			{OrderId: "13", LocalOrderId: "g-sl", OrderStatus: ibkr.OrderStatusPreSubmitted},
+		},
+	})
+	group, err := ibkr.NewBracket(entryOrder("g"), ibkr.PlaceOrderParam{OrderType: ibkr.OrderTypeLimit, Price: decimalPtr("195")}, ibkr.PlaceOrderParam{OrderType: ibkr.OrderTypeStop, Price: decimalPtr("180")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := server.Client().Service().Order()
+
+	resp, err := service.PlaceOrder(group.Orders)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.AlternateResults == nil || len(*resp.AlternateResults) != 1 {
+		t.Fatalf("got %+v, want a prompt", resp)
+	}
+	confirmed, err := service.PlaceOrderReplyConfirmation(ibkr.PlaceOrderReplyConfirmationParam{ReplyId: (*resp.AlternateResults)[0].Id, Confirmed: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	legs, err := group.Results(confirmed.NormalResults)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ibkr.OrderLegResult{
+		{Role: ibkr.OrderLegRoleEntry, CustomOrderId: "g", OrderId: "11", OrderStatus: ibkr.OrderStatusSubmitted},
+		{Role: ibkr.OrderLegRoleTakeProfit, CustomOrderId: "g-tp", OrderId: "12", OrderStatus: ibkr.OrderStatusPreSubmitted},
+		{Role: ibkr.OrderLegRoleStopLoss, CustomOrderId: "g-sl", OrderId: "13", OrderStatus: ibkr.OrderStatusPreSubmitted},
+	}
+	if !reflect.DeepEqual(legs, want) {
+		t.Errorf("legs %+v, want %+v", legs, want)
+	}
+}
@@ -24,7 +24,7 @@ func (s *OrderMonitoringService) GetStatus(orderId int) (*OrderStatusItem, error
 
 	urlParam := url.Values{}
 
-	if err := s.client.getPublic(fmt.Sprintf("/iserver/account/order/status/%d", orderId), urlParam, &res); err != nil {
+	if err := s.client.getPublic(newOperation(OperationNameGetOrderStatus, "/iserver/account/order/status/{orderId}", orderId), urlParam, &res); err != nil {
 		return nil, err
 	}
 
@@ -41,7 +41,7 @@ func (s *OrderMonitoringService) GetTrades(param GetTradesParam) (*[]TradeItem,
 	if param.Days != nil {
 		urlParam.Add("days", fmt.Sprintf("%d", *param.Days))
 	}
-	if err := s.client.getPublic("/iserver/account/trades", urlParam, &res); err != nil {
+	if err := s.client.getPublic(newOperation(OperationNameGetTrades, "/iserver/account/trades"), urlParam, &res); err != nil {
 		return nil, err
 	}
 
@@ -64,7 +64,7 @@ func (s *OrderMonitoringService) GetLiveOrders(param GetLiveOrdersParam) (*GetLi
 	}
 	urlParam.Add("force", fmt.Sprintf("%t", param.Force))
 
-	if err := s.client.getPublic("/iserver/account/orders", urlParam, &res); err != nil {
+	if err := s.client.getPublic(newOperation(OperationNameGetLiveOrders, "/iserver/account/orders"), urlParam, &res); err != nil {
 		return nil, err
 	}
 
@@ -81,12 +81,12 @@ type OrderStatusItem struct {
 	ContractDescription1         string  `json:"contract_description_1,omitempty"`
 	ListingExchange              string  `json:"listing_exchange,omitempty"`
 	CompanyName                  string  `json:"company_name,omitempty"`
-	Size                         float64 `json:"size,omitempty"`
-	TotalSize                    float64 `json:"total_size,omitempty"`
+	Size                         Decimal `json:"size,omitzero"`
+	TotalSize                    Decimal `json:"total_size,omitzero"`
 	Currency                     string  `json:"currency,omitempty"`
 	AccountId                    string  `json:"account,omitempty"`
 	OrderType                    string  `json:"order_type,omitempty"`
-	CumulativeFill               string  `json:"cum_fill,omitempty"`
+	CumulativeFill               Decimal `json:"cum_fill,omitzero"`
 	OrderStatus                  string  `json:"order_status,omitempty"`
 	OrderCcpStatus               string  `json:"order_ccp_status,omitempty"`
 	OrderStatusDescription       string  `json:"order_status_description,omitempty"`
@@ -106,7 +106,7 @@ type OrderStatusItem struct {
 	SizeAndFills                 string  `json:"size_and_fills,omitempty"`
 	ExitStrategyDisplayPrice     string  `json:"exit_strategy_display_price,omitempty"`
 	ExitStrategyChartDescription string  `json:"exit_strategy_chart_description,omitempty"`
-	AveragePrice                 string  `json:"average_price,omitempty"`
+	AveragePrice                 Decimal `json:"average_price,omitzero"`
 	AllowedDuplicateOpposite     string  `json:"allowed_duplicate_opposite,omitempty"`
 	OrderTime                    string  `json:"order_time,omitempty"`
 }
@@ -126,12 +126,12 @@ type TradeItem struct {
 	OrderRef             string  `json:"order_ref,omitempty"`
 	TradeTime            string  `json:"trade_time,omitempty"`
 	TradeTimeR           int64   `json:"trade_time_r,omitempty"`
-	Size                 float64 `json:"size,omitempty"`
-	Price                string  `json:"price,omitempty"`
+	Size                 Decimal `json:"size,omitzero"`
+	Price                Decimal `json:"price,omitzero"`
 	Submitter            string  `json:"submitter,omitempty"`
 	Exchange             string  `json:"exchange,omitempty"`
-	Commission           string  `json:"commission,omitempty"`
-	NetAmount            float64 `json:"net_amount,omitempty"`
+	Commission           Decimal `json:"commission,omitzero"`
+	NetAmount            Decimal `json:"net_amount,omitzero"`
 	Account              string  `json:"account,omitempty"`
 	AccountCode          string  `json:"accountCode,omitempty"`
 	CompanyName          string  `json:"company_name,omitempty"`
@@ -164,8 +164,8 @@ type LiveOrderItem struct {
 	Ticker             string  `json:"ticker,omitempty"`
 	SecurityType       string  `json:"secType,omitempty"`
 	ListingExchange    string  `json:"listingExchange,omitempty"`
-	RemainingQuantity  float64 `json:"remainingQuantity,omitempty"`
-	FilledQuantity     float64 `json:"filledQuantity,omitempty"`
+	RemainingQuantity  Decimal `json:"remainingQuantity,omitzero"`
+	FilledQuantity     Decimal `json:"filledQuantity,omitzero"`
 	CompanyName        string  `json:"companyName,omitempty"`
 	Status             string  `json:"status,omitempty"`
 	OrderCcpStatus     string  `json:"order_ccp_status,omitempty"`
@@ -180,7 +180,7 @@ type LiveOrderItem struct {
 	TimeInForce        string  `json:"timeInForce,omitempty"`
 
 	Side         string  `json:"side,omitempty"`
-	AveragePrice string `json:"avgPrice,omitempty"`
+	AveragePrice Decimal `json:"avgPrice,omitzero"`
 }
 
 type GetLiveOrdersResponse struct {
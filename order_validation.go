@@ -0,0 +1,216 @@
+package ibkr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ruleOrderTypes :
+// The names of order types in ContractRules.OrderTypes, OrderTypesOutside,
+// FraqTypes and CqtTypes.
+var ruleOrderTypes = map[OrderType]string{
+	OrderTypeMarket:         "market",
+	OrderTypeLimit:          "limit",
+	OrderTypeStop:           "stop",
+	OrderTypeStopLimit:      "stop_limit",
+	OrderTypeMarketIfTouch:  "mit",
+	OrderTypeLimitIfTouched: "lit",
+	OrderTypeRelative:       "relative",
+	OrderTypeMarketOnClose:  "marketonclose",
+	OrderTypeLimitOnClose:   "limitonclose",
+	OrderTypeTrailing:       "trailing_stop",
+	OrderTypeTrailingLimit:  "trailing_stop_limit",
+}
+
+// OrderViolation :
+// One broken rule. Field is the JSON name of the offending field of
+// PlaceOrderParam.
+type OrderViolation struct {
+	Field   string
+	Message string
+}
+
+// OrderValidationError :
+// Every violation found by ValidateOrder.
+type OrderValidationError struct {
+	Violations []OrderViolation
+}
+
+func (e *OrderValidationError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		messages = append(messages, violation.Field+": "+violation.Message)
+	}
+	return "order violates contract rules: " + strings.Join(messages, "; ")
+}
+
+func (e *OrderValidationError) add(field, format string, args ...interface{}) {
+	e.Violations = append(e.Violations, OrderViolation{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// ValidateOrder :
+// Checks param against the rules of its contract before PlaceOrder and
+// returns an *OrderValidationError listing every violation, or nil.
+//
+// Order types unknown to the rules, like PEG MKT, are only checked for their
+// prices. A whole quantity must be a multiple of SizeIncrement; a fractional
+// one needs an order type listed in FraqTypes. A cash quantity needs an
+// order type listed in CqtTypes and a multiple of CashQtyIncr. Nil rules are
+// a violation of conid, as there is nothing to check against.
+func ValidateOrder(param PlaceOrderParam, rules *ContractRules) error {
+	e := &OrderValidationError{}
+	if rules == nil {
+		e.add("conid", "no contract rules to validate against")
+		return e
+	}
+	if rules.Error != nil && *rules.Error != "" {
+		e.add("conid", "%s", *rules.Error)
+	}
+	if param.ContractId == nil && param.ContractIdExchange == "" {
+		e.add("conid", "is required")
+	}
+	if len(rules.CanTradeAcctIds) > 0 && !containsFold(rules.CanTradeAcctIds, param.AccountId) {
+		e.add("acctId", "account %s can not trade this contract", param.AccountId)
+	}
+	if !isBuy(param.Side) && !strings.EqualFold(param.Side, string(OrderSideSell)) {
+		e.add("side", "must be BUY or SELL, not %q", param.Side)
+	}
+
+	ruleType, known := ruleOrderTypes[param.OrderType]
+	switch {
+	case param.OrderType == "":
+		e.add("orderType", "is required")
+	case known && !containsFold(rules.OrderTypes, ruleType):
+		e.add("orderType", "%s is not allowed, allowed: %s", param.OrderType, strings.Join(rules.OrderTypes, ", "))
+	case known && param.OutsideRegularTradingHours && !containsFold(rules.OrderTypesOutside, ruleType):
+		e.add("outsideRTH", "%s is not allowed outside regular trading hours", param.OrderType)
+	}
+	if len(rules.TifTypes) > 0 && param.TimeInForce != "" && !containsTimeInForce(rules.TifTypes, param.TimeInForce) {
+		e.add("tif", "%s is not allowed", param.TimeInForce)
+	}
+
+	validateQuantity(e, param, rules, ruleType)
+	validatePrices(e, param, rules)
+
+	if len(e.Violations) > 0 {
+		return e
+	}
+	return nil
+}
+
+func validateQuantity(e *OrderValidationError, param PlaceOrderParam, rules *ContractRules, ruleType string) {
+	if param.CashQty != nil {
+		if !containsFold(rules.CqtTypes, ruleType) {
+			e.add("cashQty", "cash quantity is not supported for %s orders", param.OrderType)
+		}
+		if param.CashQty.Sign() <= 0 {
+			e.add("cashQty", "must be positive")
+		} else if rules.CashQtyIncr > 0 && !isMultipleOf(*param.CashQty, DecimalFromFloat(rules.CashQtyIncr)) {
+			e.add("cashQty", "%s is not a multiple of %v", param.CashQty, rules.CashQtyIncr)
+		}
+		return
+	}
+
+	quantity := param.Quantity
+	if quantity.Sign() <= 0 {
+		e.add("quantity", "must be positive")
+		return
+	}
+	if !quantity.Equal(quantity.Truncate(0)) {
+		if !containsFold(rules.FraqTypes, ruleType) {
+			e.add("quantity", "fractional quantity %s is not supported for %s orders", quantity, param.OrderType)
+		}
+		return
+	}
+	if rules.SizeIncrement > 0 {
+		increment := DecimalFromFloat(rules.SizeIncrement)
+		if quantity.LessThan(increment) {
+			e.add("quantity", "%s is below the minimum size %s", quantity, increment)
+		} else if !isMultipleOf(quantity, increment) {
+			e.add("quantity", "%s is not a multiple of %s", quantity, increment)
+		}
+	}
+}
+
+// validatePrices :
+// Price is the limit of LMT, LIT, LOC, STP LMT and REL orders and the stop
+// of STP and MIT orders; AuxPrice is the stop of STP LMT and the trigger of
+// LIT orders, or an offset, see AuxPriceIsOffset. Offsets may be zero and
+// must be a multiple of the increment at Price, or of the finest increment
+// for orders without one, as the market price is not known here.
+func validatePrices(e *OrderValidationError, param PlaceOrderParam, rules *ContractRules) {
+	switch param.OrderType {
+	case OrderTypeLimit, OrderTypeStop, OrderTypeStopLimit, OrderTypeMarketIfTouch, OrderTypeLimitIfTouched, OrderTypeLimitOnClose:
+		if param.Price == nil {
+			e.add("price", "is required for %s orders", param.OrderType)
+		}
+	}
+	switch param.OrderType {
+	case OrderTypeStopLimit, OrderTypeLimitIfTouched:
+		if param.AuxPrice == nil {
+			e.add("auxPrice", "is required for %s orders", param.OrderType)
+		}
+	}
+	switch param.OrderType {
+	case OrderTypeTrailing, OrderTypeTrailingLimit:
+		if param.TrailingAmount == nil || param.TrailingAmount.Sign() <= 0 {
+			e.add("trailingAmt", "a positive amount is required for %s orders", param.OrderType)
+		}
+		if param.TrailingType == "" {
+			e.add("trailingType", "is required for %s orders", param.OrderType)
+		}
+	}
+
+	rounder := NewPriceRounder(rules)
+	validatePrice(e, rounder, rules, "price", param.Price)
+	if !AuxPriceIsOffset(param.OrderType) {
+		validatePrice(e, rounder, rules, "auxPrice", param.AuxPrice)
+	} else if param.AuxPrice != nil {
+		var reference Decimal
+		if param.Price != nil {
+			reference = *param.Price
+		}
+		offset := *param.AuxPrice
+		if offset.Sign() < 0 {
+			e.add("auxPrice", "offset must not be negative")
+		} else if rounded := rounder.RoundOffset(offset, reference); !rounded.Equal(offset) {
+			e.add("auxPrice", "offset %s is not a multiple of the increment %s, nearest valid offset is %s", offset, rounder.Increment(reference), rounded)
+		}
+	}
+}
+
+func validatePrice(e *OrderValidationError, rounder *PriceRounder, rules *ContractRules, field string, price *Decimal) {
+	if price == nil {
+		return
+	}
+	if price.Sign() <= 0 && !rules.NegativeCapable {
+		e.add(field, "must be positive")
+	} else if rounder.Validate(*price) != nil {
+		e.add(field, "%s is not a multiple of the increment %s, nearest valid price is %s", price, rounder.Increment(*price), rounder.RoundNearest(*price))
+	}
+}
+
+func isMultipleOf(value, increment Decimal) bool {
+	return value.Div(increment, 0).Mul(increment).Equal(value)
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsTimeInForce :
+// Rules list time in force like "DAY/o,a", the part before "/" is the name.
+func containsTimeInForce(tifTypes []string, tif TimeInForce) bool {
+	for _, tifType := range tifTypes {
+		name, _, _ := strings.Cut(tifType, "/")
+		if strings.EqualFold(name, string(tif)) {
+			return true
+		}
+	}
+	return false
+}
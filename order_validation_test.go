@@ -0,0 +1,142 @@
+package ibkr_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+	"github.com/dictxwang/go-ibkr/ibkrtest"
+)
+
+// stockContractRules are trimmed rules of a US stock as served by
+// /iserver/contract/rules.
+func stockContractRules() ibkr.ContractRules {
+	return ibkr.ContractRules{
+		CanTradeAcctIds:   []string{"DU123"},
+		OrderTypes:        []string{"market", "limit", "stop", "stop_limit", "mit", "lit", "trailing_stop", "trailing_stop_limit", "relative"},
+		OrderTypesOutside: []string{"limit", "stop_limit"},
+		FraqTypes:         []string{"market", "limit"},
+		CqtTypes:          []string{"market"},
+		TifTypes:          []string{"DAY/o,a", "GTC/o,a", "IOC/o"},
+		SizeIncrement:     1,
+		CashQtyIncr:       500,
+		IncrementRules:    stockRules,
+	}
+}
+
+// violations returns the fields of the violations in err, nil for no error.
+func violations(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var e *ibkr.OrderValidationError
+	if !errors.As(err, &e) {
+		t.Fatalf("got %T %v, want *OrderValidationError", err, err)
+	}
+	fields := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		fields = append(fields, violation.Field)
+	}
+	return fields
+}
+
+func TestValidateOrder(t *testing.T) {
+	conid := 265598
+	limit := func(change func(*ibkr.PlaceOrderParam)) ibkr.PlaceOrderParam {
+		param := ibkr.PlaceOrderParam{
+			AccountId:   "DU123",
+			ContractId:  &conid,
+			Side:        "BUY",
+			OrderType:   ibkr.OrderTypeLimit,
+			Price:       decimalPtr("187.25"),
+			Quantity:    d("100"),
+			TimeInForce: ibkr.TimeInForceDAY,
+		}
+		if change != nil {
+			change(&param)
+		}
+		return param
+	}
+	errorMessage := "no rules"
+
+	tests := []struct {
+		name  string
+		param ibkr.PlaceOrderParam
+		// rules changes the stock rules, or replaces them with nil when it
+		// returns false
+		rules func(*ibkr.ContractRules) bool
+		want  []string
+	}{
+		{"valid limit", limit(nil), nil, nil},
+		{"nil rules", limit(nil), func(*ibkr.ContractRules) bool { return false }, []string{"conid"}},
+		{"rules error", limit(nil), func(r *ibkr.ContractRules) bool { r.Error = &errorMessage; return true }, []string{"conid"}},
+		{"missing conid", limit(func(p *ibkr.PlaceOrderParam) { p.ContractId = nil }), nil, []string{"conid"}},
+		{"account can not trade", limit(func(p *ibkr.PlaceOrderParam) { p.AccountId = "DU999" }), nil, []string{"acctId"}},
+		{"side", limit(func(p *ibkr.PlaceOrderParam) { p.Side = "SHORT" }), nil, []string{"side"}},
+		{"lower case side", limit(func(p *ibkr.PlaceOrderParam) { p.Side = "sell" }), nil, nil},
+		{"order type not allowed", limit(func(p *ibkr.PlaceOrderParam) { p.OrderType = ibkr.OrderTypeMarketOnClose; p.Price = nil }), nil, []string{"orderType"}},
+		{"order type unknown to rules", limit(func(p *ibkr.PlaceOrderParam) { p.OrderType = ibkr.OrderTypeMarketToLimit; p.Price = nil }), nil, nil},
+		{"outside RTH", limit(func(p *ibkr.PlaceOrderParam) { p.OrderType = ibkr.OrderTypeStop; p.OutsideRegularTradingHours = true }), nil, []string{"outsideRTH"}},
+		{"tif", limit(func(p *ibkr.PlaceOrderParam) { p.TimeInForce = ibkr.TimeInForceFOK }), nil, []string{"tif"}},
+		{"zero quantity", limit(func(p *ibkr.PlaceOrderParam) { p.Quantity = ibkr.Decimal{} }), nil, []string{"quantity"}},
+		{"fractional", limit(func(p *ibkr.PlaceOrderParam) { p.Quantity = d("0.5") }), nil, nil},
+		{"fractional not supported", limit(func(p *ibkr.PlaceOrderParam) { p.OrderType = ibkr.OrderTypeStop; p.Quantity = d("0.5") }), nil, []string{"quantity"}},
+		{"size increment", limit(func(p *ibkr.PlaceOrderParam) { p.Quantity = d("150") }), func(r *ibkr.ContractRules) bool { r.SizeIncrement = 100; return true }, []string{"quantity"}},
+		{"below minimum size", limit(func(p *ibkr.PlaceOrderParam) { p.Quantity = d("50") }), func(r *ibkr.ContractRules) bool { r.SizeIncrement = 100; return true }, []string{"quantity"}},
+		{"cash quantity", limit(func(p *ibkr.PlaceOrderParam) {
+			p.OrderType, p.Price, p.Quantity, p.CashQty = ibkr.OrderTypeMarket, nil, ibkr.Decimal{}, decimalPtr("1000")
+		}), nil, nil},
+		{"cash quantity increment and type", limit(func(p *ibkr.PlaceOrderParam) { p.Quantity, p.CashQty = ibkr.Decimal{}, decimalPtr("750") }), nil, []string{"cashQty", "cashQty"}},
+		{"missing price", limit(func(p *ibkr.PlaceOrderParam) { p.Price = nil }), nil, []string{"price"}},
+		{"price increment", limit(func(p *ibkr.PlaceOrderParam) { p.Price = decimalPtr("187.255") }), nil, []string{"price"}},
+		{"negative price", limit(func(p *ibkr.PlaceOrderParam) { p.Price = decimalPtr("-1") }), nil, []string{"price"}},
+		{"negative capable", limit(func(p *ibkr.PlaceOrderParam) { p.Price = decimalPtr("-1.25") }), func(r *ibkr.ContractRules) bool { r.NegativeCapable = true; return true }, nil},
+		{"stop limit without stop", limit(func(p *ibkr.PlaceOrderParam) { p.OrderType = ibkr.OrderTypeStopLimit }), nil, []string{"auxPrice"}},
+		{"trailing without amount", limit(func(p *ibkr.PlaceOrderParam) { p.OrderType, p.Price = ibkr.OrderTypeTrailing, nil }), nil, []string{"trailingAmt", "trailingType"}},
+		{"every violation", limit(func(p *ibkr.PlaceOrderParam) { p.Side, p.Price, p.Quantity = "", nil, ibkr.Decimal{} }), nil, []string{"side", "quantity", "price"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := stockContractRules()
+			served := &rules
+			if tt.rules != nil && !tt.rules(&rules) {
+				served = nil
+			}
+			if got := violations(t, ibkr.ValidateOrder(tt.param, served)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("violations %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateOrderServedRules(t *testing.T) {
+	server := ibkrtest.NewServer(t)
+	server.SetContractRules(265598, stockContractRules())
+	service := server.Client().Service().Contract()
+
+	param, err := ibkr.NewOrder("DU123", 265598).Sell(d("10")).Limit(d("187.255")).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		contractId int
+		want       []string
+	}{
+		{265598, []string{"price"}},
+		{8314, []string{"conid", "orderType"}},
+	} {
+		rules, err := service.SearchContractRules(ibkr.SearchContractRulesQuery{ContractId: tt.contractId})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := violations(t, ibkr.ValidateOrder(param, rules)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("conid %d violations %v, want %v", tt.contractId, got, tt.want)
+		}
+	}
+
+	if _, err := ibkr.NewOrder("DU123", 265598).Buy(d("1")).Market().BuildFor(nil); !reflect.DeepEqual(violations(t, err), []string{"conid"}) {
+		t.Errorf("BuildFor(nil) got %v, want a conid violation", err)
+	}
+}
@@ -3,13 +3,13 @@ package ibkr
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	"net/url"
 	"strings"
 )
 
 type OrdersServiceI interface {
 	PlaceOrder(orders []PlaceOrderParam) (*PlaceOrderResponse, error)
+	PreviewOrder(orders []PlaceOrderParam) (*PreviewOrderResponse, error)
 	CancelOrder(param CancelOrderParam) (*CancelOrderResponse, error)
 	PlaceOrderReplyConfirmation(param PlaceOrderReplyConfirmationParam) (*PlaceOrderReplyConfirmationResponse, error)
 	RespondServerPrompt(param RespondServerPromptParam) (*RespondServerPromptResponse, error)
@@ -32,7 +32,7 @@ func (s *OrdersService) PlaceOrder(orders []PlaceOrderParam) (*PlaceOrderRespons
 		return nil, err
 	}
 
-	responseBytes, err := s.client.postJSONConciseResponse(fmt.Sprintf("/iserver/account/%s/orders", orders[0].AccountId), body)
+	responseBytes, err := s.client.postJSONConciseResponse(newOperation(OperationNamePlaceOrder, "/iserver/account/{accountId}/orders", orders[0].AccountId), body)
 	if err != nil {
 		return nil, err
 	}
@@ -65,12 +65,33 @@ func (s *OrdersService) PlaceOrder(orders []PlaceOrderParam) (*PlaceOrderRespons
 	return &resp, nil
 }
 
+// PreviewOrder :
+// Returns the what-if commission and margin impact of orders without placing them.
+func (s *OrdersService) PreviewOrder(orders []PlaceOrderParam) (*PreviewOrderResponse, error) {
+	if len(orders) == 0 {
+		return nil, errors.New("require order params")
+	}
+
+	params := map[string][]PlaceOrderParam{}
+	params["orders"] = orders
+	body, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp PreviewOrderResponse
+	if err := s.client.postJSON(newOperation(OperationNamePreviewOrder, "/iserver/account/{accountId}/orders/whatif", orders[0].AccountId), body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
 func (s *OrdersService) CancelOrder(param CancelOrderParam) (*CancelOrderResponse, error) {
 
 	var resp CancelOrderResponse
 
 	queries := url.Values{}
-	if err := s.client.deletePublic(fmt.Sprintf("/iserver/account/%s/order/%d", param.AccountId, param.OrderId), queries, &resp); err != nil {
+	if err := s.client.deletePublic(newOperation(OperationNameCancelOrder, "/iserver/account/{accountId}/order/{orderId}", param.AccountId, param.OrderId), queries, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -83,7 +104,7 @@ func (s *OrdersService) PlaceOrderReplyConfirmation(param PlaceOrderReplyConfirm
 		return nil, err
 	}
 
-	responseBytes, err := s.client.postJSONConciseResponse(fmt.Sprintf("/iserver/reply/%s", param.ReplyId), body)
+	responseBytes, err := s.client.postJSONConciseResponse(newOperation(OperationNamePlaceOrderReplyConfirmation, "/iserver/reply/{replyId}", param.ReplyId), body)
 	if err != nil {
 		return nil, err
 	}
@@ -93,7 +114,7 @@ func (s *OrdersService) PlaceOrderReplyConfirmation(param PlaceOrderReplyConfirm
 		if err != nil {
 			return nil, err
 		}
-	} else {
+	} else if strings.Contains(string(responseBytes), "\"order_id\"") {
 		var results []PlaceOrderNormalResult
 		err := json.Unmarshal(responseBytes, &results)
 		if err != nil {
@@ -101,6 +122,15 @@ func (s *OrdersService) PlaceOrderReplyConfirmation(param PlaceOrderReplyConfirm
 		} else {
 			resp.NormalResults = results
 		}
+	} else {
+		// confirming one prompt can raise the next one
+		var alternates []PlaceOrderAlternateResult
+		err := json.Unmarshal(responseBytes, &alternates)
+		if err != nil {
+			return nil, err
+		} else {
+			resp.AlternateResults = alternates
+		}
 	}
 	return &resp, nil
 }
@@ -111,7 +141,7 @@ func (s *OrdersService) RespondServerPrompt(param RespondServerPromptParam) (*Re
 		return nil, err
 	}
 
-	responseBytes, err := s.client.postJSONConciseResponse("/iserver/notification", body)
+	responseBytes, err := s.client.postJSONConciseResponse(newOperation(OperationNameRespondServerPrompt, "/iserver/notification"), body)
 	if err != nil {
 		return nil, err
 	}
@@ -128,7 +158,7 @@ func (s *OrdersService) SuppressMessages(messageIds []string) (*SuppressMessages
 		return nil, err
 	}
 
-	responseBytes, err := s.client.postJSONConciseResponse("/iserver/questions/suppress", body)
+	responseBytes, err := s.client.postJSONConciseResponse(newOperation(OperationNameSuppressMessages, "/iserver/questions/suppress"), body)
 	if err != nil {
 		return nil, err
 	}
@@ -154,22 +184,22 @@ type PlaceOrderParam struct {
 	ListingExchange            string                 `json:"listingExchange,omitempty"`
 	IsSingleGroup              bool                   `json:"isSingleGroup"`
 	OutsideRegularTradingHours bool                   `json:"outsideRTH"`
-	Price                      *float64               `json:"price,omitempty"`
-	AuxPrice                   *float64               `json:"auxPrice,omitempty"`
+	Price                      *Decimal               `json:"price,omitempty"`
+	AuxPrice                   *Decimal               `json:"auxPrice,omitempty"`
 	Side                       string                 `json:"side"`
 	Ticker                     string                 `json:"ticker,omitempty"`
 	TimeInForce                TimeInForce            `json:"tif"`
-	TrailingAmount             *float64               `json:"trailingAmt,omitempty"`
+	TrailingAmount             *Decimal               `json:"trailingAmt,omitempty"`
 	TrailingType               TrailingType           `json:"trailingType,omitempty"`
 	AllOrNone                  bool                   `json:"allOrNone"`
 	CustomerAccount            string                 `json:"customerAccount,omitempty"`
 	IsProCustomer              bool                   `json:"isProCustomer"`
 	Referrer                   string                 `json:"referrer,omitempty"`
-	Quantity                   float64                `json:"quantity"`
-	CashQty                    *float64               `json:"cashQty"`
-	FxQty                      *float64               `json:"fxQty,omitempty"`
+	Quantity                   Decimal                `json:"quantity"`
+	CashQty                    *Decimal               `json:"cashQty"`
+	FxQty                      *Decimal               `json:"fxQty,omitempty"`
 	UseAdaptive                *bool                  `json:"useAdaptive,omitempty"`
-	IsCcyConversion            *bool                  `json:"isCcyConv,omitempty"'`
+	IsCcyConversion            *bool                  `json:"isCcyConv,omitempty"`
 	AllocationMethod           string                 `json:"allocationMethod,omitempty"`
 	ManualOrderTime            *int                   `json:"manualOrderTime,omitempty"`
 	Deactivated                *bool                  `json:"deactivated,omitempty"`
@@ -179,6 +209,7 @@ type PlaceOrderParam struct {
 
 type PlaceOrderNormalResult struct {
 	OrderId        string      `json:"order_id"`
+	LocalOrderId   string      `json:"local_order_id,omitempty"` // the cOID of the order, if it has one
 	OrderStatus    OrderStatus `json:"order_status"`
 	EncryptMessage string      `json:"encrypt_message"`
 }
@@ -216,8 +247,31 @@ type PlaceOrderReplyConfirmationParam struct {
 }
 
 type PlaceOrderReplyConfirmationResponse struct {
-	NormalResults []PlaceOrderNormalResult `json:"-"`
-	Error         string                   `json:"error,omitempty"`
+	NormalResults    []PlaceOrderNormalResult    `json:"-"`
+	AlternateResults []PlaceOrderAlternateResult `json:"-"`
+	Error            string                      `json:"error,omitempty"`
+}
+
+type PreviewOrderAmount struct {
+	Amount     string `json:"amount"`
+	Commission string `json:"commission"`
+	Total      string `json:"total"`
+}
+
+type PreviewOrderChange struct {
+	Current string `json:"current"`
+	Change  string `json:"change"`
+	After   string `json:"after"`
+}
+
+type PreviewOrderResponse struct {
+	Amount      PreviewOrderAmount `json:"amount"`
+	Equity      PreviewOrderChange `json:"equity"`
+	Initial     PreviewOrderChange `json:"initial"`
+	Maintenance PreviewOrderChange `json:"maintenance"`
+	Position    PreviewOrderChange `json:"position"`
+	Warn        *string            `json:"warn,omitempty"`
+	Error       *string            `json:"error,omitempty"`
 }
 
 type RespondServerPromptParam struct {
@@ -0,0 +1,137 @@
+// Package otelibkr instruments ibkr clients with OpenTelemetry traces and metrics.
+//
+//	instrumentation, err := otelibkr.New(otelibkr.Config{})
+//	client.Use(instrumentation.Middleware())
+//	wsClient.WithHooks(instrumentation.WebsocketHooks())
+package otelibkr
+
+import (
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/dictxwang/go-ibkr/otelibkr"
+
+// Attribute :
+// An attribute recorded only when allowlisted, as it identifies accounts or
+// has a high cardinality.
+type Attribute string
+
+const (
+	AttributeAccount = Attribute("ibkr.account")
+	AttributeConid   = Attribute("ibkr.conid")
+	AttributeTopic   = Attribute("ibkr.websocket.topic")
+)
+
+// DefaultAttributes :
+var DefaultAttributes = []Attribute{AttributeTopic}
+
+const (
+	attributeOperation = attribute.Key("ibkr.operation")
+	attributeService   = attribute.Key("ibkr.websocket.service")
+	attributeMethod    = attribute.Key("http.request.method")
+	attributeStatus    = attribute.Key("http.response.status_code")
+	attributeErrorType = attribute.Key("error.type")
+)
+
+// Config :
+type Config struct {
+	// TracerProvider and MeterProvider default to the global providers.
+	TracerProvider trace.TracerProvider
+	MeterProvider  metric.MeterProvider
+	// Attributes is the allowlist of optional attributes. nil means
+	// DefaultAttributes, an empty slice records none.
+	Attributes []Attribute
+}
+
+// Instrumentation :
+type Instrumentation struct {
+	tracer  trace.Tracer
+	allowed map[Attribute]bool
+
+	requestDuration metric.Float64Histogram
+	requestErrors   metric.Int64Counter
+	messages        metric.Int64Counter
+	connects        metric.Int64Counter
+	reconnects      metric.Int64Counter
+	subscriptions   metric.Int64UpDownCounter
+
+	mutex     sync.Mutex
+	connected map[string]bool
+	// active holds the attributes of the subscriptions of each service by
+	// subscription key, e.g. "md+265598", to take them back on disconnect
+	active map[string]map[string]attribute.Set
+}
+
+// New :
+func New(config Config) (*Instrumentation, error) {
+	tracerProvider := config.TracerProvider
+	if tracerProvider == nil {
+		tracerProvider = otel.GetTracerProvider()
+	}
+	meterProvider := config.MeterProvider
+	if meterProvider == nil {
+		meterProvider = otel.GetMeterProvider()
+	}
+	attributes := config.Attributes
+	if attributes == nil {
+		attributes = DefaultAttributes
+	}
+
+	i := &Instrumentation{
+		tracer:    tracerProvider.Tracer(instrumentationName),
+		allowed:   map[Attribute]bool{},
+		connected: map[string]bool{},
+		active:    map[string]map[string]attribute.Set{},
+	}
+	for _, attribute := range attributes {
+		i.allowed[attribute] = true
+	}
+
+	meter := meterProvider.Meter(instrumentationName)
+	var err error
+	if i.requestDuration, err = meter.Float64Histogram("ibkr.request.duration",
+		metric.WithUnit("s"), metric.WithDescription("Latency of REST requests.")); err != nil {
+		return nil, err
+	}
+	if i.requestErrors, err = meter.Int64Counter("ibkr.request.errors",
+		metric.WithDescription("Failed REST requests by error class.")); err != nil {
+		return nil, err
+	}
+	if i.messages, err = meter.Int64Counter("ibkr.websocket.messages",
+		metric.WithDescription("Received websocket messages.")); err != nil {
+		return nil, err
+	}
+	if i.connects, err = meter.Int64Counter("ibkr.websocket.connects",
+		metric.WithDescription("Websocket dials, failed ones with error.type.")); err != nil {
+		return nil, err
+	}
+	if i.reconnects, err = meter.Int64Counter("ibkr.websocket.reconnects",
+		metric.WithDescription("Successful websocket dials after the first one per service.")); err != nil {
+		return nil, err
+	}
+	if i.subscriptions, err = meter.Int64UpDownCounter("ibkr.websocket.subscriptions",
+		metric.WithDescription("Active websocket subscriptions.")); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+// optional :
+// Appends the allowlisted attributes with a non-empty value.
+func (i *Instrumentation) optional(attributes []attribute.KeyValue, account, conid, topic string) []attribute.KeyValue {
+	if account != "" && i.allowed[AttributeAccount] {
+		attributes = append(attributes, attribute.String(string(AttributeAccount), account))
+	}
+	if conid != "" && i.allowed[AttributeConid] {
+		attributes = append(attributes, attribute.String(string(AttributeConid), conid))
+	}
+	if topic != "" && i.allowed[AttributeTopic] {
+		attributes = append(attributes, attribute.String(string(AttributeTopic), topic))
+	}
+	return attributes
+}
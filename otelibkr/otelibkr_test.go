@@ -0,0 +1,178 @@
+package otelibkr_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+	"github.com/dictxwang/go-ibkr/ibkrtest"
+	"github.com/dictxwang/go-ibkr/otelibkr"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// exporters holds in-memory exporters of an Instrumentation.
+type exporters struct {
+	spans  *tracetest.SpanRecorder
+	reader *sdkmetric.ManualReader
+}
+
+func newInstrumentation(t *testing.T, allowed ...otelibkr.Attribute) (*otelibkr.Instrumentation, exporters) {
+	t.Helper()
+	e := exporters{spans: tracetest.NewSpanRecorder(), reader: sdkmetric.NewManualReader()}
+	instrumentation, err := otelibkr.New(otelibkr.Config{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(e.spans)),
+		MeterProvider:  sdkmetric.NewMeterProvider(sdkmetric.WithReader(e.reader)),
+		Attributes:     allowed,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return instrumentation, e
+}
+
+func (e exporters) spanNames() []string {
+	var names []string
+	for _, span := range e.spans.Ended() {
+		names = append(names, span.Name())
+	}
+	return names
+}
+
+// sum :
+// Returns the sum of the counter name over the points with attribute key set
+// to value, or over all points for an empty key.
+func (e exporters) sum(t *testing.T, name string, key attribute.Key, value string) int64 {
+	t.Helper()
+	var data metricdata.ResourceMetrics
+	if err := e.reader.Collect(context.Background(), &data); err != nil {
+		t.Fatal(err)
+	}
+	var total int64
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if m.Name != name {
+				continue
+			}
+			switch points := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, point := range points.DataPoints {
+					if got, _ := point.Attributes.Value(key); key == "" || got.AsString() == value {
+						total += point.Value
+					}
+				}
+			case metricdata.Histogram[float64]:
+				for _, point := range points.DataPoints {
+					if got, _ := point.Attributes.Value(key); key == "" || got.AsString() == value {
+						total += int64(point.Count)
+					}
+				}
+			}
+		}
+	}
+	return total
+}
+
+func TestMiddleware(t *testing.T) {
+	server := ibkrtest.NewServer(t)
+	server.Handle(http.MethodGet, "/portfolio/{accountId}/positions/{pageId}", func(ibkrtest.Request) (int, interface{}) {
+		return http.StatusUnauthorized, nil
+	})
+	instrumentation, e := newInstrumentation(t, otelibkr.AttributeAccount)
+	client := server.Client().Use(instrumentation.Middleware())
+
+	if _, err := client.Service().Session().PostAuthStatus(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Service().Portfolio().GetPositions(ibkr.GetPositionParam{AccountId: "DU123"}); err == nil {
+		t.Fatal("expected the positions request to fail")
+	}
+
+	spans := e.spans.Ended()
+	if len(spans) != 2 || spans[0].Name() != "ibkr.session.auth_status" || spans[1].Name() != "ibkr.portfolio.positions" {
+		t.Fatalf("spans %v", e.spanNames())
+	}
+	var account string
+	for _, kv := range spans[1].Attributes() {
+		if kv.Key == attribute.Key(otelibkr.AttributeAccount) {
+			account = kv.Value.AsString()
+		}
+	}
+	if account != "DU123" {
+		t.Errorf("positions span account %q, want DU123", account)
+	}
+
+	tests := []struct {
+		name  string
+		key   attribute.Key
+		value string
+		want  int64
+	}{
+		{"ibkr.request.duration", "", "", 2},
+		{"ibkr.request.errors", "error.type", "unauthorized", 1},
+		{"ibkr.request.errors", "error.type", "not_found", 0},
+	}
+	for _, tt := range tests {
+		if got := e.sum(t, tt.name, tt.key, tt.value); got != tt.want {
+			t.Errorf("%s %s=%s got %d, want %d", tt.name, tt.key, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestWebsocketHooks(t *testing.T) {
+	server := ibkrtest.NewServer(t)
+	instrumentation, e := newInstrumentation(t)
+	service, err := server.WebsocketClient().WithHooks(instrumentation.WebsocketHooks()).Service().Public("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = service.Start(context.Background(), nil)
+	}()
+
+	received := make(chan struct{}, 4)
+	param := ibkr.WebsocketPublicMarketDataParam{ContractIds: []int{265598, 8314}}
+	for range 2 {
+		if _, err := service.SubscribeMarketData(param, func(ibkr.WebsocketPublicMarketDataResponse) error {
+			received <- struct{}{}
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server.WaitForWebsocketMessage(t, "smd+8314", time.Second)
+	if got := e.sum(t, "ibkr.websocket.subscriptions", "", ""); got != 2 {
+		t.Errorf("subscriptions %d after subscribing two contracts twice, want 2", got)
+	}
+
+	if err := server.PushMarketData(ibkr.WebsocketPublicMarketDataResponse{ContractId: 265598, LastPrice: "187"}); err != nil {
+		t.Fatal(err)
+	}
+	<-received
+	if got := e.sum(t, "ibkr.websocket.messages", "ibkr.websocket.topic", "md"); got != 1 {
+		t.Errorf("md messages %d, want 1", got)
+	}
+
+	server.DropWebsockets()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Start did not return after the connection dropped")
+	}
+	if got := e.sum(t, "ibkr.websocket.subscriptions", "", ""); got != 0 {
+		t.Errorf("subscriptions %d after the connection dropped, want 0", got)
+	}
+	if got := e.sum(t, "ibkr.websocket.connects", "", ""); got != 1 {
+		t.Errorf("connects %d, want 1", got)
+	}
+	if names := e.spanNames(); len(names) != 4 || names[0] != "ibkr.websocket.subscribe" {
+		t.Errorf("spans %v, want four subscribe spans", names)
+	}
+}
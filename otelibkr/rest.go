@@ -0,0 +1,141 @@
+package otelibkr
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var spanNames = map[ibkr.OperationName]string{
+	ibkr.OperationNameGetProfitAndLoss:                         "ibkr.account.pnl",
+	ibkr.OperationNameGetAllContractIds:                        "ibkr.contract.all_conids",
+	ibkr.OperationNameSearchSecurityDefinition:                 "ibkr.contract.secdef",
+	ibkr.OperationNameGetContractInfo:                          "ibkr.contract.info",
+	ibkr.OperationNameGetCurrencyPairs:                         "ibkr.contract.currency_pairs",
+	ibkr.OperationNameGetCurrencyExchangeRate:                  "ibkr.contract.exchange_rate",
+	ibkr.OperationNameGetContractInfoAndRules:                  "ibkr.contract.info_and_rules",
+	ibkr.OperationNameSearchContractBySymbol:                   "ibkr.contract.search",
+	ibkr.OperationNameSearchContractRules:                      "ibkr.contract.rules",
+	ibkr.OperationNameGetSecurityFutures:                       "ibkr.contract.futures",
+	ibkr.OperationNameGetSecurityStocks:                        "ibkr.contract.stocks",
+	ibkr.OperationNameGetTradingSchedule:                       "ibkr.contract.schedule",
+	ibkr.OperationNameGetOrderStatus:                           "ibkr.orders.status",
+	ibkr.OperationNameGetTrades:                                "ibkr.orders.trades",
+	ibkr.OperationNameGetLiveOrders:                            "ibkr.orders.live",
+	ibkr.OperationNamePlaceOrder:                               "ibkr.orders.place",
+	ibkr.OperationNamePreviewOrder:                             "ibkr.orders.preview",
+	ibkr.OperationNameCancelOrder:                              "ibkr.orders.cancel",
+	ibkr.OperationNamePlaceOrderReplyConfirmation:              "ibkr.orders.reply",
+	ibkr.OperationNameRespondServerPrompt:                      "ibkr.orders.notification",
+	ibkr.OperationNameSuppressMessages:                         "ibkr.orders.suppress",
+	ibkr.OperationNameGetAccounts:                              "ibkr.portfolio.accounts",
+	ibkr.OperationNameGetSubAccounts:                           "ibkr.portfolio.subaccounts",
+	ibkr.OperationNameGetSubAccountsWithLargeAccountStructures: "ibkr.portfolio.subaccounts2",
+	ibkr.OperationNameGetSpecificAccount:                       "ibkr.portfolio.meta",
+	ibkr.OperationNameGetCombinationPositions:                  "ibkr.portfolio.combo_positions",
+	ibkr.OperationNameGetPositions:                             "ibkr.portfolio.positions",
+	ibkr.OperationNameGetPositionsNew:                          "ibkr.portfolio.positions2",
+	ibkr.OperationNameGetPositionByContractId:                  "ibkr.portfolio.position",
+	ibkr.OperationNameGetLedger:                                "ibkr.portfolio.ledger",
+	ibkr.OperationNamePostAuthStatus:                           "ibkr.session.auth_status",
+	ibkr.OperationNamePostReauthenticate:                       "ibkr.session.reauthenticate",
+	ibkr.OperationNamePostLogout:                               "ibkr.session.logout",
+	ibkr.OperationNamePostPingServer:                           "ibkr.session.tickle",
+}
+
+// SpanName :
+// Returns the span name of a REST operation, e.g. "ibkr.orders.place".
+func SpanName(name ibkr.OperationName) string {
+	if spanName, has := spanNames[name]; has {
+		return spanName
+	}
+	if name == "" {
+		return "ibkr.request"
+	}
+	return "ibkr." + string(name)
+}
+
+// Middleware :
+// Records a span, the latency and errors of every REST request.
+func (i *Instrumentation) Middleware() ibkr.Middleware {
+	return func(next ibkr.Handler) ibkr.Handler {
+		return func(operation ibkr.Operation, req *http.Request) (*http.Response, error) {
+			account, conid := pathParams(operation.Endpoint)
+			attributes := []attribute.KeyValue{
+				attributeOperation.String(string(operation.Name)),
+				attributeMethod.String(req.Method),
+			}
+			attributes = i.optional(attributes, account, conid, "")
+
+			ctx, span := i.tracer.Start(req.Context(), SpanName(operation.Name),
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attributes...),
+				trace.WithAttributes(attribute.String("url.template", operation.Endpoint.Template)))
+			defer span.End()
+
+			start := time.Now()
+			resp, err := next(operation, req.WithContext(ctx))
+			latency := time.Since(start)
+
+			errorType := ""
+			if err != nil {
+				errorType = "transport"
+				span.RecordError(err)
+			} else {
+				attributes = append(attributes, attributeStatus.Int(resp.StatusCode))
+				span.SetAttributes(attributeStatus.Int(resp.StatusCode))
+				errorType = statusErrorType(resp.StatusCode)
+			}
+			if errorType != "" {
+				span.SetStatus(codes.Error, errorType)
+				i.requestErrors.Add(ctx, 1, metric.WithAttributes(append(attributes, attributeErrorType.String(errorType))...))
+			}
+			i.requestDuration.Record(ctx, latency.Seconds(), metric.WithAttributes(attributes...))
+			return resp, err
+		}
+	}
+}
+
+// statusErrorType :
+// Classifies a status code the way Client.RequestFull reports it.
+func statusErrorType(status int) string {
+	switch {
+	case 200 <= status && status <= 299:
+		return ""
+	case status == http.StatusBadRequest:
+		return "bad_request"
+	case status == http.StatusUnauthorized:
+		return "unauthorized"
+	case status == http.StatusForbidden:
+		return "forbidden"
+	case status == http.StatusNotFound:
+		return "not_found"
+	default:
+		return "status"
+	}
+}
+
+// pathParams :
+// Returns the account and conid filled into the endpoint template.
+func pathParams(endpoint ibkr.Endpoint) (account, conid string) {
+	templateParts := strings.Split(endpoint.Template, "/")
+	pathParts := strings.Split(endpoint.Path, "/")
+	if len(templateParts) != len(pathParts) {
+		return "", ""
+	}
+	for n, part := range templateParts {
+		switch part {
+		case "{accountId}":
+			account = pathParts[n]
+		case "{conid}":
+			conid = pathParts[n]
+		}
+	}
+	return account, conid
+}
@@ -0,0 +1,182 @@
+package otelibkr
+
+import (
+	"context"
+	"strings"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// WebsocketHooks :
+// Records a span per subscribe and unsubscribe, and counts messages,
+// connections and active subscriptions.
+func (i *Instrumentation) WebsocketHooks() ibkr.WebsocketHooks {
+	return ibkr.WebsocketHooks{
+		OnConnect:    i.onConnect,
+		OnDisconnect: i.onDisconnect,
+		OnSend:       i.onSend,
+		OnMessage:    i.onMessage,
+	}
+}
+
+func (i *Instrumentation) onConnect(service string, err error) {
+	ctx := context.Background()
+	attributes := []attribute.KeyValue{attributeService.String(service)}
+	if err != nil {
+		i.connects.Add(ctx, 1, metric.WithAttributes(append(attributes, attributeErrorType.String("dial"))...))
+		return
+	}
+	i.connects.Add(ctx, 1, metric.WithAttributes(attributes...))
+
+	i.mutex.Lock()
+	reconnect := i.connected[service]
+	i.connected[service] = true
+	i.mutex.Unlock()
+	if reconnect {
+		i.reconnects.Add(ctx, 1, metric.WithAttributes(attributes...))
+	}
+}
+
+// onDisconnect :
+// The subscriptions of a connection end with it, so they are taken back
+// without waiting for unsubscribe messages that are never sent.
+func (i *Instrumentation) onDisconnect(service string, _ error) {
+	i.mutex.Lock()
+	active := i.active[service]
+	delete(i.active, service)
+	i.mutex.Unlock()
+
+	for _, attributes := range active {
+		i.subscriptions.Add(context.Background(), -1, metric.WithAttributeSet(attributes))
+	}
+}
+
+func (i *Instrumentation) onSend(service string, topic ibkr.WebsocketTopic, subscribe bool) func(error) {
+	spanName := "ibkr.websocket.unsubscribe"
+	if subscribe {
+		spanName = "ibkr.websocket.subscribe"
+	}
+	attributes := i.topicAttributes(service, topic)
+	ctx, span := i.tracer.Start(context.Background(), spanName)
+	span.SetAttributes(attributes...)
+
+	return func(err error) {
+		defer span.End()
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "write")
+			return
+		}
+		if subscribe {
+			i.subscribed(ctx, service, topic, attribute.NewSet(attributes...))
+		} else {
+			i.unsubscribed(ctx, service, topic)
+		}
+	}
+}
+
+// subscribed :
+// Counts a subscription once, however often it is sent.
+func (i *Instrumentation) subscribed(ctx context.Context, service string, topic ibkr.WebsocketTopic, attributes attribute.Set) {
+	key := subscriptionKey(topic)
+	i.mutex.Lock()
+	active, has := i.active[service]
+	if !has {
+		active = map[string]attribute.Set{}
+		i.active[service] = active
+	}
+	_, counted := active[key]
+	active[key] = attributes
+	i.mutex.Unlock()
+
+	if !counted {
+		i.subscriptions.Add(ctx, 1, metric.WithAttributeSet(attributes))
+	}
+}
+
+// unsubscribed :
+// Ends the subscription of topic. Unsubscribe messages without arguments,
+// like "ubd+{DU123}", end every subscription of their kind.
+func (i *Instrumentation) unsubscribed(ctx context.Context, service string, topic ibkr.WebsocketTopic) {
+	key, kind := subscriptionKey(topic), topicKind(topic.Name)
+	var ended []attribute.Set
+	i.mutex.Lock()
+	for activeKey, attributes := range i.active[service] {
+		if activeKey == key || len(topic.Args) == 0 && strings.HasPrefix(activeKey, kind+"+") {
+			ended = append(ended, attributes)
+			delete(i.active[service], activeKey)
+		}
+	}
+	i.mutex.Unlock()
+
+	for _, attributes := range ended {
+		i.subscriptions.Add(ctx, -1, metric.WithAttributeSet(attributes))
+	}
+}
+
+func (i *Instrumentation) onMessage(service string, topic ibkr.WebsocketTopic) {
+	i.messages.Add(context.Background(), 1, metric.WithAttributes(i.topicAttributes(service, topic)...))
+}
+
+// topicAttributes :
+// The topic attribute holds the name without the "s"/"u" prefix of subscribe
+// messages, so a subscription and its messages share it, e.g. "md".
+func (i *Instrumentation) topicAttributes(service string, topic ibkr.WebsocketTopic) []attribute.KeyValue {
+	account, conid := topicParams(topic)
+	return i.optional([]attribute.KeyValue{attributeService.String(service)}, account, conid, topicKind(topic.Name))
+}
+
+// topicKinds :
+// The subscribe and unsubscribe topics sharing a kind. Other topics, like
+// the unsolicited "sts" or "system", are their own kind.
+var topicKinds = map[string]string{
+	"smd": "md", "umd": "md",
+	"smh": "mh", "umh": "mh",
+	"sbd": "bd", "ubd": "bd",
+	"ssd": "sd", "usd": "sd",
+	"sld": "ld", "uld": "ld",
+	"sor": "or", "uor": "or",
+	"str": "tr", "utr": "tr",
+	"spl": "pl", "upl": "pl",
+}
+
+// topicKind :
+func topicKind(name string) string {
+	if kind, has := topicKinds[name]; has {
+		return kind
+	}
+	return name
+}
+
+// subscriptionKey :
+// Identifies a subscription by kind and args, e.g. "md+265598" or "or+".
+func subscriptionKey(topic ibkr.WebsocketTopic) string {
+	return topicKind(topic.Name) + "+" + topic.Key()
+}
+
+// topicParams :
+// Returns the account and conid of a topic, e.g. "smd+265598" or "sbd+DU123+265598".
+func topicParams(topic ibkr.WebsocketTopic) (account, conid string) {
+	arg := func(n int) string {
+		if n < len(topic.Args) {
+			return topic.Args[n]
+		}
+		return ""
+	}
+	switch topicKind(topic.Name) {
+	case "md":
+		conid = arg(0)
+	case "mh":
+		if topic.Name == "smh" {
+			conid = arg(0)
+		}
+	case "bd":
+		account, conid = arg(0), arg(1)
+	case "sd", "ld":
+		account = arg(0)
+	}
+	return account, conid
+}
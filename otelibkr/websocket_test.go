@@ -0,0 +1,48 @@
+package otelibkr
+
+import (
+	"testing"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+)
+
+func TestTopicKind(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"smd", "md"},
+		{"umd", "md"},
+		{"sbd", "bd"},
+		{"uor", "or"},
+		{"spl", "pl"},
+		{"sts", "sts"},
+		{"system", "system"},
+		{"act", "act"},
+		{"blt", "blt"},
+	}
+	for _, tt := range tests {
+		if got := topicKind(tt.name); got != tt.want {
+			t.Errorf("topicKind(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTopicParams(t *testing.T) {
+	tests := []struct {
+		topic   string
+		account string
+		conid   string
+	}{
+		{"smd+265598", "", "265598"},
+		{"sbd+DU123+265598", "DU123", "265598"},
+		{"ssd+DU123", "DU123", ""},
+		{"sor", "", ""},
+	}
+	for _, tt := range tests {
+		account, conid := topicParams(ibkr.ParseWebsocketTopic(tt.topic))
+		if account != tt.account || conid != tt.conid {
+			t.Errorf("topicParams(%q) = %q, %q, want %q, %q", tt.topic, account, conid, tt.account, tt.conid)
+		}
+	}
+}
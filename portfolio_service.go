@@ -27,7 +27,7 @@ func (s *PortfolioService) GetAccounts() (*[]PortfolioAccountInfo, error) {
 
 	param := url.Values{}
 
-	if err := s.client.getPublic("/portfolio/accounts", param, &res); err != nil {
+	if err := s.client.getPublic(newOperation(OperationNameGetAccounts, "/portfolio/accounts"), param, &res); err != nil {
 		return nil, err
 	}
 
@@ -40,7 +40,7 @@ func (s *PortfolioService) GetSubAccounts() (*[]PortfolioAccountInfo, error) {
 
 	param := url.Values{}
 
-	if err := s.client.getPublic("/portfolio/subaccounts", param, &res); err != nil {
+	if err := s.client.getPublic(newOperation(OperationNameGetSubAccounts, "/portfolio/subaccounts"), param, &res); err != nil {
 		return nil, err
 	}
 
@@ -53,7 +53,7 @@ func (s *PortfolioService) GetSubAccountsWithLargeAccountStructures() (*[]Portfo
 
 	param := url.Values{}
 
-	if err := s.client.getPublic("/portfolio/subaccounts2", param, &res); err != nil {
+	if err := s.client.getPublic(newOperation(OperationNameGetSubAccountsWithLargeAccountStructures, "/portfolio/subaccounts2"), param, &res); err != nil {
 		return nil, err
 	}
 
@@ -66,7 +66,7 @@ func (s *PortfolioService) GetSpecificAccount(accountId string) (*PortfolioAccou
 
 	param := url.Values{}
 
-	if err := s.client.getPublic(fmt.Sprintf("/portfolio/%s/meta", accountId), param, &res); err != nil {
+	if err := s.client.getPublic(newOperation(OperationNameGetSpecificAccount, "/portfolio/{accountId}/meta", accountId), param, &res); err != nil {
 		return nil, err
 	}
 
@@ -80,7 +80,7 @@ func (s *PortfolioService) GetCombinationPositions(accountId string, nocache boo
 	param := url.Values{}
 	param.Add("nocache", fmt.Sprintf("%t", nocache))
 
-	if err := s.client.getPublic(fmt.Sprintf("/portfolio/%s/combo/positions", accountId), param, &res); err != nil {
+	if err := s.client.getPublic(newOperation(OperationNameGetCombinationPositions, "/portfolio/{accountId}/combo/positions", accountId), param, &res); err != nil {
 		return nil, err
 	}
 
@@ -105,7 +105,7 @@ func (s *PortfolioService) GetPositions(param GetPositionParam) (*[]PositionInfo
 		query.Add("period", string(*param.Period))
 	}
 
-	if err := s.client.getPublic(fmt.Sprintf("/portfolio/%s/positions/%d", param.AccountId, param.PageId), query, &res); err != nil {
+	if err := s.client.getPublic(newOperation(OperationNameGetPositions, "/portfolio/{accountId}/positions/{pageId}", param.AccountId, param.PageId), query, &res); err != nil {
 		return nil, err
 	}
 
@@ -130,7 +130,7 @@ func (s *PortfolioService) GetPositionsNew(param GetPositionParam) (*[]PositionN
 		query.Add("period", string(*param.Period))
 	}
 
-	if err := s.client.getPublic(fmt.Sprintf("/portfolio2/%s/positions", param.AccountId), query, &res); err != nil {
+	if err := s.client.getPublic(newOperation(OperationNameGetPositionsNew, "/portfolio2/{accountId}/positions", param.AccountId), query, &res); err != nil {
 		return nil, err
 	}
 
@@ -143,7 +143,7 @@ func (s *PortfolioService) GetPositionByContractId(contractId int) (*PositionInf
 
 	query := url.Values{}
 
-	if err := s.client.getPublic(fmt.Sprintf("/portfolio/positions/%d", contractId), query, &res); err != nil {
+	if err := s.client.getPublic(newOperation(OperationNameGetPositionByContractId, "/portfolio/positions/{conid}", contractId), query, &res); err != nil {
 		return nil, err
 	}
 
@@ -156,7 +156,7 @@ func (s *PortfolioService) GetLedger(accountId string) (*map[string]AccountLedge
 
 	query := url.Values{}
 
-	if err := s.client.getPublic(fmt.Sprintf("/portfolio/%s/ledger", accountId), query, &res); err != nil {
+	if err := s.client.getPublic(newOperation(OperationNameGetLedger, "/portfolio/{accountId}/ledger", accountId), query, &res); err != nil {
 		return nil, err
 	}
 
@@ -211,36 +211,36 @@ type GetPositionParam struct {
 
 type AccountLedgerItem struct {
 	AcctCode string `json:"acctcode"`
-	CashBalance float64 `json:"cashbalance"`
+	CashBalance Decimal `json:"cashbalance"`
 	Currency string `json:"currency"`
-	ExchangeRate float64 `json:"exchangerate"`
-	FutureMarketValue float64 `json:"futuremarketvalue"`
-	FutureOptionMarketValue float64 `json:"futureoptionmarketvalue"`
-	FuturesOnlyPnl float64 `json:"futuresonlypnl"`
-	Interest float64 `json:"interest"`
-	NetLiquidationValue float64 `json:"netliquidationvalue"`
-	RealizedPnl float64 `json:"realizedpnl"`
-	SettledCash float64 `json:"settledcash"`
-	StockMarketValue float64 `json:"stockmarketvalue"`
-	StockOptionMarketValue float64 `json:"stockoptionmarketvalue"`
-	TbillsMarketValue float64 `json:"tbillsmarketvalue"`
-	TbondsMarketValue float64 `json:"tbondsmarketvalue"`
+	ExchangeRate Decimal `json:"exchangerate"`
+	FutureMarketValue Decimal `json:"futuremarketvalue"`
+	FutureOptionMarketValue Decimal `json:"futureoptionmarketvalue"`
+	FuturesOnlyPnl Decimal `json:"futuresonlypnl"`
+	Interest Decimal `json:"interest"`
+	NetLiquidationValue Decimal `json:"netliquidationvalue"`
+	RealizedPnl Decimal `json:"realizedpnl"`
+	SettledCash Decimal `json:"settledcash"`
+	StockMarketValue Decimal `json:"stockmarketvalue"`
+	StockOptionMarketValue Decimal `json:"stockoptionmarketvalue"`
+	TbillsMarketValue Decimal `json:"tbillsmarketvalue"`
+	TbondsMarketValue Decimal `json:"tbondsmarketvalue"`
 	Timestamp int64 `json:"timestamp"`
-	UnrealizedPnl float64 `json:"unrealizedpnl"`
+	UnrealizedPnl Decimal `json:"unrealizedpnl"`
 }
 
 type PositionInfo struct {
 	AccountId     string  `json:"acctId"`
 	ContractId    int     `json:"conid"`
 	ContractDesc  string  `json:"contractDesc"`
-	Position      float64 `json:"position"`
-	MarketPrice   float64 `json:"mktPrice"`
-	MarketValue   float64 `json:"mktValue"`
+	Position      Decimal `json:"position"`
+	MarketPrice   Decimal `json:"mktPrice"`
+	MarketValue   Decimal `json:"mktValue"`
 	Currency      string  `json:"currency"`
-	AverageCost   float64 `json:"avgCost"`
-	AveragePrice  float64 `json:"avgPrice"`
-	RealizedPnl   float64 `json:"realizedPnl"`
-	UnrealizedPnl float64 `json:"unrealizedPnl"`
+	AverageCost   Decimal `json:"avgCost"`
+	AveragePrice  Decimal `json:"avgPrice"`
+	RealizedPnl   Decimal `json:"realizedPnl"`
+	UnrealizedPnl Decimal `json:"unrealizedPnl"`
 	AssetClass    string  `json:"assetClass"`
 	PutOrCall     string  `json:"putOrCall,omitempty"`
 }
@@ -252,19 +252,19 @@ type CombinationPositionItem struct {
 }
 
 type PositionNewInfo struct {
-	Position      float64 `json:"position"`
+	Position      Decimal `json:"position"`
 	ContractId    string  `json:"conid"`
-	AverageCost   float64 `json:"avgCost"`
-	AveragePrice  float64 `json:"avgPrice"`
+	AverageCost   Decimal `json:"avgCost"`
+	AveragePrice  Decimal `json:"avgPrice"`
 	Currency      string  `json:"currency"`
 	Description   string  `json:"description"` // Returns the local symbol of the order.
 	IsLastToLoq   bool    `json:"isLastToLoq"`
-	MarketPrice   float64 `json:"marketPrice"`
-	MarketValue   float64 `json:"marketValue"`
-	RealizedPnl   float64 `json:"realizedPnl"`
+	MarketPrice   Decimal `json:"marketPrice"`
+	MarketValue   Decimal `json:"marketValue"`
+	RealizedPnl   Decimal `json:"realizedPnl"`
 	SecurityType  string  `json:"secType"`
 	Timestamp     int64   `json:"timestamp"`
-	UnrealizedPnl float64 `json:"unrealizedPnl"`
+	UnrealizedPnl Decimal `json:"unrealizedPnl"`
 	AssetClass    string  `json:"assetClass"`
 	Sector        string  `json:"sector"`
 	Group         string  `json:"group"`
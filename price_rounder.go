@@ -0,0 +1,274 @@
+package ibkr
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrPriceIncrement :
+// Returned by PriceRounder.Validate for a price that is not a multiple of the
+// increment of its price band, which the gateway rejects with o382.
+var ErrPriceIncrement = errors.New("price is not a multiple of the price increment")
+
+// priceBand :
+// The increment of prices from lowerEdge up to the next band.
+type priceBand struct {
+	lowerEdge Decimal
+	increment Decimal
+}
+
+// PriceRounder :
+// Rounds prices to the valid increment of a contract. The increment depends
+// on the price band, e.g. 0.0001 below 1 and 0.01 above for US stocks, and
+// negative prices of combos use the band of their absolute value.
+//
+// Limit prices are rounded away from the market: down for BUY and up for
+// SELL, so rounding never makes an order more aggressive. Stop prices are
+// rounded the other way, so a stop never triggers earlier than intended.
+// If-touched triggers sit on the other side of the market than stops and
+// are rounded like limits, down for BUY and up for SELL.
+type PriceRounder struct {
+	bands   []priceBand
+	display *DisplayRule
+}
+
+// NewPriceRounder :
+// Builds a rounder from the increment rules of rules, or from its single
+// Increment if it has none.
+func NewPriceRounder(rules *ContractRules) *PriceRounder {
+	r := newPriceRounder(rules.IncrementRules)
+	if len(r.bands) == 0 && rules.Increment > 0 {
+		r.bands = []priceBand{{increment: DecimalFromFloat(rules.Increment)}}
+	}
+	return r
+}
+
+// NewPriceRounderFromSecurityDefinition :
+// Builds a rounder from the increment and display rules of a /trsrv/secdef
+// item.
+func NewPriceRounderFromSecurityDefinition(item SearchSecurityDefinitionItem) *PriceRounder {
+	return newPriceRounder(item.IncrementRules).WithDisplayRule(item.DisplaceRule)
+}
+
+func newPriceRounder(rules []IncrementRule) *PriceRounder {
+	r := &PriceRounder{}
+	for _, rule := range rules {
+		if rule.Increment <= 0 {
+			continue
+		}
+		r.bands = append(r.bands, priceBand{
+			lowerEdge: DecimalFromFloat(rule.LowerEdge),
+			increment: DecimalFromFloat(rule.Increment),
+		})
+	}
+	sort.Slice(r.bands, func(i, j int) bool {
+		return r.bands[i].lowerEdge.LessThan(r.bands[j].lowerEdge)
+	})
+	return r
+}
+
+// LoadPriceRounder :
+// Builds the rounder of contractId from its security definition, falling back
+// to its contract rules when the definition carries no increment rules.
+func LoadPriceRounder(service ContractServiceI, contractId int) (*PriceRounder, error) {
+	definitions, err := service.SearchSecurityDefinitionByContactId([]int{contractId})
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range definitions.SecurityDefinitions {
+		if item.ContractId == contractId && len(item.IncrementRules) > 0 {
+			return NewPriceRounderFromSecurityDefinition(item), nil
+		}
+	}
+	rules, err := service.SearchContractRules(SearchContractRulesQuery{ContractId: contractId})
+	if err != nil {
+		return nil, err
+	}
+	return NewPriceRounder(rules), nil
+}
+
+// WithDisplayRule :
+// Sets the rule Format follows. A rule without digits is ignored.
+func (r *PriceRounder) WithDisplayRule(rule DisplayRule) *PriceRounder {
+	if rule.DisplayRuleStep.DecimalDigits > 0 || rule.Magnification != 0 {
+		r.display = &rule
+	}
+	return r
+}
+
+// Increment :
+// Returns the increment of the band of price, zero if the rounder has no
+// rules.
+func (r *PriceRounder) Increment(price Decimal) Decimal {
+	var increment Decimal
+	abs := price.Abs()
+	for _, band := range r.bands {
+		if band.lowerEdge.GreaterThan(abs) {
+			break
+		}
+		increment = band.increment
+	}
+	if increment.IsZero() && len(r.bands) > 0 {
+		increment = r.bands[0].increment
+	}
+	return increment
+}
+
+// RoundNearest :
+// Rounds price to the nearest valid price, halves away from zero.
+func (r *PriceRounder) RoundNearest(price Decimal) Decimal {
+	increment := r.Increment(price)
+	if increment.IsZero() {
+		return price
+	}
+	return price.Div(increment, 0).Mul(increment)
+}
+
+// RoundDown :
+// Returns the greatest valid price not above price.
+func (r *PriceRounder) RoundDown(price Decimal) Decimal {
+	rounded := r.RoundNearest(price)
+	if rounded.GreaterThan(price) {
+		rounded = rounded.Sub(r.Increment(price))
+	}
+	return rounded
+}
+
+// RoundUp :
+// Returns the least valid price not below price.
+func (r *PriceRounder) RoundUp(price Decimal) Decimal {
+	rounded := r.RoundNearest(price)
+	if rounded.LessThan(price) {
+		rounded = rounded.Add(r.Increment(price))
+	}
+	return rounded
+}
+
+// RoundLimit :
+// Rounds a limit price down for BUY and up for SELL.
+func (r *PriceRounder) RoundLimit(price Decimal, side OrderSide) Decimal {
+	if isBuy(string(side)) {
+		return r.RoundDown(price)
+	}
+	return r.RoundUp(price)
+}
+
+// RoundStop :
+// Rounds a stop price up for BUY and down for SELL.
+func (r *PriceRounder) RoundStop(price Decimal, side OrderSide) Decimal {
+	if isBuy(string(side)) {
+		return r.RoundUp(price)
+	}
+	return r.RoundDown(price)
+}
+
+// RoundTrigger :
+// Rounds the trigger of an MIT or LIT order down for BUY and up for SELL.
+func (r *PriceRounder) RoundTrigger(price Decimal, side OrderSide) Decimal {
+	return r.RoundLimit(price, side)
+}
+
+// RoundOffset :
+// Rounds an offset from the market, like the offset of a REL order, to the
+// nearest multiple of the increment at reference, the current price. Zero
+// stays a valid offset.
+func (r *PriceRounder) RoundOffset(offset, reference Decimal) Decimal {
+	increment := r.Increment(reference)
+	if increment.IsZero() {
+		return offset
+	}
+	return offset.Div(increment, 0).Mul(increment)
+}
+
+// RoundTrailingAmount :
+// Rounds a trailing amount like RoundOffset, keeping at least one increment.
+func (r *PriceRounder) RoundTrailingAmount(amount, reference Decimal) Decimal {
+	rounded := r.RoundOffset(amount, reference)
+	if rounded.Sign() <= 0 && !r.Increment(reference).IsZero() {
+		return r.Increment(reference)
+	}
+	return rounded
+}
+
+// RoundOrder :
+// Rounds the prices of param in place following its order type and side.
+// Price is the stop of STP orders and the trigger of MIT orders; STP LMT and
+// LIT orders carry their limit in Price and their stop or trigger in
+// AuxPrice. TRAIL LIMIT, REL, PASSV REL and PEG MKT orders carry an offset
+// in AuxPrice instead, see AuxPriceIsOffset. Offsets and trailing amounts
+// are rounded to the band of last, the current price of the contract, as
+// the order carries no price to take the band from. Percent trailing
+// amounts are left alone.
+func (r *PriceRounder) RoundOrder(param *PlaceOrderParam, last Decimal) {
+	side := OrderSide(strings.ToUpper(param.Side))
+	round := func(price *Decimal, rounding func(Decimal, OrderSide) Decimal) {
+		if price != nil {
+			*price = rounding(*price, side)
+		}
+	}
+
+	switch param.OrderType {
+	case OrderTypeStop, OrderTypeStopWithProtection:
+		round(param.Price, r.RoundStop)
+	case OrderTypeMarketIfTouch:
+		round(param.Price, r.RoundTrigger)
+	case OrderTypeLimitIfTouched:
+		round(param.Price, r.RoundLimit)
+		round(param.AuxPrice, r.RoundTrigger)
+	default:
+		round(param.Price, r.RoundLimit)
+		if !AuxPriceIsOffset(param.OrderType) {
+			round(param.AuxPrice, r.RoundStop)
+		} else if param.AuxPrice != nil {
+			*param.AuxPrice = r.RoundOffset(*param.AuxPrice, last)
+		}
+	}
+	if param.TrailingAmount != nil && param.TrailingType != TrailingTypePercent {
+		*param.TrailingAmount = r.RoundTrailingAmount(*param.TrailingAmount, last)
+	}
+}
+
+// Validate :
+// Returns an error wrapping ErrPriceIncrement if price is off its increment.
+func (r *PriceRounder) Validate(price Decimal) error {
+	if rounded := r.RoundNearest(price); !rounded.Equal(price) {
+		return fmt.Errorf("%w: %s, increment %s, nearest %s", ErrPriceIncrement, price, r.Increment(price), rounded)
+	}
+	return nil
+}
+
+// Format :
+// Formats price with the decimal digits of the display rule, or of the
+// increment of its band without one. A magnification scales the price
+// first, e.g. to show bond prices per 100.
+func (r *PriceRounder) Format(price Decimal) string {
+	if r.display == nil {
+		digits := r.Increment(price).String()
+		if _, fraction, ok := strings.Cut(digits, "."); ok {
+			return price.StringFixed(int32(len(fraction)))
+		}
+		return price.StringFixed(0)
+	}
+	if magnification := r.display.Magnification; magnification != 0 {
+		price = price.Mul(NewDecimal(1, -int32(magnification)))
+	}
+	return price.StringFixed(int32(r.display.DisplayRuleStep.DecimalDigits))
+}
+
+// AuxPriceIsOffset :
+// Reports whether orders of orderType carry an offset from the market in
+// AuxPrice rather than a price: the limit offset of TRAIL LIMIT and the
+// offset of REL, PASSV REL and PEG MKT orders.
+func AuxPriceIsOffset(orderType OrderType) bool {
+	switch orderType {
+	case OrderTypeTrailingLimit, OrderTypeRelative, OrderTypePassiveRelative, OrderTypePeggedToMarket:
+		return true
+	}
+	return false
+}
+
+func isBuy(side string) bool {
+	return strings.EqualFold(side, string(OrderSideBuy))
+}
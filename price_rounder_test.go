@@ -0,0 +1,219 @@
+package ibkr_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+	"github.com/dictxwang/go-ibkr/ibkrtest"
+)
+
+var d = ibkr.MustParseDecimal
+
+// stockRules are the increments of a US stock: 0.0001 below 1 and 0.01 above.
+var stockRules = []ibkr.IncrementRule{{LowerEdge: 1, Increment: 0.01}, {LowerEdge: 0, Increment: 0.0001}}
+
+func stockRounder() *ibkr.PriceRounder {
+	return ibkr.NewPriceRounder(&ibkr.ContractRules{IncrementRules: stockRules})
+}
+
+func decimalPtr(value string) *ibkr.Decimal {
+	v := d(value)
+	return &v
+}
+
+func TestPriceRounder(t *testing.T) {
+	r := stockRounder()
+	tests := []struct {
+		name  string
+		round func(ibkr.Decimal) ibkr.Decimal
+		price string
+		want  string
+	}{
+		{"increment below 1", r.Increment, "0.5", "0.0001"},
+		{"increment at edge", r.Increment, "1", "0.01"},
+		{"increment of negative", r.Increment, "-2", "0.01"},
+		{"nearest half away", r.RoundNearest, "187.255", "187.26"},
+		{"nearest below 1", r.RoundNearest, "0.12345", "0.1235"},
+		{"down", r.RoundDown, "187.259", "187.25"},
+		{"down valid", r.RoundDown, "187.25", "187.25"},
+		{"down negative", r.RoundDown, "-1.234", "-1.24"},
+		{"up", r.RoundUp, "187.251", "187.26"},
+		{"up negative", r.RoundUp, "-1.236", "-1.23"},
+		{"limit buy", func(p ibkr.Decimal) ibkr.Decimal { return r.RoundLimit(p, ibkr.OrderSideBuy) }, "10.005", "10"},
+		{"limit sell", func(p ibkr.Decimal) ibkr.Decimal { return r.RoundLimit(p, ibkr.OrderSideSell) }, "10.005", "10.01"},
+		{"stop buy", func(p ibkr.Decimal) ibkr.Decimal { return r.RoundStop(p, ibkr.OrderSideBuy) }, "10.005", "10.01"},
+		{"stop sell", func(p ibkr.Decimal) ibkr.Decimal { return r.RoundStop(p, ibkr.OrderSideSell) }, "10.005", "10"},
+		{"trigger buy", func(p ibkr.Decimal) ibkr.Decimal { return r.RoundTrigger(p, ibkr.OrderSideBuy) }, "10.005", "10"},
+		{"trigger sell", func(p ibkr.Decimal) ibkr.Decimal { return r.RoundTrigger(p, ibkr.OrderSideSell) }, "10.005", "10.01"},
+		{"trailing amount", func(p ibkr.Decimal) ibkr.Decimal { return r.RoundTrailingAmount(p, d("100")) }, "0.254", "0.25"},
+		{"trailing amount minimum", func(p ibkr.Decimal) ibkr.Decimal { return r.RoundTrailingAmount(p, d("100")) }, "0.001", "0.01"},
+		{"trailing amount below 1", func(p ibkr.Decimal) ibkr.Decimal { return r.RoundTrailingAmount(p, d("0.5")) }, "0.00123", "0.0012"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.round(d(tt.price)); got.String() != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if err := r.Validate(d("187.255")); !errors.Is(err, ibkr.ErrPriceIncrement) {
+		t.Errorf("Validate got %v, want ErrPriceIncrement", err)
+	}
+	if err := r.Validate(d("0.1234")); err != nil {
+		t.Errorf("Validate got %v for a valid price", err)
+	}
+	if got := ibkr.NewPriceRounder(&ibkr.ContractRules{}).RoundNearest(d("1.23456")); got.String() != "1.23456" {
+		t.Errorf("a rounder without rules changed the price to %s", got)
+	}
+	if got := ibkr.NewPriceRounder(&ibkr.ContractRules{Increment: 0.25}).RoundNearest(d("4.9")); got.String() != "5" {
+		t.Errorf("single increment got %s, want 5", got)
+	}
+}
+
+func TestPriceRounderRoundOrder(t *testing.T) {
+	tests := []struct {
+		name         string
+		param        ibkr.PlaceOrderParam
+		last         string
+		wantPrice    string
+		wantAuxPrice string
+		wantTrailing string
+	}{
+		{
+			name:      "LMT buy",
+			param:     ibkr.PlaceOrderParam{Side: "BUY", OrderType: ibkr.OrderTypeLimit, Price: decimalPtr("10.005")},
+			wantPrice: "10",
+		},
+		{
+			name:      "STP sell",
+			param:     ibkr.PlaceOrderParam{Side: "SELL", OrderType: ibkr.OrderTypeStop, Price: decimalPtr("10.005")},
+			wantPrice: "10",
+		},
+		{
+			name:      "STP PRT buy",
+			param:     ibkr.PlaceOrderParam{Side: "buy", OrderType: ibkr.OrderTypeStopWithProtection, Price: decimalPtr("10.005")},
+			wantPrice: "10.01",
+		},
+		{
+			name:      "MIT buy",
+			param:     ibkr.PlaceOrderParam{Side: "BUY", OrderType: ibkr.OrderTypeMarketIfTouch, Price: decimalPtr("10.005")},
+			wantPrice: "10",
+		},
+		{
+			name:      "MIT sell",
+			param:     ibkr.PlaceOrderParam{Side: "SELL", OrderType: ibkr.OrderTypeMarketIfTouch, Price: decimalPtr("10.005")},
+			wantPrice: "10.01",
+		},
+		{
+			name:         "STP LMT sell",
+			param:        ibkr.PlaceOrderParam{Side: "SELL", OrderType: ibkr.OrderTypeStopLimit, Price: decimalPtr("9.995"), AuxPrice: decimalPtr("10.005")},
+			wantPrice:    "10",
+			wantAuxPrice: "10",
+		},
+		{
+			name:         "LIT buy",
+			param:        ibkr.PlaceOrderParam{Side: "BUY", OrderType: ibkr.OrderTypeLimitIfTouched, Price: decimalPtr("10.015"), AuxPrice: decimalPtr("10.005")},
+			wantPrice:    "10.01",
+			wantAuxPrice: "10",
+		},
+		{
+			name:         "LIT sell",
+			param:        ibkr.PlaceOrderParam{Side: "SELL", OrderType: ibkr.OrderTypeLimitIfTouched, Price: decimalPtr("9.995"), AuxPrice: decimalPtr("10.005")},
+			wantPrice:    "10",
+			wantAuxPrice: "10.01",
+		},
+		{
+			name:         "TRAIL amount at the band of last",
+			param:        ibkr.PlaceOrderParam{Side: "SELL", OrderType: ibkr.OrderTypeTrailing, TrailingAmount: decimalPtr("0.1234"), TrailingType: ibkr.TrailingTypeAmount},
+			last:         "187",
+			wantTrailing: "0.12",
+		},
+		{
+			name:         "TRAIL percent",
+			param:        ibkr.PlaceOrderParam{Side: "SELL", OrderType: ibkr.OrderTypeTrailing, TrailingAmount: decimalPtr("1.2345"), TrailingType: ibkr.TrailingTypePercent},
+			last:         "187",
+			wantTrailing: "1.2345",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			param := tt.param
+			var last ibkr.Decimal
+			if tt.last != "" {
+				last = d(tt.last)
+			}
+			stockRounder().RoundOrder(&param, last)
+			for _, field := range []struct {
+				name  string
+				value *ibkr.Decimal
+				want  string
+			}{{"price", param.Price, tt.wantPrice}, {"auxPrice", param.AuxPrice, tt.wantAuxPrice}, {"trailingAmt", param.TrailingAmount, tt.wantTrailing}} {
+				got := ""
+				if field.value != nil {
+					got = field.value.String()
+				}
+				if got != field.want {
+					t.Errorf("%s got %q, want %q", field.name, got, field.want)
+				}
+			}
+		})
+	}
+}
+
+func TestPriceRounderFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		rounder *ibkr.PriceRounder
+		price   string
+		want    string
+	}{
+		{"increment digits", stockRounder(), "187.5", "187.50"},
+		{"increment digits below 1", stockRounder(), "0.5", "0.5000"},
+		{"display rule", stockRounder().WithDisplayRule(ibkr.DisplayRule{DisplayRuleStep: ibkr.DisplayRuleStep{DecimalDigits: 3}}), "187.5", "187.500"},
+		{"magnification", stockRounder().WithDisplayRule(ibkr.DisplayRule{Magnification: 2, DisplayRuleStep: ibkr.DisplayRuleStep{DecimalDigits: 1}}), "0.995", "99.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rounder.Format(d(tt.price)); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadPriceRounder(t *testing.T) {
+	server := ibkrtest.NewServer(t)
+	server.Handle(http.MethodGet, "/trsrv/secdef", func(req ibkrtest.Request) (int, interface{}) {
+		if req.Query.Get("conids") != "265598" {
+			return http.StatusOK, ibkr.SearchSecurityDefinitionResponse{}
+		}
+		return http.StatusOK, ibkr.SearchSecurityDefinitionResponse{SecurityDefinitions: []ibkr.SearchSecurityDefinitionItem{
+			{ContractId: 265598, IncrementRules: stockRules},
+		}}
+	})
+	server.SetContractRules(8314, ibkr.ContractRules{Increment: 0.05})
+	service := server.Client().Service().Contract()
+
+	tests := []struct {
+		contractId int
+		price      string
+		want       string
+	}{
+		{265598, "0.5", "0.0001"},
+		{265598, "187", "0.01"},
+		{8314, "187", "0.05"},
+	}
+	for _, tt := range tests {
+		rounder, err := ibkr.LoadPriceRounder(service, tt.contractId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := rounder.Increment(d(tt.price)); got.String() != tt.want {
+			t.Errorf("conid %d increment at %s got %s, want %s", tt.contractId, tt.price, got, tt.want)
+		}
+	}
+	server.AssertCallCount(t, http.MethodPost, "/iserver/contract/rules", 1)
+}
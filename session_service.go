@@ -7,6 +7,8 @@ import (
 type SessionServiceI interface {
 	PostAuthStatus() (*AuthStatusInfo, error)
 	PostPingServer() (*PingServerResponse, error)
+	PostReauthenticate() (*ReauthenticateResponse, error)
+	PostLogout() (*LogoutResponse, error)
 }
 
 // SessionService :
@@ -27,6 +29,14 @@ type PingServerResponse struct {
 	IServer    IServerInfo       `json:"iserver"`
 }
 
+type ReauthenticateResponse struct {
+	Message string `json:"message"`
+}
+
+type LogoutResponse struct {
+	Status bool `json:"status"`
+}
+
 type ServerInfo struct {
 	ServerName    string `json:"serverName"`
 	ServerVersion string `json:"serverVersion"`
@@ -55,7 +65,7 @@ func (s *SessionService) PostAuthStatus() (*AuthStatusInfo, error) {
 		return nil, err
 	}
 
-	if err := s.client.postJSON("/iserver/auth/status", body, &res); err != nil {
+	if err := s.client.postJSON(newOperation(OperationNamePostAuthStatus, "/iserver/auth/status"), body, &res); err != nil {
 		return nil, err
 	}
 
@@ -74,7 +84,45 @@ func (s *SessionService) PostPingServer() (*PingServerResponse, error) {
 		return nil, err
 	}
 
-	if err := s.client.postJSON("/tickle", body, &res); err != nil {
+	if err := s.client.postJSON(newOperation(OperationNamePostPingServer, "/tickle"), body, &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (s *SessionService) PostReauthenticate() (*ReauthenticateResponse, error) {
+
+	var (
+		res ReauthenticateResponse
+	)
+
+	param := map[string]string{}
+	body, err := json.Marshal(param)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.client.postJSON(newOperation(OperationNamePostReauthenticate, "/iserver/reauthenticate"), body, &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (s *SessionService) PostLogout() (*LogoutResponse, error) {
+
+	var (
+		res LogoutResponse
+	)
+
+	param := map[string]string{}
+	body, err := json.Marshal(param)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.client.postJSON(newOperation(OperationNamePostLogout, "/logout"), body, &res); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,114 @@
+package ibkr
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// TLSOptions :
+// Trust settings for the Client Portal gateway, which serves a self-signed
+// certificate by default.
+type TLSOptions struct {
+	// CAFile and CAPEM hold PEM certificates trusted instead of the system roots.
+	CAFile string
+	CAPEM  []byte
+	// PinnedSHA256 are SHA-256 fingerprints of the gateway certificate in hex,
+	// colons allowed. Without a CA the chain is not verified, the pin is.
+	PinnedSHA256 []string
+	// ServerName overrides the name verified against the certificate.
+	ServerName string
+
+	// ClientCertFile and ClientKeyFile are a PEM key pair for mTLS.
+	ClientCertFile     string
+	ClientKeyFile      string
+	ClientCertificates []tls.Certificate
+
+	// InsecureSkipVerify disables every check. DO NOT USE IN PRODUCTION
+	InsecureSkipVerify bool
+}
+
+// ErrCertificateNotPinned :
+var ErrCertificateNotPinned = errors.New("gateway certificate does not match any pinned fingerprint")
+
+// NewTLSConfig :
+func NewTLSConfig(options TLSOptions) (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         options.ServerName,
+		InsecureSkipVerify: options.InsecureSkipVerify,
+	}
+
+	if options.CAFile != "" || len(options.CAPEM) > 0 {
+		pool := x509.NewCertPool()
+		if options.CAFile != "" {
+			pem, err := os.ReadFile(options.CAFile)
+			if err != nil {
+				return nil, err
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificate found in %s", options.CAFile)
+			}
+		}
+		if len(options.CAPEM) > 0 && !pool.AppendCertsFromPEM(options.CAPEM) {
+			return nil, errors.New("no certificate found in CA PEM")
+		}
+		config.RootCAs = pool
+	}
+
+	if len(options.PinnedSHA256) > 0 {
+		pins := map[string]bool{}
+		for _, pin := range options.PinnedSHA256 {
+			fingerprint, err := parseFingerprint(pin)
+			if err != nil {
+				return nil, err
+			}
+			pins[fingerprint] = true
+		}
+		if config.RootCAs == nil {
+			// a pinned self-signed certificate can not chain to a CA
+			config.InsecureSkipVerify = true
+		}
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 {
+				return ErrCertificateNotPinned
+			}
+			if !pins[CertificateFingerprint(state.PeerCertificates[0])] {
+				return ErrCertificateNotPinned
+			}
+			return nil
+		}
+	}
+
+	config.Certificates = append(config.Certificates, options.ClientCertificates...)
+	if options.ClientCertFile != "" || options.ClientKeyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(options.ClientCertFile, options.ClientKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = append(config.Certificates, certificate)
+	}
+
+	return config, nil
+}
+
+// CertificateFingerprint :
+// Returns the SHA-256 fingerprint of a DER certificate as used by PinnedSHA256.
+func CertificateFingerprint(certificate *x509.Certificate) string {
+	sum := sha256.Sum256(certificate.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+func parseFingerprint(pin string) (string, error) {
+	fingerprint := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(pin), ":", ""))
+	decoded, err := hex.DecodeString(fingerprint)
+	if err != nil || len(decoded) != sha256.Size {
+		return "", fmt.Errorf("invalid SHA-256 fingerprint %q", pin)
+	}
+	return fingerprint, nil
+}
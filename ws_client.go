@@ -1,24 +1,49 @@
 package ibkr
 
-import "log"
+import (
+	"crypto/tls"
+	"log/slog"
+	"net/http"
+	"time"
+)
 
 const (
-	DefaultWebsocketBaseURL        = "wss://localhost:5000"
-	DefaultWebsocketPrefixEndpoint = "/v1/api/ws"
+	DefaultWebsocketBaseURL         = "wss://localhost:5000"
+	DefaultWebsocketPrefixEndpoint  = "/v1/api/ws"
+	DefaultWebsocketShutdownTimeout = time.Second
 )
 
 // WebSocketClient :
 type WebSocketClient struct {
 	debug          bool
-	logger         *log.Logger
+	logger         *slog.Logger
 	baseURL        string
 	prefixEndpoint string
 	skipTLSVerify  bool
+	tlsConfig      *tls.Config
+
+	shutdownTimeout time.Duration
+
+	// restClient fetches the session token when none is given
+	restClient *Client
+	header     http.Header
+
+	hooks WebsocketHooks
 }
 
-func (c *WebSocketClient) debugf(format string, v ...interface{}) {
+// log :
+// Returns the client logger, falling back to the package logger.
+func (c *WebSocketClient) log() *slog.Logger {
+	if c.logger != nil {
+		return c.logger
+	}
+	return packageLogger()
+}
+
+// logMessage :
+func (c *WebSocketClient) logMessage(service string, message []byte) {
 	if c.debug {
-		c.logger.Printf(format, v...)
+		c.log().Debug("websocket message", "service", service, "message", Redact(string(message)))
 	}
 }
 
@@ -33,10 +58,11 @@ func NewWebsocketClient(wsBaseUrl string, wsPrefixEndpoint string, skipTlsVerify
 		prefixEndpoint = wsPrefixEndpoint
 	}
 	return &WebSocketClient{
-		logger:         newDefaultLogger(),
 		baseURL:        baseUrl,
 		prefixEndpoint: prefixEndpoint,
 		skipTLSVerify:  skipTlsVerify,
+
+		shutdownTimeout: DefaultWebsocketShutdownTimeout,
 	}
 }
 
@@ -46,13 +72,17 @@ func NewDefaultWebsocketClient() *WebSocketClient {
 }
 
 // WithDebug :
+// Logs redacted messages at debug level. Without a logger they go to stderr.
 func (c *WebSocketClient) WithDebug(debug bool) *WebSocketClient {
 	c.debug = debug
+	if debug && c.logger == nil {
+		c.logger = newDefaultLogger()
+	}
 	return c
 }
 
 // WithLogger :
-func (c *WebSocketClient) WithLogger(logger *log.Logger) *WebSocketClient {
+func (c *WebSocketClient) WithLogger(logger *slog.Logger) *WebSocketClient {
 	c.debug = true
 	c.logger = logger
 	return c
@@ -76,5 +106,37 @@ func (c *WebSocketClient) WithSkipTLSVersify(skipTlsVerify bool) *WebSocketClien
 	return c
 }
 
+// WithTLSConfig :
+// Uses config for the handshake instead of the skipTLSVerify switch, see NewTLSConfig.
+func (c *WebSocketClient) WithTLSConfig(config *tls.Config) *WebSocketClient {
+	c.tlsConfig = config
+	return c
+}
+
+// WithShutdownTimeout :
+// Sets how long Shutdown waits for the read loop to exit.
+func (c *WebSocketClient) WithShutdownTimeout(timeout time.Duration) *WebSocketClient {
+	c.shutdownTimeout = timeout
+	return c
+}
+
+// WithRESTClient :
+// Sets the REST client used to fetch the session token from /tickle when a
+// connection is opened without one.
+func (c *WebSocketClient) WithRESTClient(client *Client) *WebSocketClient {
+	c.restClient = client
+	return c
+}
+
+// WithHeader :
+// Adds a header sent with every handshake, e.g. an OAuth Authorization header.
+func (c *WebSocketClient) WithHeader(key, value string) *WebSocketClient {
+	if c.header == nil {
+		c.header = http.Header{}
+	}
+	c.header.Add(key, value)
+	return c
+}
+
 // ErrHandler :
 type ErrHandler func(isWebsocketClosed bool, err error)
@@ -1,21 +1,23 @@
 package ibkr
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
-	"github.com/gorilla/websocket"
+	"errors"
 	"net"
 	"net/http"
-	"net/http/cookiejar"
-	"net/url"
+	"time"
+
+	"github.com/gorilla/websocket"
 )
 
 // WebsocketClientServiceI :
 type WebsocketClientServiceI interface {
-	Public(sessionToken string) (*WebsocketPublicService, error)
-	PublicWithSourceIP(sessionToken string, sourceIP string) (*WebsocketPublicService, error)
-	Private(sessionToken string) (*WebsocketPrivateService, error)
-	PrivateWithSourceIP(sessionToken string, sourceIP string) (*WebsocketPrivateService, error)
+	Public(sessionToken string) (WebsocketPublicServiceI, error)
+	PublicWithSourceIP(sessionToken string, sourceIP string) (WebsocketPublicServiceI, error)
+	Private(sessionToken string) (WebsocketPrivateServiceI, error)
+	PrivateWithSourceIP(sessionToken string, sourceIP string) (WebsocketPrivateServiceI, error)
 }
 
 // WebsocketClientService :
@@ -24,115 +26,132 @@ type WebsocketClientService struct {
 }
 
 // Service :
-func (c *WebSocketClient) Service() *WebsocketClientService {
+func (c *WebSocketClient) Service() WebsocketClientServiceI {
 	return &WebsocketClientService{c}
 }
 
 // Public :
-func (s *WebsocketClientService) Public(sessionToken string) (*WebsocketPublicService, error) {
-	url1 := s.client.baseURL + s.client.prefixEndpoint
-	dialer := generateCustomDialer(s.client.skipTLSVerify, "")
+// An empty sessionToken is fetched from /tickle through the REST client set
+// with WithRESTClient.
+func (s *WebsocketClientService) Public(sessionToken string) (WebsocketPublicServiceI, error) {
+	return s.PublicWithSourceIP(sessionToken, "")
+}
 
-	value := map[string]string{}
-	value["session"] = sessionToken
-	cv, _ := json.Marshal(value)
-
-	serverURL, _ := url.Parse(s.client.baseURL)
-	cookie := &http.Cookie{
-		Name:   "api",
-		Value:  string(cv),
-		Path:   "/",
-		Domain: serverURL.Host,
-	}
-	jar, _ := cookiejar.New(nil)
-	jar.SetCookies(serverURL, []*http.Cookie{cookie})
-	dialer.Jar = jar
-	requestHeader := makeRequestHeader(sessionToken)
-	c, _, err := dialer.Dial(url1, requestHeader)
+// PublicWithSourceIP :
+func (s *WebsocketClientService) PublicWithSourceIP(sessionToken, sourceIP string) (WebsocketPublicServiceI, error) {
+	c, err := s.dial("public", sessionToken, sourceIP)
 	if err != nil {
 		return nil, err
 	}
+	return newWebsocketPublicService(s.client, c), nil
+}
 
-	//// TODO
-	//login := fmt.Sprintf("{\"session\":\"%s\"}", sessionToken)
-	//c.WriteMessage(websocket.TextMessage, []byte(login))
-	return &WebsocketPublicService{
-		client:     s.client,
-		connection: c,
-	}, nil
+// Private :
+// An empty sessionToken is fetched from /tickle through the REST client set
+// with WithRESTClient.
+func (s *WebsocketClientService) Private(sessionToken string) (WebsocketPrivateServiceI, error) {
+	return s.PrivateWithSourceIP(sessionToken, "")
 }
 
-// PublicWithSourceIP :
-func (s *WebsocketClientService) PublicWithSourceIP(sessionToken, sourceIP string) (*WebsocketPublicService, error) {
-	url := s.client.baseURL + s.client.prefixEndpoint
-	dialer := generateCustomDialer(s.client.skipTLSVerify, sourceIP)
-	requestHeader := makeRequestHeader(sessionToken)
-	c, _, err := dialer.Dial(url, requestHeader)
+// PrivateWithSourceIP :
+func (s *WebsocketClientService) PrivateWithSourceIP(sessionToken, sourceIP string) (WebsocketPrivateServiceI, error) {
+	c, err := s.dial("private", sessionToken, sourceIP)
 	if err != nil {
 		return nil, err
 	}
-	return &WebsocketPublicService{
-		client:     s.client,
-		connection: c,
-	}, nil
+	return newWebsocketPrivateService(s.client, c), nil
 }
 
-// Private :
-func (s *WebsocketClientService) Private(sessionToken string) (*WebsocketPrivateService, error) {
-	url := s.client.baseURL + s.client.prefixEndpoint
-	dialer := generateCustomDialer(s.client.skipTLSVerify, "")
-	requestHeader := makeRequestHeader(sessionToken)
-	c, _, err := dialer.Dial(url, requestHeader)
+// dial :
+// Opens an authenticated connection with a dialer of its own, so source IP
+// and TLS settings never leak between connections.
+func (s *WebsocketClientService) dial(service, sessionToken, sourceIP string) (*websocket.Conn, error) {
+	c, err := s.connect(sessionToken, sourceIP)
+	if s.client.hooks.OnConnect != nil {
+		s.client.hooks.OnConnect(service, err)
+	}
+	return c, err
+}
+
+func (s *WebsocketClientService) connect(sessionToken, sourceIP string) (*websocket.Conn, error) {
+	if sessionToken == "" {
+		token, err := s.fetchSessionToken()
+		if err != nil {
+			return nil, err
+		}
+		sessionToken = token
+	}
+
+	requestHeader, err := makeRequestHeader(s.client.header, sessionToken)
 	if err != nil {
 		return nil, err
 	}
-	return &WebsocketPrivateService{
-		client:     s.client,
-		connection: c,
-	}, nil
-}
 
-// PrivateWithSourceIP :
-func (s *WebsocketClientService) PrivateWithSourceIP(sessionToken, sourceIP string) (*WebsocketPrivateService, error) {
-	url := s.client.baseURL + s.client.prefixEndpoint
-	dialer := generateCustomDialer(s.client.skipTLSVerify, sourceIP)
-	requestHeader := makeRequestHeader(sessionToken)
-	c, _, err := dialer.Dial(url, requestHeader)
+	tlsConfig := s.client.tlsConfig.Clone()
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{InsecureSkipVerify: s.client.skipTLSVerify}
+	}
+	dialer := newDialer(tlsConfig, sourceIP)
+	c, _, err := dialer.Dial(s.client.baseURL+s.client.prefixEndpoint, requestHeader)
 	if err != nil {
 		return nil, err
 	}
-	return &WebsocketPrivateService{
-		client:     s.client,
-		connection: c,
-	}, nil
+	return c, nil
 }
 
-func generateCustomDialer(skipTlsVerify bool, sourceIP string) *websocket.Dialer {
-	dialer := websocket.DefaultDialer
-	tlsConfig := tls.Config{
-		InsecureSkipVerify: skipTlsVerify,
+func (s *WebsocketClientService) fetchSessionToken() (string, error) {
+	if s.client.restClient == nil {
+		return "", ErrSessionTokenRequired
 	}
-	dialer.TLSClientConfig = &tlsConfig
-	if sourceIP != "" {
-		dialer.NetDial = func(network, addr string) (net.Conn, error) {
-			localAddr, err := net.ResolveTCPAddr(network, sourceIP+":0")
-			if err != nil {
-				return nil, err
-			}
+	resp, err := s.client.restClient.Service().Session().PostPingServer()
+	if err != nil {
+		return "", err
+	}
+	if resp.Session == "" {
+		return "", ErrSessionTokenRequired
+	}
+	return resp.Session, nil
+}
+
+// ErrSessionTokenRequired :
+// No session token was given and none could be fetched from /tickle.
+var ErrSessionTokenRequired = errors.New("websocket session token required")
 
-			remoteAddr, err := net.ResolveTCPAddr(network, addr)
+func newDialer(tlsConfig *tls.Config, sourceIP string) *websocket.Dialer {
+	dialer := &websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: 45 * time.Second,
+		TLSClientConfig:  tlsConfig,
+	}
+	if sourceIP != "" {
+		dialer.NetDialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			localAddr, err := net.ResolveTCPAddr(network, net.JoinHostPort(sourceIP, "0"))
 			if err != nil {
 				return nil, err
 			}
-
-			return net.DialTCP(network, localAddr, remoteAddr)
+			netDialer := &net.Dialer{LocalAddr: localAddr}
+			return netDialer.DialContext(ctx, network, addr)
 		}
 	}
 	return dialer
 }
 
-func makeRequestHeader(sessionToken string) http.Header {
-	httpHeader := http.Header{}
-	//httpHeader.Add("origin", "interactivebrokers.github.io")
-	return httpHeader
+// makeRequestHeader :
+// Sets the api session cookie on top of the configured headers, e.g. OAuth
+// authorization.
+func makeRequestHeader(header http.Header, sessionToken string) (http.Header, error) {
+	httpHeader := header.Clone()
+	if httpHeader == nil {
+		httpHeader = http.Header{}
+	}
+
+	value := map[string]string{}
+	value["session"] = sessionToken
+	cv, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	// set as is, http.Cookie would strip the quotes of the JSON value
+	httpHeader.Add("Cookie", "api="+string(cv))
+	return httpHeader, nil
 }
@@ -0,0 +1,217 @@
+package ibkr
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+type DeliveryPolicy string
+
+const (
+	// DeliveryPolicyBlock queues messages and waits for room when the queue is
+	// full. A consumer that falls behind by a whole queue stalls the read loop.
+	DeliveryPolicyBlock = DeliveryPolicy("block")
+	// DeliveryPolicyDropOldest discards the oldest queued message to make room.
+	DeliveryPolicyDropOldest = DeliveryPolicy("dropOldest")
+	// DeliveryPolicyDropNewest discards the incoming message when the queue is full.
+	DeliveryPolicyDropNewest = DeliveryPolicy("dropNewest")
+	// DeliveryPolicyConflateLatest keeps only the latest message queued.
+	DeliveryPolicyConflateLatest = DeliveryPolicy("conflateLatest")
+)
+
+// DefaultDeliveryPolicy :
+// Applies when DeliveryOptions.Policy is empty, so no message is lost unless
+// a topic opts in to dropping with SetDeliveryOptions. Order and trade updates
+// in particular must not be discarded.
+const DefaultDeliveryPolicy = DeliveryPolicyBlock
+
+const DefaultDeliveryBufferSize = 256
+
+// ErrDeliveryPolicy :
+// Returned for a DeliveryOptions.Policy that is not one of the DeliveryPolicy constants.
+var ErrDeliveryPolicy = errors.New("unknown delivery policy")
+
+// DeliveryOptions :
+type DeliveryOptions struct {
+	Policy DeliveryPolicy
+	// BufferSize is the queue size in front of handler callbacks. Unsolicited
+	// channels are sized by the caller and ignore it.
+	BufferSize int
+}
+
+func (o DeliveryOptions) validate() error {
+	switch o.Policy {
+	case "", DeliveryPolicyBlock, DeliveryPolicyDropOldest, DeliveryPolicyDropNewest, DeliveryPolicyConflateLatest:
+		return nil
+	}
+	return fmt.Errorf("%w: %q", ErrDeliveryPolicy, o.Policy)
+}
+
+func (o DeliveryOptions) policy() DeliveryPolicy {
+	if o.Policy == "" {
+		return DefaultDeliveryPolicy
+	}
+	return o.Policy
+}
+
+func (o DeliveryOptions) blocking() bool {
+	return o.policy() == DeliveryPolicyBlock
+}
+
+func (o DeliveryOptions) bufferSize() int {
+	if o.Policy == DeliveryPolicyConflateLatest {
+		return 1
+	}
+	if o.BufferSize <= 0 {
+		return DefaultDeliveryBufferSize
+	}
+	return o.BufferSize
+}
+
+// DeliveryStats :
+type DeliveryStats struct {
+	Delivered uint64
+	Dropped   uint64
+}
+
+type deliveryCounters struct {
+	delivered atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+func (c *deliveryCounters) stats() DeliveryStats {
+	return DeliveryStats{
+		Delivered: c.delivered.Load(),
+		Dropped:   c.dropped.Load(),
+	}
+}
+
+// offer :
+// Sends value to channel without waiting, following one of the dropping
+// policies, and returns how many messages were dropped.
+func offer[T any](channel chan T, value T, policy DeliveryPolicy) uint64 {
+	if policy == DeliveryPolicyDropNewest || cap(channel) == 0 {
+		// for an unbuffered channel nothing is queued that could be dropped
+		select {
+		case channel <- value:
+			return 0
+		default:
+			return 1
+		}
+	}
+
+	var dropped uint64
+	if policy == DeliveryPolicyConflateLatest {
+		for drained := false; !drained; {
+			select {
+			case <-channel:
+				dropped++
+			default:
+				drained = true
+			}
+		}
+	}
+	for {
+		select {
+		case channel <- value:
+			return dropped
+		default:
+		}
+		select {
+		case <-channel:
+			dropped++
+		default:
+		}
+	}
+}
+
+type websocketDelivery struct {
+	topic   WebsocketTopic
+	message []byte
+}
+
+// websocketSubscriber :
+// Runs handler on its own goroutine fed by a bounded queue, or on the read
+// goroutine for direct subscribers, which leave policy and accounting to
+// their handler. The queue is never closed, so delivering can not race with
+// close; done stops the goroutine and any delivery waiting for room.
+type websocketSubscriber struct {
+	handler  websocketRouteHandler
+	options  DeliveryOptions
+	counters *deliveryCounters
+	onError  func(topic WebsocketTopic, message []byte, err error)
+	// unsubscribe is sent on shutdown while the subscriber is registered
+	unsubscribe string
+
+	queue     chan websocketDelivery
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newWebsocketSubscriber(
+	handler websocketRouteHandler,
+	options DeliveryOptions,
+	counters *deliveryCounters,
+	onError func(WebsocketTopic, []byte, error),
+) *websocketSubscriber {
+	subscriber := &websocketSubscriber{
+		handler:  handler,
+		options:  options,
+		counters: counters,
+		onError:  onError,
+		queue:    make(chan websocketDelivery, options.bufferSize()),
+		done:     make(chan struct{}),
+	}
+	go subscriber.run()
+	return subscriber
+}
+
+func newDirectSubscriber(handler websocketRouteHandler) *websocketSubscriber {
+	return &websocketSubscriber{handler: handler, done: make(chan struct{})}
+}
+
+func (s *websocketSubscriber) deliver(topic WebsocketTopic, message []byte) error {
+	if s.queue == nil {
+		return s.handler(topic, message)
+	}
+
+	select {
+	case <-s.done:
+		return nil
+	default:
+	}
+	delivery := websocketDelivery{topic, message}
+	if s.options.blocking() {
+		select {
+		case s.queue <- delivery:
+		case <-s.done:
+		}
+		return nil
+	}
+	if dropped := offer(s.queue, delivery, s.options.policy()); dropped > 0 {
+		s.counters.dropped.Add(dropped)
+	}
+	return nil
+}
+
+func (s *websocketSubscriber) run() {
+	for {
+		select {
+		case <-s.done:
+			return
+		case delivery := <-s.queue:
+			s.counters.delivered.Add(1)
+			if err := s.handler(delivery.topic, delivery.message); err != nil && s.onError != nil {
+				s.onError(delivery.topic, delivery.message, err)
+			}
+		}
+	}
+}
+
+func (s *websocketSubscriber) close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+}
@@ -0,0 +1,213 @@
+package ibkr
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOffer(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      DeliveryPolicy
+		capacity    int
+		queued      []int
+		wantQueue   []int
+		wantDropped uint64
+	}{
+		{"drop newest with room", DeliveryPolicyDropNewest, 2, []int{1}, []int{1, 9}, 0},
+		{"drop newest when full", DeliveryPolicyDropNewest, 2, []int{1, 2}, []int{1, 2}, 1},
+		{"drop oldest with room", DeliveryPolicyDropOldest, 2, []int{1}, []int{1, 9}, 0},
+		{"drop oldest when full", DeliveryPolicyDropOldest, 2, []int{1, 2}, []int{2, 9}, 1},
+		{"conflate", DeliveryPolicyConflateLatest, 3, []int{1, 2}, []int{9}, 2},
+		{"unbuffered", DeliveryPolicyDropOldest, 0, nil, nil, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			channel := make(chan int, tt.capacity)
+			for _, value := range tt.queued {
+				channel <- value
+			}
+			if dropped := offer(channel, 9, tt.policy); dropped != tt.wantDropped {
+				t.Errorf("dropped %d, want %d", dropped, tt.wantDropped)
+			}
+			close(channel)
+			var queue []int
+			for value := range channel {
+				queue = append(queue, value)
+			}
+			if !reflect.DeepEqual(queue, tt.wantQueue) {
+				t.Errorf("queue %v, want %v", queue, tt.wantQueue)
+			}
+		})
+	}
+}
+
+func TestDeliveryOptionsValidate(t *testing.T) {
+	tests := []struct {
+		policy  DeliveryPolicy
+		wantErr bool
+	}{
+		{"", false},
+		{DeliveryPolicyBlock, false},
+		{DeliveryPolicyDropOldest, false},
+		{DeliveryPolicyDropNewest, false},
+		{DeliveryPolicyConflateLatest, false},
+		{"dropoldest", true},
+		{"latest", true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			err := DeliveryOptions{Policy: tt.policy}.validate()
+			if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrDeliveryPolicy)) {
+				t.Errorf("got %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	router := newWebsocketRouter()
+	if err := router.setDeliveryOptions("smd", DeliveryOptions{Policy: "latest"}); !errors.Is(err, ErrDeliveryPolicy) {
+		t.Errorf("setDeliveryOptions got %v, want ErrDeliveryPolicy", err)
+	}
+	_, err := NewSubscription(DeliveryOptions{Policy: "latest"}, func(func(int) error) (func() error, error) {
+		t.Error("subscribed with an unknown policy")
+		return nil, nil
+	})
+	if !errors.Is(err, ErrDeliveryPolicy) {
+		t.Errorf("NewSubscription got %v, want ErrDeliveryPolicy", err)
+	}
+}
+
+// stalledSubscriber returns a subscriber whose handler waits for release,
+// after it took the first message off the queue.
+func stalledSubscriber(options DeliveryOptions) (*websocketSubscriber, *deliveryCounters, chan struct{}) {
+	counters := &deliveryCounters{}
+	started, release := make(chan struct{}), make(chan struct{})
+	first := true
+	subscriber := newWebsocketSubscriber(func(WebsocketTopic, []byte) error {
+		if first {
+			first = false
+			close(started)
+			<-release
+		}
+		return nil
+	}, options, counters, nil)
+	_ = subscriber.deliver(WebsocketTopic{}, nil)
+	<-started
+	return subscriber, counters, release
+}
+
+func TestWebsocketSubscriberSlowConsumer(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     DeliveryOptions
+		wantDropped uint64
+	}{
+		{"drop oldest", DeliveryOptions{Policy: DeliveryPolicyDropOldest, BufferSize: 2}, 3},
+		{"drop newest", DeliveryOptions{Policy: DeliveryPolicyDropNewest, BufferSize: 2}, 3},
+		{"conflate", DeliveryOptions{Policy: DeliveryPolicyConflateLatest}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subscriber, counters, release := stalledSubscriber(tt.options)
+			defer subscriber.close()
+
+			delivered := make(chan struct{})
+			go func() {
+				defer close(delivered)
+				for n := 0; n < 5; n++ {
+					_ = subscriber.deliver(WebsocketTopic{}, nil)
+				}
+			}()
+			select {
+			case <-delivered:
+			case <-time.After(time.Second):
+				t.Fatal("a stalled handler blocked delivery")
+			}
+			if dropped := counters.dropped.Load(); dropped != tt.wantDropped {
+				t.Errorf("dropped %d, want %d", dropped, tt.wantDropped)
+			}
+			close(release)
+		})
+	}
+}
+
+func TestWebsocketSubscriberBlockClose(t *testing.T) {
+	subscriber, _, release := stalledSubscriber(DeliveryOptions{Policy: DeliveryPolicyBlock, BufferSize: 1})
+	defer close(release)
+
+	_ = subscriber.deliver(WebsocketTopic{}, nil)
+	waiting := make(chan struct{})
+	go func() {
+		defer close(waiting)
+		_ = subscriber.deliver(WebsocketTopic{}, nil)
+	}()
+	select {
+	case <-waiting:
+		t.Fatal("block delivered to a full queue")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		subscriber.close()
+	}()
+	for _, done := range []chan struct{}{closed, waiting} {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("close did not release a blocked delivery")
+		}
+	}
+	if err := subscriber.deliver(WebsocketTopic{}, nil); err != nil {
+		t.Errorf("deliver after close: %v", err)
+	}
+}
+
+func TestRouterOrdersLosslessByDefault(t *testing.T) {
+	router := newWebsocketRouter()
+	defer router.close()
+
+	release := make(chan struct{})
+	received := make(chan string, DefaultDeliveryBufferSize*2)
+	router.handle(MessageTopicSubscribeOrder, "", "uor+{}", func(_ WebsocketTopic, message []byte) error {
+		<-release
+		received <- string(message)
+		return nil
+	})
+
+	// more orders than the queue holds, while the handler is stalled
+	count := DefaultDeliveryBufferSize + 10
+	dispatched := make(chan struct{})
+	go func() {
+		defer close(dispatched)
+		for n := 0; n < count; n++ {
+			router.dispatch([]byte(fmt.Sprintf(`{"topic":"sor","args":[{"orderId":%d}]}`, n)))
+		}
+	}()
+	select {
+	case <-dispatched:
+		t.Fatal("a stalled order handler did not hold back the read loop")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	for n := 0; n < count; n++ {
+		want := fmt.Sprintf(`{"topic":"sor","args":[{"orderId":%d}]}`, n)
+		select {
+		case message := <-received:
+			if message != want {
+				t.Fatalf("message %d is %s, want %s", n, message, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("received %d of %d orders", n, count)
+		}
+	}
+	<-dispatched
+	if stats := router.deliveryStats(MessageTopicSubscribeOrder); stats.Dropped != 0 || stats.Delivered != uint64(count) {
+		t.Errorf("stats %+v, want %d delivered and none dropped", stats, count)
+	}
+}
@@ -1,8 +1,41 @@
 package ibkr
 
-import "github.com/gorilla/websocket"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gorilla/websocket"
+)
+
+// ErrWebsocketConnectionClosed :
+// Ends subscriptions whose connection went away before they were closed.
+var ErrWebsocketConnectionClosed = errors.New("websocket connection closed")
+
+// ErrWebsocketShutdownTimeout :
+// The read loop did not exit within the shutdown timeout after Close.
+var ErrWebsocketShutdownTimeout = errors.New("websocket shutdown timed out")
 
 // IsErrWebsocketClosed :
 func IsErrWebsocketClosed(err error) bool {
 	return websocket.IsCloseError(err, websocket.CloseNormalClosure)
 }
+
+// WebsocketMessageError :
+// A decode or handler error of a single message. It is reported through the
+// message error handler and does not close the connection.
+type WebsocketMessageError struct {
+	Topic   WebsocketTopic
+	Message []byte
+	Err     error
+}
+
+func (e *WebsocketMessageError) Error() string {
+	return fmt.Sprintf("websocket message on topic %q: %v", e.Topic.Raw, e.Err)
+}
+
+func (e *WebsocketMessageError) Unwrap() error {
+	return e.Err
+}
+
+// MessageErrorHandler :
+type MessageErrorHandler func(err *WebsocketMessageError)
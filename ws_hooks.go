@@ -0,0 +1,55 @@
+package ibkr
+
+import "strings"
+
+// WebsocketHooks :
+// Observes websocket connections, e.g. for metrics and tracing. Every func is
+// optional and must not block.
+type WebsocketHooks struct {
+	// OnConnect is called after every dial of service "public" or "private".
+	OnConnect func(service string, err error)
+	// OnDisconnect is called when the read loop of Start ends, with the read
+	// error, so subscriptions of the connection are gone.
+	OnDisconnect func(service string, err error)
+	// OnSend is called before a subscribe or unsubscribe message is written;
+	// the returned func, if any, receives the result of the write.
+	OnSend func(service string, topic WebsocketTopic, subscribe bool) func(err error)
+	// OnMessage is called for every received message before it is routed.
+	OnMessage func(service string, topic WebsocketTopic)
+	// OnRead and OnWrite see every raw text message read or written, e.g. for
+	// recording. message must not be retained.
+	OnRead  func(service string, message []byte)
+	OnWrite func(service string, message []byte)
+}
+
+// WithHooks :
+func (c *WebSocketClient) WithHooks(hooks WebsocketHooks) *WebSocketClient {
+	c.hooks = hooks
+	return c
+}
+
+// Hooks :
+// Returns the hooks set with WithHooks, so they can be extended.
+func (c *WebSocketClient) Hooks() WebsocketHooks {
+	return c.hooks
+}
+
+// parseOutgoingTopic :
+// Parses a subscribe message like "smd+265598+{...}", dropping the JSON argument.
+func parseOutgoingTopic(message string) WebsocketTopic {
+	if i := strings.Index(message, "{"); i >= 0 {
+		message = strings.TrimRight(message[:i], "+")
+	}
+	return ParseWebsocketTopic(message)
+}
+
+// sendHook :
+// Reports a text message to OnSend. Topics starting with "s" subscribe, all
+// others ("u...") unsubscribe.
+func (c *WebSocketClient) sendHook(service string, message []byte) func(err error) {
+	if c.hooks.OnSend == nil {
+		return nil
+	}
+	topic := parseOutgoingTopic(string(message))
+	return c.hooks.OnSend(service, topic, strings.HasPrefix(topic.Name, "s"))
+}
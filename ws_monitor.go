@@ -0,0 +1,231 @@
+package ibkr
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultMonitorConnectionStaleAfter = 30 * time.Second
+	DefaultMonitorCheckInterval        = 5 * time.Second
+)
+
+// WebsocketMonitorConfig :
+type WebsocketMonitorConfig struct {
+	// ConnectionStaleAfter flags the connection stale when no system heartbeat
+	// arrived for this long. Defaults to DefaultMonitorConnectionStaleAfter.
+	ConnectionStaleAfter time.Duration
+	// ContractStaleAfter flags a conid stale when no smd update arrived for
+	// this long during its trading session. Zero disables the check.
+	ContractStaleAfter time.Duration
+	// CheckInterval defaults to DefaultMonitorCheckInterval.
+	CheckInterval time.Duration
+	// InTradingSession reports whether contractId trades at t. A nil func
+	// treats every conid as always trading.
+	InTradingSession func(contractId int, t time.Time) bool
+
+	OnConnectionStale func(lastHeartbeat time.Time)
+	OnContractStale   func(contractId int, lastUpdate time.Time)
+	// CloseOnConnectionStale closes the connection once it is stale, so Start
+	// returns and the caller can reconnect.
+	CloseOnConnectionStale bool
+}
+
+// WebsocketMonitor :
+// Tracks system heartbeats and the last message per subscription topic, and
+// flags the connection or single conids as stale. Attach it with SetMonitor
+// before Start; it runs for as long as Start does.
+type WebsocketMonitor struct {
+	config WebsocketMonitorConfig
+
+	mutex           sync.Mutex
+	started         time.Time
+	lastHeartbeat   time.Time
+	lastByTopic     map[string]time.Time
+	lastByContract  map[int]time.Time
+	staleContracts  map[int]bool
+	connectionStale bool
+}
+
+// NewWebsocketMonitor :
+func NewWebsocketMonitor(config WebsocketMonitorConfig) *WebsocketMonitor {
+	if config.ConnectionStaleAfter <= 0 {
+		config.ConnectionStaleAfter = DefaultMonitorConnectionStaleAfter
+	}
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = DefaultMonitorCheckInterval
+	}
+	return &WebsocketMonitor{
+		config:         config,
+		lastByTopic:    map[string]time.Time{},
+		lastByContract: map[int]time.Time{},
+		staleContracts: map[int]bool{},
+	}
+}
+
+// Watch :
+// Starts tracking contractIds now, so a conid that never ticks is flagged too.
+func (m *WebsocketMonitor) Watch(contractIds ...int) {
+	now := time.Now()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for _, contractId := range contractIds {
+		if _, has := m.lastByContract[contractId]; !has {
+			m.lastByContract[contractId] = now
+		}
+	}
+}
+
+// Unwatch :
+func (m *WebsocketMonitor) Unwatch(contractIds ...int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for _, contractId := range contractIds {
+		delete(m.lastByContract, contractId)
+		delete(m.staleContracts, contractId)
+	}
+}
+
+// LastHeartbeat :
+func (m *WebsocketMonitor) LastHeartbeat() time.Time {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.lastHeartbeat
+}
+
+// LastMessage :
+// Returns when a message of the raw topic (e.g. "smd+265598", "sor") was last seen.
+func (m *WebsocketMonitor) LastMessage(topic string) (time.Time, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	last, has := m.lastByTopic[topic]
+	return last, has
+}
+
+// IsConnectionStale :
+func (m *WebsocketMonitor) IsConnectionStale() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.connectionStale
+}
+
+// IsContractStale :
+func (m *WebsocketMonitor) IsContractStale(contractId int) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.staleContracts[contractId]
+}
+
+// observe :
+func (m *WebsocketMonitor) observe(topic WebsocketTopic, message []byte) {
+	now := time.Now()
+
+	var heartbeat bool
+	if topic.Name == UnsolicitedMessageTopicSystemConnection {
+		var resp WebsocketUnsolicitedSystemConnectionResponse
+		heartbeat = json.Unmarshal(message, &resp) == nil && resp.HB != 0
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if heartbeat {
+		m.lastHeartbeat = now
+		m.connectionStale = false
+	}
+	if topic.Raw != "" {
+		m.lastByTopic[topic.Raw] = now
+	}
+	if topic.Name == MessageTopicSubscribeMarketData && len(topic.Args) > 0 {
+		if contractId, err := strconv.Atoi(topic.Args[0]); err == nil {
+			m.lastByContract[contractId] = now
+			delete(m.staleContracts, contractId)
+		}
+	}
+}
+
+// run :
+// Checks staleness until ctx is done. closer closes the monitored connection.
+// Every run starts from a fresh state, so a reconnected connection neither
+// inherits the heartbeat and staleness of the last one nor the times its
+// conids last ticked; watched conids are tracked again from now.
+func (m *WebsocketMonitor) run(ctx context.Context, closer func() error) {
+	m.reset(time.Now())
+
+	ticker := time.NewTicker(m.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if m.check(now) && m.config.CloseOnConnectionStale && closer != nil {
+				_ = closer()
+				return
+			}
+		}
+	}
+}
+
+// reset :
+func (m *WebsocketMonitor) reset(now time.Time) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.started = now
+	m.lastHeartbeat = time.Time{}
+	m.connectionStale = false
+	m.lastByTopic = map[string]time.Time{}
+	m.staleContracts = map[int]bool{}
+	for contractId := range m.lastByContract {
+		m.lastByContract[contractId] = now
+	}
+}
+
+// check :
+// Returns true when the connection just became stale.
+func (m *WebsocketMonitor) check(now time.Time) bool {
+	type staleContract struct {
+		contractId int
+		lastUpdate time.Time
+	}
+
+	m.mutex.Lock()
+	lastHeartbeat := m.lastHeartbeat
+	since := lastHeartbeat
+	if since.IsZero() {
+		since = m.started
+	}
+	connectionStale := !m.connectionStale && now.Sub(since) > m.config.ConnectionStaleAfter
+	if connectionStale {
+		m.connectionStale = true
+	}
+
+	var contracts []staleContract
+	if m.config.ContractStaleAfter > 0 {
+		for contractId, lastUpdate := range m.lastByContract {
+			if m.staleContracts[contractId] || now.Sub(lastUpdate) <= m.config.ContractStaleAfter {
+				continue
+			}
+			if m.config.InTradingSession != nil && !m.config.InTradingSession(contractId, now) {
+				continue
+			}
+			m.staleContracts[contractId] = true
+			contracts = append(contracts, staleContract{contractId, lastUpdate})
+		}
+	}
+	m.mutex.Unlock()
+
+	if connectionStale && m.config.OnConnectionStale != nil {
+		m.config.OnConnectionStale(lastHeartbeat)
+	}
+	if m.config.OnContractStale != nil {
+		for _, contract := range contracts {
+			m.config.OnContractStale(contract.contractId, contract.lastUpdate)
+		}
+	}
+	return connectionStale
+}
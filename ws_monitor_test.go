@@ -0,0 +1,94 @@
+package ibkr
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWebsocketMonitorCheck(t *testing.T) {
+	var staleConnections, staleContracts []time.Time
+	monitor := NewWebsocketMonitor(WebsocketMonitorConfig{
+		ConnectionStaleAfter: time.Minute,
+		ContractStaleAfter:   time.Minute,
+		OnConnectionStale:    func(lastHeartbeat time.Time) { staleConnections = append(staleConnections, lastHeartbeat) },
+		OnContractStale: func(contractId int, lastUpdate time.Time) {
+			staleContracts = append(staleContracts, lastUpdate)
+		},
+	})
+	monitor.reset(time.Now())
+	monitor.Watch(265598)
+	monitor.observe(WebsocketTopic{Name: UnsolicitedMessageTopicSystemConnection, Raw: "system"}, []byte(`{"topic":"system","hb":1}`))
+	if monitor.LastHeartbeat().IsZero() {
+		t.Fatal("heartbeat not seen")
+	}
+
+	later := time.Now().Add(2 * time.Minute)
+	if !monitor.check(later) {
+		t.Error("connection not stale after missing heartbeats")
+	}
+	if monitor.check(later) {
+		t.Error("a stale connection was reported twice")
+	}
+	if !monitor.IsConnectionStale() || !monitor.IsContractStale(265598) {
+		t.Errorf("stale connection %v contract %v", monitor.IsConnectionStale(), monitor.IsContractStale(265598))
+	}
+	if len(staleConnections) != 1 || len(staleContracts) != 1 {
+		t.Errorf("callbacks %d and %d, want 1 and 1", len(staleConnections), len(staleContracts))
+	}
+}
+
+func TestWebsocketMonitorRunResets(t *testing.T) {
+	monitor := NewWebsocketMonitor(WebsocketMonitorConfig{ContractStaleAfter: time.Minute, CheckInterval: time.Hour})
+	monitor.Watch(265598)
+	monitor.observe(WebsocketTopic{Name: UnsolicitedMessageTopicSystemConnection, Raw: "system"}, []byte(`{"topic":"system","hb":1}`))
+	monitor.observe(ParseWebsocketTopic("smd+265598"), []byte(`{"topic":"smd+265598"}`))
+	if !monitor.check(time.Now().Add(time.Hour)) {
+		t.Fatal("connection not stale")
+	}
+
+	// a reconnect runs the monitor again
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	before := time.Now()
+	monitor.run(ctx, nil)
+
+	if monitor.IsConnectionStale() || monitor.IsContractStale(265598) || !monitor.LastHeartbeat().IsZero() {
+		t.Errorf("stale connection %v contract %v heartbeat %v after run", monitor.IsConnectionStale(), monitor.IsContractStale(265598), monitor.LastHeartbeat())
+	}
+	if _, has := monitor.LastMessage("smd+265598"); has {
+		t.Error("the last message of the old connection survived run")
+	}
+	if monitor.check(before.Add(DefaultMonitorConnectionStaleAfter / 2)) {
+		t.Error("the new connection is stale before its heartbeat is due")
+	}
+	if !monitor.check(time.Now().Add(time.Hour)) {
+		t.Error("the new connection never became stale")
+	}
+	if !monitor.IsContractStale(265598) {
+		t.Error("a watched conid was not tracked on the new connection")
+	}
+}
+
+func TestSetMonitorWhileReading(t *testing.T) {
+	service := newWebsocketPublicService(NewWebsocketClient("ws://localhost", "", true), nil)
+	monitor := NewWebsocketMonitor(WebsocketMonitorConfig{})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for n := 0; n < 100; n++ {
+			service.SetMonitor(monitor)
+			service.SetMonitor(nil)
+		}
+		service.SetMonitor(monitor)
+	}()
+	for n := 0; n < 100; n++ {
+		service.router.dispatch([]byte(`{"topic":"system","hb":1}`))
+	}
+	<-done
+	service.router.dispatch([]byte(`{"topic":"system","hb":1}`))
+	if monitor.LastHeartbeat().IsZero() {
+		t.Error("the monitor set last did not see the heartbeat")
+	}
+}
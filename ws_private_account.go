@@ -26,28 +26,28 @@ type WebsocketPrivateAccountLedgerItem struct {
 	Key                       string  `json:"key"`
 	SecondKey                 string  `json:"secondKey,omitempty"`
 	Timestamp                 int64   `json:"timestamp,omitempty"`
-	Dividends                 float64 `json:"dividends,omitempty"`
-	ExchangeRate              float64 `json:"exchangeRate,omitempty"`
-	Funds                     float64 `json:"funds,omitempty"`
+	Dividends                 Decimal `json:"dividends,omitzero"`
+	ExchangeRate              Decimal `json:"exchangeRate,omitzero"`
+	Funds                     Decimal `json:"funds,omitzero"`
 	AccountCode               string  `json:"acctCode,omitempty"`
-	CashBalance               float64 `json:"cashbalance,omitempty"`
-	CashBalanceFXSegment      float64 `json:"cashBalanceFXSegment,omitempty"`
-	CommodityMarketValue      float64 `json:"commodityMarketValue,omitempty"`
-	CorporateBondsMarketValue float64 `json:"corporateBondsMarketValue,omitempty"`
-	MarketValue               float64 `json:"marketValue,omitempty"`
-	OptionMarketValue         float64 `json:"optionMarketValue,omitempty"`
-	Interest                  float64 `json:"interest,omitempty"`
-	IssueOptionsMarketValue   float64 `json:"issueOptionsMarketValue,omitempty"`
-	MoneyFunds                float64 `json:"moneyFunds,omitempty"`
-	NetLiquidationValue       float64 `json:"netLiquidationValue,omitempty"`
-	RealizedPnl               float64 `json:"realizedPnl,omitempty"`
-	UnrealizedPnl             float64 `json:"unrealizedPnl,omitempty"`
-	SettledCash               float64 `json:"settledCash,omitempty"`
+	CashBalance               Decimal `json:"cashbalance,omitzero"`
+	CashBalanceFXSegment      Decimal `json:"cashBalanceFXSegment,omitzero"`
+	CommodityMarketValue      Decimal `json:"commodityMarketValue,omitzero"`
+	CorporateBondsMarketValue Decimal `json:"corporateBondsMarketValue,omitzero"`
+	MarketValue               Decimal `json:"marketValue,omitzero"`
+	OptionMarketValue         Decimal `json:"optionMarketValue,omitzero"`
+	Interest                  Decimal `json:"interest,omitzero"`
+	IssueOptionsMarketValue   Decimal `json:"issueOptionsMarketValue,omitzero"`
+	MoneyFunds                Decimal `json:"moneyFunds,omitzero"`
+	NetLiquidationValue       Decimal `json:"netLiquidationValue,omitzero"`
+	RealizedPnl               Decimal `json:"realizedPnl,omitzero"`
+	UnrealizedPnl             Decimal `json:"unrealizedPnl,omitzero"`
+	SettledCash               Decimal `json:"settledCash,omitzero"`
 	Severity                  float64 `json:"severity,omitempty"`
-	StockMarketValue          float64 `json:"stockMarketValue,omitempty"`
-	TBillsMarketValue         float64 `json:"tBillsMarketValue,omitempty"`
-	TBondsMarketValue         float64 `json:"tBondsMarketValue,omitempty"`
-	WarrantsMarketValue       float64 `json:"warrantsMarketValue,omitempty"`
+	StockMarketValue          Decimal `json:"stockMarketValue,omitzero"`
+	TBillsMarketValue         Decimal `json:"tBillsMarketValue,omitzero"`
+	TBondsMarketValue         Decimal `json:"tBondsMarketValue,omitzero"`
+	WarrantsMarketValue       Decimal `json:"warrantsMarketValue,omitzero"`
 }
 
 type WebsocketPrivateAccountLedgerResponse struct {
@@ -68,20 +68,20 @@ func (s *WebsocketPrivateService) SubscribeAccountSummary(
 		return nil, err
 	}
 
+	remove := s.router.handle(MessageTopicSubscribeAccountSummary, param.AccountId, fmt.Sprintf("usd+%s+{}", param.AccountId), routeHandler(handler))
+
 	args := fmt.Sprintf("ssd+%s+%s", param.AccountId, string(buf))
 
 	if err := s.writeMessage(websocket.TextMessage, []byte(args)); err != nil {
+		remove()
 		return nil, err
 	}
 
-	s.accountSummaryResponseHandler = handler
-
 	return func() error {
-		args := fmt.Sprintf("usd+%s+{}", param.AccountId)
-		if err := s.writeMessage(websocket.TextMessage, []byte(args)); err != nil {
-			return err
+		if !remove() {
+			return nil
 		}
-		return nil
+		return s.writeMessage(websocket.TextMessage, []byte(fmt.Sprintf("usd+%s+{}", param.AccountId)))
 	}, nil
 }
 func (s *WebsocketPrivateService) UnsubscribeAccountSummary(
@@ -91,6 +91,7 @@ func (s *WebsocketPrivateService) UnsubscribeAccountSummary(
 	if err := s.writeMessage(websocket.TextMessage, []byte(args)); err != nil {
 		return err
 	}
+	s.router.remove(MessageTopicSubscribeAccountSummary, param.AccountId)
 	return nil
 }
 
@@ -108,20 +109,20 @@ func (s *WebsocketPrivateService) SubscribeAccountLedger(
 		return nil, err
 	}
 
+	remove := s.router.handle(MessageTopicSubscribeAccountLedger, param.AccountId, fmt.Sprintf("uld+%s+{}", param.AccountId), routeHandler(handler))
+
 	args := fmt.Sprintf("sld+%s+%s", param.AccountId, string(buf))
 
 	if err := s.writeMessage(websocket.TextMessage, []byte(args)); err != nil {
+		remove()
 		return nil, err
 	}
 
-	s.accountLedgerResponseHandler = handler
-
 	return func() error {
-		args := fmt.Sprintf("uld+%s+{}", param.AccountId)
-		if err := s.writeMessage(websocket.TextMessage, []byte(args)); err != nil {
-			return err
+		if !remove() {
+			return nil
 		}
-		return nil
+		return s.writeMessage(websocket.TextMessage, []byte(fmt.Sprintf("uld+%s+{}", param.AccountId)))
 	}, nil
 }
 
@@ -132,5 +133,26 @@ func (s *WebsocketPrivateService) UnsubscribeAccountLedger(
 	if err := s.writeMessage(websocket.TextMessage, []byte(args)); err != nil {
 		return err
 	}
+	s.router.remove(MessageTopicSubscribeAccountLedger, param.AccountId)
 	return nil
 }
+
+// StreamAccountSummary :
+func (s *WebsocketPrivateService) StreamAccountSummary(
+	param WebsocketPrivateAccountSummaryParam,
+	options DeliveryOptions,
+) (*Subscription[WebsocketPrivateAccountSummaryResponse], error) {
+	return subscribe(s.router, options, func(handler func(WebsocketPrivateAccountSummaryResponse) error) (func() error, error) {
+		return s.SubscribeAccountSummary(param, handler)
+	})
+}
+
+// StreamAccountLedger :
+func (s *WebsocketPrivateService) StreamAccountLedger(
+	param WebsocketPrivateAccountLedgerParam,
+	options DeliveryOptions,
+) (*Subscription[WebsocketPrivateAccountLedgerResponse], error) {
+	return subscribe(s.router, options, func(handler func(WebsocketPrivateAccountLedgerResponse) error) (func() error, error) {
+		return s.SubscribeAccountLedger(param, handler)
+	})
+}
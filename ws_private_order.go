@@ -22,8 +22,8 @@ type WebsocketPrivateOrder struct {
 	Ticker             string  `json:"ticker,omitempty"`
 	SecurityType       string  `json:"secType,omitempty"`
 	ListingExchange    string  `json:"listingExchange,omitempty"`
-	RemainingQuantity  float64 `json:"remainingQuantity,omitempty"`
-	FilledQuantity     float64 `json:"filledQuantity,omitempty"`
+	RemainingQuantity  Decimal `json:"remainingQuantity,omitzero"`
+	FilledQuantity     Decimal `json:"filledQuantity,omitzero"`
 	CompanyName        string  `json:"companyName,omitempty"`
 	Status             string  `json:"status,omitempty"`
 	OrderCcpStatus     string  `json:"order_ccp_status,omitempty"`
@@ -37,7 +37,7 @@ type WebsocketPrivateOrder struct {
 	OrderRef           string  `json:"order_ref,omitempty"` // User defined string used to identify the order. Value is set using “cOID” field while placing an order.
 	TimeInForce        string  `json:"timeInForce,omitempty"`
 	Side               string  `json:"side,omitempty"`
-	Price              float64 `json:"price,omitempty"`
+	Price              Decimal `json:"price,omitzero"`
 }
 type WebsocketPrivateOrderResponse struct {
 	Orders   []WebsocketPrivateOrder `json:"orders"`
@@ -47,9 +47,9 @@ type WebsocketPrivateOrderResponse struct {
 type WebsocketPrivateOrderV2 struct {
 	AccountId          string  `json:"acct,omitempty"`
 	Exchange           string  `json:"exchange,omitempty"`
-	ContractIdExchange string     `json:"conidex,omitempty"`
+	ContractIdExchange string  `json:"conidex,omitempty"`
 	ContractId         int     `json:"conid,omitempty"`
-	Account            string `json:"account,omitempty"`
+	Account            string  `json:"account,omitempty"`
 	OrderId            int64   `json:"orderId,omitempty"`
 	CashCcy            string  `json:"cashCcy,omitempty"`
 	SizeAndFills       string  `json:"sizeAndFills,omitempty"`
@@ -58,9 +58,9 @@ type WebsocketPrivateOrderV2 struct {
 	Ticker             string  `json:"ticker,omitempty"`
 	SecurityType       string  `json:"secType,omitempty"`
 	ListingExchange    string  `json:"listingExchange,omitempty"`
-	RemainingQuantity  float64 `json:"remainingQuantity,omitempty"`
-	FilledQuantity     float64 `json:"filledQuantity,omitempty"`
-	TotalSize          float64 `json:"totalSize,omitempty"`
+	RemainingQuantity  Decimal `json:"remainingQuantity,omitzero"`
+	FilledQuantity     Decimal `json:"filledQuantity,omitzero"`
+	TotalSize          Decimal `json:"totalSize,omitzero"`
 	CompanyName        string  `json:"companyName,omitempty"`
 	Status             string  `json:"status,omitempty"`
 	OrderCcpStatus     string  `json:"order_ccp_status,omitempty"`
@@ -73,13 +73,13 @@ type WebsocketPrivateOrderV2 struct {
 	FgColor            string  `json:"fgColor,omitempty"`
 	OrderRef           string  `json:"order_ref,omitempty"`
 	IsEventTrading     string  `json:"isEventTrading,omitempty"`
-	Price              string  `json:"price,omitempty"`
+	Price              Decimal `json:"price,omitzero"`
 	TimeInForce        string  `json:"timeInForce,omitempty"`
 	Side               string  `json:"side,omitempty"`
 }
 
 type WebsocketPrivateOrderResponseV2 struct {
-	Orders   []WebsocketPrivateOrderV2 `json:"args"`
+	Orders []WebsocketPrivateOrderV2 `json:"args"`
 }
 
 type WebsocketPrivatePnLResponse struct {
@@ -100,11 +100,11 @@ type WebsocketPrivateTradesData struct {
 	OrderDescription     string  `json:"order_description,omitempty"`
 	TradeTime            string  `json:"trade_time,omitempty"`
 	TradeTimeR           int64   `json:"trade_time_r,omitempty"`
-	Size                 float64 `json:"size,omitempty"`
+	Size                 Decimal `json:"size,omitzero"`
 	OrderRef             string  `json:"order_ref,omitempty"`
-	Price                string  `json:"price,omitempty"`
+	Price                Decimal `json:"price,omitzero"`
 	Exchange             string  `json:"exchange,omitempty"`
-	NetAmount            float64 `json:"net_amount,omitempty"`
+	NetAmount            Decimal `json:"net_amount,omitzero"`
 	Account              string  `json:"account,omitempty"`
 	AccountCode          string  `json:"accountCode,omitempty"`
 	CompanyName          string  `json:"company_name,omitempty"`
@@ -126,20 +126,25 @@ func (s *WebsocketPrivateService) SubscribeOrderV2(
 	handler func(WebsocketPrivateOrderResponseV2) error,
 ) (func() error, error) {
 
+	remove := s.router.handle(MessageTopicSubscribeOrder, "", "uor+{}", routeHandler(func(resp WebsocketPrivateOrderResponseV2) error {
+		if handler == nil {
+			return nil
+		}
+		return handler(resp)
+	}))
+
 	args := fmt.Sprintf("sor+%s", "{}")
 
 	if err := s.writeMessage(websocket.TextMessage, []byte(args)); err != nil {
+		remove()
 		return nil, err
 	}
 
-	s.orderResponseHandlerV2 = handler
-
 	return func() error {
-		args := fmt.Sprintf("uor+{}")
-		if err := s.writeMessage(websocket.TextMessage, []byte(args)); err != nil {
-			return err
+		if !remove() {
+			return nil
 		}
-		return nil
+		return s.writeMessage(websocket.TextMessage, []byte("uor+{}"))
 	}, nil
 }
 
@@ -156,20 +161,20 @@ func (s *WebsocketPrivateService) SubscribeOrder(
 		return nil, err
 	}
 
+	remove := s.router.handle(MessageTopicSubscribeOrder, "", "uor+{}", routeHandler(handler))
+
 	args := fmt.Sprintf("sor+%s", string(buf))
 
 	if err := s.writeMessage(websocket.TextMessage, []byte(args)); err != nil {
+		remove()
 		return nil, err
 	}
 
-	s.orderResponseHandler = handler
-
 	return func() error {
-		args := fmt.Sprintf("uor+{}")
-		if err := s.writeMessage(websocket.TextMessage, []byte(args)); err != nil {
-			return err
+		if !remove() {
+			return nil
 		}
-		return nil
+		return s.writeMessage(websocket.TextMessage, []byte("uor+{}"))
 	}, nil
 }
 func (s *WebsocketPrivateService) UnsubscribeOrder(
@@ -179,6 +184,7 @@ func (s *WebsocketPrivateService) UnsubscribeOrder(
 	if err := s.writeMessage(websocket.TextMessage, []byte(args)); err != nil {
 		return err
 	}
+	s.router.remove(MessageTopicSubscribeOrder, "")
 	return nil
 }
 
@@ -200,20 +206,20 @@ func (s *WebsocketPrivateService) SubscribeTradesData(
 		return nil, err
 	}
 
+	remove := s.router.handle(MessageTopicSubscribeTradesData, "", "utr", routeHandler(handler))
+
 	args := fmt.Sprintf("str+%s", string(buf))
 
 	if err := s.writeMessage(websocket.TextMessage, []byte(args)); err != nil {
+		remove()
 		return nil, err
 	}
 
-	s.tradesDataResponseHandler = handler
-
 	return func() error {
-		args := fmt.Sprintf("utr")
-		if err := s.writeMessage(websocket.TextMessage, []byte(args)); err != nil {
-			return err
+		if !remove() {
+			return nil
 		}
-		return nil
+		return s.writeMessage(websocket.TextMessage, []byte("utr"))
 	}, nil
 }
 func (s *WebsocketPrivateService) UnsubscribeTradesData(
@@ -223,6 +229,7 @@ func (s *WebsocketPrivateService) UnsubscribeTradesData(
 	if err := s.writeMessage(websocket.TextMessage, []byte(args)); err != nil {
 		return err
 	}
+	s.router.remove(MessageTopicSubscribeTradesData, "")
 	return nil
 }
 
@@ -230,20 +237,20 @@ func (s *WebsocketPrivateService) SubscribePnL(
 	handler func(WebsocketPrivatePnLResponse) error,
 ) (func() error, error) {
 
+	remove := s.router.handle(MessageTopicSubscribePnL, "", "upl+{}", routeHandler(handler))
+
 	args := "spl+{}"
 
 	if err := s.writeMessage(websocket.TextMessage, []byte(args)); err != nil {
+		remove()
 		return nil, err
 	}
 
-	s.pnlResponseHandler = handler
-
 	return func() error {
-		args := fmt.Sprintf("upl+{}")
-		if err := s.writeMessage(websocket.TextMessage, []byte(args)); err != nil {
-			return err
+		if !remove() {
+			return nil
 		}
-		return nil
+		return s.writeMessage(websocket.TextMessage, []byte("upl+{}"))
 	}, nil
 }
 func (s *WebsocketPrivateService) UnsubscribePnL() error {
@@ -251,5 +258,40 @@ func (s *WebsocketPrivateService) UnsubscribePnL() error {
 	if err := s.writeMessage(websocket.TextMessage, []byte(args)); err != nil {
 		return err
 	}
+	s.router.remove(MessageTopicSubscribePnL, "")
 	return nil
 }
+
+// StreamOrder :
+func (s *WebsocketPrivateService) StreamOrder(
+	param WebsocketPrivateOrderParam,
+	options DeliveryOptions,
+) (*Subscription[WebsocketPrivateOrderResponse], error) {
+	return subscribe(s.router, options, func(handler func(WebsocketPrivateOrderResponse) error) (func() error, error) {
+		return s.SubscribeOrder(param, handler)
+	})
+}
+
+// StreamOrderV2 :
+func (s *WebsocketPrivateService) StreamOrderV2(
+	options DeliveryOptions,
+) (*Subscription[WebsocketPrivateOrderResponseV2], error) {
+	return subscribe(s.router, options, s.SubscribeOrderV2)
+}
+
+// StreamPnL :
+func (s *WebsocketPrivateService) StreamPnL(
+	options DeliveryOptions,
+) (*Subscription[WebsocketPrivatePnLResponse], error) {
+	return subscribe(s.router, options, s.SubscribePnL)
+}
+
+// StreamTradesData :
+func (s *WebsocketPrivateService) StreamTradesData(
+	param WebsocketPrivateTradesDataParam,
+	options DeliveryOptions,
+) (*Subscription[WebsocketPrivateTradesDataResponse], error) {
+	return subscribe(s.router, options, func(handler func(WebsocketPrivateTradesDataResponse) error) (func() error, error) {
+		return s.SubscribeTradesData(param, handler)
+	})
+}
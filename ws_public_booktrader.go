@@ -7,46 +7,52 @@ import (
 )
 
 type WebsocketPublicBookTraderParam struct {
-	AccountId string
+	AccountId   string
 	ContractIds []int
-	Exchange      string
+	Exchange    string
 }
 type WebsocketPublicBookTraderDataItem struct {
-	Row int `json:"row"`
-	Focus int `json:"focus"` //Indicates if the value was marked as the last trade price for the contract.
+	Row   int    `json:"row"`
+	Focus int    `json:"focus"` //Indicates if the value was marked as the last trade price for the contract.
 	Price string `json:"price"`
-	Ask string `json:"ask,omitempty"`
-	Bid string `json:"bid,omitempty"`
+	Ask   string `json:"ask,omitempty"`
+	Bid   string `json:"bid,omitempty"`
 }
 type WebsocketPublicBookTraderResponse struct {
-	Topic string `json:"topic"`
-	Data []WebsocketPublicBookTraderDataItem `json:"data"`
+	Topic string                              `json:"topic"`
+	Data  []WebsocketPublicBookTraderDataItem `json:"data"`
 }
 
 func (s *WebsocketPublicService) SubscribeBookTrader(
 	param WebsocketPublicBookTraderParam,
 	handler func(WebsocketPublicBookTraderResponse) error,
 ) (func() error, error) {
-	
+
+	removes := make([]func() bool, 0, len(param.ContractIds))
 	for _, contractId := range param.ContractIds {
+		removes = append(removes, s.router.handle(MessageTopicSubscribeBookTrader, bookTraderRouteKey(param.AccountId, contractId), fmt.Sprintf("ubd+{%s}", param.AccountId), routeHandler(handler)))
+
 		args := fmt.Sprintf("sbd+%s+%d", param.AccountId, contractId)
 		if param.Exchange != "" {
 			args = args + "+" + param.Exchange
 		}
 
 		if err := s.writeMessage(websocket.TextMessage, []byte(args)); err != nil {
+			removeRoutes(removes)
 			return nil, err
 		}
 	}
 
-	s.bookTraderResponseHandler = handler
-
+	// ubd ends every book of the account, so only the last handler sends it
 	return func() error {
-		args := fmt.Sprintf("ubd+{%s}", param.AccountId)
-		if err := s.writeMessage(websocket.TextMessage, []byte(args)); err != nil {
-			return err
+		last := false
+		for _, remove := range removes {
+			last = remove() || last
+		}
+		if !last {
+			return nil
 		}
-		return nil
+		return s.writeMessage(websocket.TextMessage, []byte(fmt.Sprintf("ubd+{%s}", param.AccountId)))
 	}, nil
 }
 func (s *WebsocketPublicService) UnsubscribeBookTrader(
@@ -56,5 +62,22 @@ func (s *WebsocketPublicService) UnsubscribeBookTrader(
 	if err := s.writeMessage(websocket.TextMessage, []byte(args)); err != nil {
 		return err
 	}
+	for _, contractId := range param.ContractIds {
+		s.router.remove(MessageTopicSubscribeBookTrader, bookTraderRouteKey(param.AccountId, contractId))
+	}
 	return nil
 }
+
+func bookTraderRouteKey(accountId string, contractId int) string {
+	return fmt.Sprintf("%s+%d", accountId, contractId)
+}
+
+// StreamBookTrader :
+func (s *WebsocketPublicService) StreamBookTrader(
+	param WebsocketPublicBookTraderParam,
+	options DeliveryOptions,
+) (*Subscription[WebsocketPublicBookTraderResponse], error) {
+	return subscribe(s.router, options, func(handler func(WebsocketPublicBookTraderResponse) error) (func() error, error) {
+		return s.SubscribeBookTrader(param, handler)
+	})
+}
@@ -3,6 +3,8 @@ package ibkr
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
+
 	"github.com/gorilla/websocket"
 )
 
@@ -14,6 +16,7 @@ func (s *WebsocketPublicService) UnsubscribeHistoricalMarketData(
 		if err := s.writeMessage(websocket.TextMessage, []byte(args)); err != nil {
 			return err
 		}
+		s.router.remove(MessageTopicSubscribeHistoricalMarketData, strconv.Itoa(contractId))
 	}
 	return nil
 }
@@ -28,24 +31,29 @@ func (s *WebsocketPublicService) SubscribeHistoricalTicker(
 		return nil, err
 	}
 
+	removes := make([]func() bool, 0, len(param.ContractIds))
 	for _, contractId := range param.ContractIds {
+		removes = append(removes, s.router.handle(MessageTopicSubscribeHistoricalMarketData, strconv.Itoa(contractId), fmt.Sprintf("umh+%d+{}", contractId), routeHandler(handler)))
+
 		args := fmt.Sprintf("smh+%d+%s", contractId, string(buf))
 
 		if err := s.writeMessage(websocket.TextMessage, []byte(args)); err != nil {
+			removeRoutes(removes)
 			return nil, err
 		}
 	}
 
-	s.historicalMarketDataResponseHandler = handler
-
 	return func() error {
-		for _, contractId := range param.ContractIds {
-			args := fmt.Sprintf("umh+%d+{}", contractId)
-			if err := s.writeMessage(websocket.TextMessage, []byte(args)); err != nil {
-				return err
+		unused := WebsocketPublicHistoricalMarketDataParam{}
+		for n, contractId := range param.ContractIds {
+			if removes[n]() {
+				unused.ContractIds = append(unused.ContractIds, contractId)
 			}
 		}
-		return nil
+		if len(unused.ContractIds) == 0 {
+			return nil
+		}
+		return s.UnsubscribeHistoricalMarketData(unused)
 	}, nil
 }
 
@@ -64,3 +72,13 @@ type WebsocketPublicHistoricalMarketDataResponse struct {
 	ServerId string `json:"server_id,omitempty"`
 	Symbol   string `json:"symbol,omitempty"`
 }
+
+// StreamHistoricalTicker :
+func (s *WebsocketPublicService) StreamHistoricalTicker(
+	param WebsocketPublicHistoricalMarketDataParam,
+	options DeliveryOptions,
+) (*Subscription[WebsocketPublicHistoricalMarketDataResponse], error) {
+	return subscribe(s.router, options, func(handler func(WebsocketPublicHistoricalMarketDataResponse) error) (func() error, error) {
+		return s.SubscribeHistoricalTicker(param, handler)
+	})
+}
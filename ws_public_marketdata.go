@@ -3,6 +3,7 @@ package ibkr
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 
 	"github.com/gorilla/websocket"
 )
@@ -15,6 +16,10 @@ func (s *WebsocketPublicService) UnsubscribeMarketData(
 		if err := s.writeMessage(websocket.TextMessage, []byte(args)); err != nil {
 			return err
 		}
+		s.router.remove(MessageTopicSubscribeMarketData, strconv.Itoa(contractId))
+	}
+	if monitor := s.currentMonitor(); monitor != nil {
+		monitor.Unwatch(param.ContractIds...)
 	}
 	return nil
 }
@@ -32,24 +37,34 @@ func (s *WebsocketPublicService) SubscribeMarketData(
 		return nil, err
 	}
 
+	removes := make([]func() bool, 0, len(param.ContractIds))
 	for _, contractId := range param.ContractIds {
+		removes = append(removes, s.router.handle(MessageTopicSubscribeMarketData, strconv.Itoa(contractId), fmt.Sprintf("umd+%d+{}", contractId), routeHandler(handler)))
+
 		args := fmt.Sprintf("smd+%d+%s", contractId, string(buf))
 
 		if err := s.writeMessage(websocket.TextMessage, []byte(args)); err != nil {
+			removeRoutes(removes)
 			return nil, err
 		}
 	}
 
-	s.marketDataResponseHandler = handler
+	if monitor := s.currentMonitor(); monitor != nil {
+		monitor.Watch(param.ContractIds...)
+	}
 
+	// other handlers may still use a contract, only the last one unsubscribes it
 	return func() error {
-		for _, contractId := range param.ContractIds {
-			args := fmt.Sprintf("umd+%d+{}", contractId)
-			if err := s.writeMessage(websocket.TextMessage, []byte(args)); err != nil {
-				return err
+		unused := WebsocketPublicMarketDataParam{}
+		for n, contractId := range param.ContractIds {
+			if removes[n]() {
+				unused.ContractIds = append(unused.ContractIds, contractId)
 			}
 		}
-		return nil
+		if len(unused.ContractIds) == 0 {
+			return nil
+		}
+		return s.UnsubscribeMarketData(unused)
 	}, nil
 }
 
@@ -74,3 +89,13 @@ type WebsocketPublicMarketDataResponse struct {
 	VolumeOfDay            string `json:"87,omitempty"`
 	VolumeLongOfDay        string `json:"7762,omitempty"`
 }
+
+// StreamMarketData :
+func (s *WebsocketPublicService) StreamMarketData(
+	param WebsocketPublicMarketDataParam,
+	options DeliveryOptions,
+) (*Subscription[WebsocketPublicMarketDataResponse], error) {
+	return subscribe(s.router, options, func(handler func(WebsocketPublicMarketDataResponse) error) (func() error, error) {
+		return s.SubscribeMarketData(param, handler)
+	})
+}
@@ -0,0 +1,114 @@
+package ibkr
+
+import (
+	"strconv"
+	"sync"
+)
+
+// MarketDataConflator :
+// Keeps the latest quote per conid by merging incremental smd updates, so a
+// slow reader skips intermediate ticks instead of falling behind. Pass Handle
+// as the handler of SubscribeMarketData.
+type MarketDataConflator struct {
+	mutex   sync.Mutex
+	quotes  map[int]WebsocketPublicMarketDataResponse
+	changed map[int]struct{}
+	notify  chan struct{}
+}
+
+// NewMarketDataConflator :
+func NewMarketDataConflator() *MarketDataConflator {
+	return &MarketDataConflator{
+		quotes:  map[int]WebsocketPublicMarketDataResponse{},
+		changed: map[int]struct{}{},
+		notify:  make(chan struct{}, 1),
+	}
+}
+
+// Handle :
+func (c *MarketDataConflator) Handle(resp WebsocketPublicMarketDataResponse) error {
+	contractId := resp.ContractId
+	if contractId == 0 {
+		topic := ParseWebsocketTopic(resp.Topic)
+		if len(topic.Args) == 0 {
+			return nil
+		}
+		id, err := strconv.Atoi(topic.Args[0])
+		if err != nil {
+			return err
+		}
+		contractId = id
+	}
+
+	c.mutex.Lock()
+	quote := c.quotes[contractId]
+	quote.Merge(resp)
+	quote.ContractId = contractId
+	c.quotes[contractId] = quote
+	c.changed[contractId] = struct{}{}
+	c.mutex.Unlock()
+
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Quote :
+// Returns the current coalesced quote of contractId.
+func (c *MarketDataConflator) Quote(contractId int) (WebsocketPublicMarketDataResponse, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	quote, has := c.quotes[contractId]
+	return quote, has
+}
+
+// Changed :
+// Receives a value when quotes changed since the last ReadChanged. Several
+// changes are signalled once.
+func (c *MarketDataConflator) Changed() <-chan struct{} {
+	return c.notify
+}
+
+// ReadChanged :
+// Returns the current quotes of the conids changed since the last call.
+func (c *MarketDataConflator) ReadChanged() map[int]WebsocketPublicMarketDataResponse {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	result := make(map[int]WebsocketPublicMarketDataResponse, len(c.changed))
+	for contractId := range c.changed {
+		result[contractId] = c.quotes[contractId]
+	}
+	c.changed = map[int]struct{}{}
+	return result
+}
+
+// Merge :
+// Applies the fields present in update on top of r.
+func (r *WebsocketPublicMarketDataResponse) Merge(update WebsocketPublicMarketDataResponse) {
+	mergeString := func(dst *string, src string) {
+		if src != "" {
+			*dst = src
+		}
+	}
+	mergeString(&r.Topic, update.Topic)
+	mergeString(&r.ServerId, update.ServerId)
+	mergeString(&r.ContractIdExchange, update.ContractIdExchange)
+	if update.ContractId != 0 {
+		r.ContractId = update.ContractId
+	}
+	if update.UpdateTime != 0 {
+		r.UpdateTime = update.UpdateTime
+	}
+	mergeString(&r.MarketDataAvailability, update.MarketDataAvailability)
+	mergeString(&r.BidSize, update.BidSize)
+	mergeString(&r.BidPrice, update.BidPrice)
+	mergeString(&r.AskSize, update.AskSize)
+	mergeString(&r.AskPrice, update.AskPrice)
+	mergeString(&r.LastPrice, update.LastPrice)
+	mergeString(&r.LastSize, update.LastSize)
+	mergeString(&r.VolumeOfDay, update.VolumeOfDay)
+	mergeString(&r.VolumeLongOfDay, update.VolumeLongOfDay)
+}
@@ -0,0 +1,138 @@
+package ibkr_test
+
+import (
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+	"github.com/dictxwang/go-ibkr/ibkrtest"
+)
+
+func TestMarketDataConflatorMerge(t *testing.T) {
+	conflator := ibkr.NewMarketDataConflator()
+	for _, update := range []ibkr.WebsocketPublicMarketDataResponse{
+		{Topic: "smd+265598", BidPrice: "187.20", AskPrice: "187.30"},
+		{ContractId: 8314, LastPrice: "152.10"},
+		{Topic: "smd+265598", UpdateTime: 1700000000000, LastPrice: "187.25", LastSize: "100"},
+		{ContractId: 265598, AskPrice: "187.28"},
+	} {
+		if err := conflator.Handle(update); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	quote, has := conflator.Quote(265598)
+	want := ibkr.WebsocketPublicMarketDataResponse{
+		Topic:      "smd+265598",
+		ContractId: 265598,
+		UpdateTime: 1700000000000,
+		BidPrice:   "187.20",
+		AskPrice:   "187.28",
+		LastPrice:  "187.25",
+		LastSize:   "100",
+	}
+	if !has || !reflect.DeepEqual(quote, want) {
+		t.Errorf("quote %+v, want %+v", quote, want)
+	}
+	if quote, _ := conflator.Quote(8314); quote.LastPrice != "152.10" || quote.BidPrice != "" {
+		t.Errorf("8314 quote %+v took fields of another conid", quote)
+	}
+	if _, has := conflator.Quote(1); has {
+		t.Error("a quote of an unknown conid")
+	}
+
+	if err := conflator.Handle(ibkr.WebsocketPublicMarketDataResponse{Topic: "smd+abc"}); err == nil {
+		t.Error("a topic without a numeric conid was merged")
+	}
+}
+
+func TestMarketDataConflatorReadChanged(t *testing.T) {
+	conflator := ibkr.NewMarketDataConflator()
+	handle := func(contractId int, last string) {
+		t.Helper()
+		if err := conflator.Handle(ibkr.WebsocketPublicMarketDataResponse{ContractId: contractId, LastPrice: last}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lastPrices := func(quotes map[int]ibkr.WebsocketPublicMarketDataResponse) map[int]string {
+		prices := map[int]string{}
+		for contractId, quote := range quotes {
+			prices[contractId] = quote.LastPrice
+		}
+		return prices
+	}
+
+	handle(265598, "187.25")
+	handle(8314, "152.10")
+	handle(265598, "187.30")
+	select {
+	case <-conflator.Changed():
+	default:
+		t.Fatal("no change signalled")
+	}
+	select {
+	case <-conflator.Changed():
+		t.Error("three changes were signalled more than once")
+	default:
+	}
+	if got, want := lastPrices(conflator.ReadChanged()), map[int]string{265598: "187.30", 8314: "152.10"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("changed %v, want %v", got, want)
+	}
+
+	handle(8314, "152.20")
+	if got, want := lastPrices(conflator.ReadChanged()), map[int]string{8314: "152.20"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("changed %v, want only %v", got, want)
+	}
+	if got := conflator.ReadChanged(); len(got) != 0 {
+		t.Errorf("changed %v without an update", got)
+	}
+	// the quote of a conid that did not change is still there
+	if quote, _ := conflator.Quote(265598); quote.LastPrice != "187.30" {
+		t.Errorf("265598 quote %+v", quote)
+	}
+}
+
+func TestMarketDataConflatorConcurrentReads(t *testing.T) {
+	server := ibkrtest.NewServer(t)
+	service := startPublic(t, server)
+
+	conflator := ibkr.NewMarketDataConflator()
+	param := ibkr.WebsocketPublicMarketDataParam{ContractIds: []int{265598, 8314}, Fields: []string{"31"}}
+	if _, err := service.SubscribeMarketData(param, conflator.Handle); err != nil {
+		t.Fatal(err)
+	}
+	server.WaitForWebsocketMessage(t, "smd+8314", time.Second)
+
+	const updates = 200
+	var wg sync.WaitGroup
+	wg.Add(1)
+	latest := map[int]string{}
+	go func() {
+		defer wg.Done()
+		timeout := time.After(5 * time.Second)
+		for latest[265598] != strconv.Itoa(updates) || latest[8314] != strconv.Itoa(updates) {
+			select {
+			case <-conflator.Changed():
+			case <-timeout:
+				t.Errorf("last prices %v, want %d", latest, updates)
+				return
+			}
+			for contractId, quote := range conflator.ReadChanged() {
+				latest[contractId] = quote.LastPrice
+			}
+			_, _ = conflator.Quote(265598)
+		}
+	}()
+
+	for n := 1; n <= updates; n++ {
+		for _, contractId := range param.ContractIds {
+			if err := server.PushMarketData(ibkr.WebsocketPublicMarketDataResponse{ContractId: contractId, LastPrice: strconv.Itoa(n)}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	wg.Wait()
+}
@@ -0,0 +1,101 @@
+package ibkr_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+	"github.com/dictxwang/go-ibkr/ibkrtest"
+)
+
+// startPublic connects a public service to server and runs it until the test ends.
+func startPublic(t *testing.T, server *ibkrtest.Server) ibkr.WebsocketPublicServiceI {
+	t.Helper()
+	service, err := server.WebsocketClient().Service().Public("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = service.Start(ctx, nil)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return service
+}
+
+func receive[T any](t *testing.T, channel <-chan T) T {
+	t.Helper()
+	select {
+	case value := <-channel:
+		return value
+	case <-time.After(2 * time.Second):
+		t.Fatal("no message received")
+	}
+	var zero T
+	return zero
+}
+
+func countMessages(server *ibkrtest.Server, prefix string) int {
+	return len(slices.DeleteFunc(server.WebsocketMessages(), func(message string) bool {
+		return !strings.HasPrefix(message, prefix)
+	}))
+}
+
+func TestSubscribeMarketDataSharedContract(t *testing.T) {
+	server := ibkrtest.NewServer(t)
+	service := startPublic(t, server)
+
+	first, second := make(chan string, 8), make(chan string, 8)
+	param := ibkr.WebsocketPublicMarketDataParam{ContractIds: []int{265598}, Fields: []string{"31"}}
+	unsubscribeFirst, err := service.SubscribeMarketData(param, func(resp ibkr.WebsocketPublicMarketDataResponse) error {
+		first <- resp.LastPrice
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsubscribeSecond, err := service.SubscribeMarketData(param, func(resp ibkr.WebsocketPublicMarketDataResponse) error {
+		second <- resp.LastPrice
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.WaitForWebsocketMessage(t, "smd+265598", time.Second)
+
+	if err := server.PushMarketData(ibkr.WebsocketPublicMarketDataResponse{ContractId: 265598, LastPrice: "187.25"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := receive(t, first); got != "187.25" {
+		t.Errorf("first handler got %q", got)
+	}
+	if got := receive(t, second); got != "187.25" {
+		t.Errorf("second handler got %q", got)
+	}
+
+	if err := unsubscribeFirst(); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.PushMarketData(ibkr.WebsocketPublicMarketDataResponse{ContractId: 265598, LastPrice: "187.30"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := receive(t, second); got != "187.30" {
+		t.Errorf("second handler got %q after the first unsubscribed", got)
+	}
+	if n := countMessages(server, "umd+265598"); n != 0 {
+		t.Errorf("umd sent %d times while the contract is still subscribed", n)
+	}
+
+	if err := unsubscribeSecond(); err != nil {
+		t.Fatal(err)
+	}
+	server.WaitForWebsocketMessage(t, "umd+265598", time.Second)
+}
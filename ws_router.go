@@ -0,0 +1,428 @@
+package ibkr
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// WebsocketTopic :
+// A parsed message topic. "smd+265598" gives Name "smd" and Args ["265598"],
+// "sbd+DU123+265598" gives Name "sbd" and Args ["DU123", "265598"].
+type WebsocketTopic struct {
+	Name     string
+	Args     []string
+	ServerId string
+	Raw      string
+}
+
+// Key :
+// Returns the routing key of the topic, i.e. the args joined with "+".
+func (t WebsocketTopic) Key() string {
+	return strings.Join(t.Args, "+")
+}
+
+// ParseWebsocketTopic :
+func ParseWebsocketTopic(topic string) WebsocketTopic {
+	// public topics are joined with "+", some private topics come back joined with "-"
+	parts := strings.FieldsFunc(topic, func(r rune) bool {
+		return r == '+' || r == '-'
+	})
+	result := WebsocketTopic{Raw: topic}
+	if len(parts) > 0 {
+		result.Name = parts[0]
+		result.Args = parts[1:]
+	}
+	return result
+}
+
+// UnhandledMessageHandler :
+// Receives messages no subscription is registered for.
+type UnhandledMessageHandler func(topic WebsocketTopic, message []byte)
+
+type websocketRouteHandler func(topic WebsocketTopic, message []byte) error
+
+type websocketRouter struct {
+	mutex            sync.RWMutex
+	routes           map[string]map[string][]*websocketSubscriber
+	options          map[string]DeliveryOptions
+	counters         map[string]*deliveryCounters
+	unhandledHandler UnhandledMessageHandler
+	// errorHandler receives decode and handler errors of single messages
+	errorHandler func(topic WebsocketTopic, message []byte, err error)
+	// observer sees every message before it is routed
+	observer func(topic WebsocketTopic, message []byte)
+
+	closeHooks  map[int]func()
+	closeHookId int
+}
+
+func newWebsocketRouter() *websocketRouter {
+	return &websocketRouter{
+		routes:     map[string]map[string][]*websocketSubscriber{},
+		options:    map[string]DeliveryOptions{},
+		counters:   map[string]*deliveryCounters{},
+		closeHooks: map[int]func(){},
+	}
+}
+
+// handle :
+// Adds handler for messages of topic name and key. An empty key matches
+// every message of the topic that has no more specific route, and every
+// handler of a key receives its messages. The handler is delivered to
+// following the delivery options of the topic. unsubscribe is the message
+// that ends the subscription on the server.
+//
+// The returned func removes only this handler and reports whether it was the
+// last one needing unsubscribe, i.e. whether the caller should send it.
+func (r *websocketRouter) handle(name, key, unsubscribe string, handler websocketRouteHandler) func() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	subscriber := newWebsocketSubscriber(handler, r.options[name], r.topicCounters(name), r.reportError)
+	subscriber.unsubscribe = unsubscribe
+	r.addRoute(name, key, subscriber)
+
+	var once sync.Once
+	return func() bool {
+		last := false
+		once.Do(func() {
+			r.mutex.Lock()
+			defer r.mutex.Unlock()
+			if r.removeRoute(name, key, subscriber) {
+				last = !r.subscribed(unsubscribe)
+			}
+		})
+		return last
+	}
+}
+
+// removeRoutes :
+// Removes the routes added for a subscription whose subscribe message could
+// not be sent, so Shutdown does not unsubscribe them.
+func removeRoutes(removes []func() bool) {
+	for _, remove := range removes {
+		remove()
+	}
+}
+
+// handleDirect :
+// Registers handler to run on the read goroutine, leaving delivery policy and
+// accounting to the handler itself. It replaces the handlers of the key.
+func (r *websocketRouter) handleDirect(name, key string, handler websocketRouteHandler) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.removeKey(name, key)
+	r.addRoute(name, key, newDirectSubscriber(handler))
+}
+
+func (r *websocketRouter) addRoute(name, key string, subscriber *websocketSubscriber) {
+	keys, has := r.routes[name]
+	if !has {
+		keys = map[string][]*websocketSubscriber{}
+		r.routes[name] = keys
+	}
+	keys[key] = append(keys[key], subscriber)
+}
+
+// removeRoute :
+// Closes and removes subscriber, reporting whether it was registered.
+func (r *websocketRouter) removeRoute(name, key string, subscriber *websocketSubscriber) bool {
+	subscribers := r.routes[name][key]
+	for n, registered := range subscribers {
+		if registered != subscriber {
+			continue
+		}
+		subscriber.close()
+		r.routes[name][key] = append(subscribers[:n:n], subscribers[n+1:]...)
+		if len(r.routes[name][key]) == 0 {
+			r.removeKey(name, key)
+		}
+		return true
+	}
+	return false
+}
+
+func (r *websocketRouter) removeKey(name, key string) {
+	keys, has := r.routes[name]
+	if !has {
+		return
+	}
+	for _, subscriber := range keys[key] {
+		subscriber.close()
+	}
+	delete(keys, key)
+	if len(keys) == 0 {
+		delete(r.routes, name)
+	}
+}
+
+// subscribed :
+// Reports whether a route still needs the server subscription ended by unsubscribe.
+func (r *websocketRouter) subscribed(unsubscribe string) bool {
+	for _, keys := range r.routes {
+		for _, subscribers := range keys {
+			for _, subscriber := range subscribers {
+				if subscriber.unsubscribe == unsubscribe {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// remove :
+// Removes every handler of topic name and key.
+func (r *websocketRouter) remove(name, key string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.removeKey(name, key)
+}
+
+// close :
+// Stops every subscriber queue and runs the close hooks. Routes stay registered.
+func (r *websocketRouter) close() {
+	r.mutex.Lock()
+	hooks := r.closeHooks
+	r.closeHooks = map[int]func(){}
+	for _, keys := range r.routes {
+		for _, subscribers := range keys {
+			for _, subscriber := range subscribers {
+				subscriber.close()
+			}
+		}
+	}
+	r.mutex.Unlock()
+
+	for _, hook := range hooks {
+		hook()
+	}
+}
+
+// onClose :
+// Registers hook to run when the router closes. The returned func unregisters it.
+func (r *websocketRouter) onClose(hook func()) func() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.closeHookId++
+	id := r.closeHookId
+	r.closeHooks[id] = hook
+	return func() {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		delete(r.closeHooks, id)
+	}
+}
+
+// unsubscribeMessages :
+// Returns the distinct unsubscribe messages of the active subscriptions.
+func (r *websocketRouter) unsubscribeMessages() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	seen := map[string]bool{}
+	messages := make([]string, 0)
+	for _, keys := range r.routes {
+		for _, subscribers := range keys {
+			for _, subscriber := range subscribers {
+				if subscriber.unsubscribe == "" || seen[subscriber.unsubscribe] {
+					continue
+				}
+				seen[subscriber.unsubscribe] = true
+				messages = append(messages, subscriber.unsubscribe)
+			}
+		}
+	}
+	sort.Strings(messages)
+	return messages
+}
+
+func (r *websocketRouter) setUnhandledHandler(handler UnhandledMessageHandler) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.unhandledHandler = handler
+}
+
+func (r *websocketRouter) setObserver(observer func(WebsocketTopic, []byte)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.observer = observer
+}
+
+func (r *websocketRouter) setErrorHandler(handler func(WebsocketTopic, []byte, error)) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.errorHandler = handler
+}
+
+func (r *websocketRouter) reportError(topic WebsocketTopic, message []byte, err error) {
+	r.mutex.RLock()
+	handler := r.errorHandler
+	r.mutex.RUnlock()
+	if handler != nil {
+		handler(topic, message, err)
+	}
+}
+
+// setDeliveryOptions :
+// Options apply to routes of the topic registered afterwards.
+func (r *websocketRouter) setDeliveryOptions(name string, options DeliveryOptions) error {
+	if err := options.validate(); err != nil {
+		return err
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.options[name] = options
+	return nil
+}
+
+func (r *websocketRouter) deliveryOptions(name string) DeliveryOptions {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.options[name]
+}
+
+func (r *websocketRouter) deliveryStats(name string) DeliveryStats {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.topicCounters(name).stats()
+}
+
+func (r *websocketRouter) topicCounters(name string) *deliveryCounters {
+	counters, has := r.counters[name]
+	if !has {
+		counters = &deliveryCounters{}
+		r.counters[name] = counters
+	}
+	return counters
+}
+
+// lookup :
+// Returns a copy of the subscribers of topic, so they can be delivered to
+// without holding the lock.
+func (r *websocketRouter) lookup(topic WebsocketTopic) ([]*websocketSubscriber, UnhandledMessageHandler) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if keys, has := r.routes[topic.Name]; has {
+		if subscribers, has := keys[topic.Key()]; has {
+			return append([]*websocketSubscriber(nil), subscribers...), nil
+		}
+		if subscribers, has := keys[""]; has {
+			return append([]*websocketSubscriber(nil), subscribers...), nil
+		}
+	}
+	return nil, r.unhandledHandler
+}
+
+// parseTopic :
+func (r *websocketRouter) parseTopic(message []byte) (WebsocketTopic, error) {
+	if len(message) == 0 {
+		return WebsocketTopic{}, nil
+	}
+	envelope := map[string]json.RawMessage{}
+	if err := json.Unmarshal(message, &envelope); err != nil {
+		return WebsocketTopic{}, err
+	}
+	var name string
+	if raw, has := envelope["topic"]; has {
+		if err := json.Unmarshal(raw, &name); err != nil {
+			return WebsocketTopic{}, err
+		}
+	}
+	topic := ParseWebsocketTopic(name)
+	if raw, has := envelope["server_id"]; has {
+		var serverId string
+		if err := json.Unmarshal(raw, &serverId); err == nil {
+			topic.ServerId = serverId
+		} else {
+			topic.ServerId = string(raw)
+		}
+	}
+	return topic, nil
+}
+
+// dispatch :
+// Routes message to its subscribers. Errors are reported to the error handler
+// rather than returned, so one bad message or handler can not end the read loop.
+func (r *websocketRouter) dispatch(message []byte) {
+	topic, err := r.parseTopic(message)
+	if err != nil {
+		r.reportError(topic, message, err)
+		return
+	}
+
+	r.mutex.RLock()
+	observer := r.observer
+	r.mutex.RUnlock()
+	if observer != nil {
+		observer(topic, message)
+	}
+
+	subscribers, unhandled := r.lookup(topic)
+	if len(subscribers) > 0 {
+		for _, subscriber := range subscribers {
+			if err := subscriber.deliver(topic, message); err != nil {
+				r.reportError(topic, message, err)
+			}
+		}
+		return
+	}
+	if unhandled != nil {
+		unhandled(topic, message)
+	}
+}
+
+// routeHandler :
+// Adapts a typed response handler to a route handler.
+func routeHandler[T any](handler func(T) error) websocketRouteHandler {
+	return func(_ WebsocketTopic, message []byte) error {
+		var resp T
+		if err := json.Unmarshal(message, &resp); err != nil {
+			return err
+		}
+		if handler == nil {
+			return nil
+		}
+		return handler(resp)
+	}
+}
+
+// routeChan :
+// Adapts an unsolicited message channel to a route handler. The channel is the
+// queue, so the delivery policy is applied when sending to it.
+func routeChan[T any](channel chan *T, options DeliveryOptions, counters *deliveryCounters) websocketRouteHandler {
+	return func(_ WebsocketTopic, message []byte) error {
+		var resp T
+		if err := json.Unmarshal(message, &resp); err != nil {
+			return err
+		}
+		if options.blocking() {
+			channel <- &resp
+			counters.delivered.Add(1)
+			return nil
+		}
+		dropped := offer(channel, &resp, options.policy())
+		if dropped > 0 {
+			counters.dropped.Add(dropped)
+		}
+		if options.policy() != DeliveryPolicyDropNewest || dropped == 0 {
+			counters.delivered.Add(1)
+		}
+		return nil
+	}
+}
+
+// setChanRoute :
+func setChanRoute[T any](router *websocketRouter, name string, channel chan *T) {
+	if channel == nil {
+		router.remove(name, "")
+		return
+	}
+	router.mutex.Lock()
+	counters := router.topicCounters(name)
+	router.mutex.Unlock()
+	router.handleDirect(name, "", routeChan(channel, router.deliveryOptions(name), counters))
+}
@@ -0,0 +1,202 @@
+package ibkr
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseWebsocketTopic(t *testing.T) {
+	tests := []struct {
+		topic string
+		name  string
+		key   string
+	}{
+		{"smd+265598", "smd", "265598"},
+		{"sbd+DU123+265598", "sbd", "DU123+265598"},
+		{"ssd-DU123", "ssd", "DU123"},
+		{"sor", "sor", ""},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.topic, func(t *testing.T) {
+			topic := ParseWebsocketTopic(tt.topic)
+			if topic.Name != tt.name || topic.Key() != tt.key {
+				t.Errorf("got name %q key %q, want %q %q", topic.Name, topic.Key(), tt.name, tt.key)
+			}
+		})
+	}
+}
+
+// recorder collects the messages routed to named handlers, which run on
+// goroutines of their own.
+type recorder struct {
+	mutex    sync.Mutex
+	received []string
+	changed  chan struct{}
+}
+
+func newRecorder() *recorder {
+	return &recorder{changed: make(chan struct{}, 1)}
+}
+
+func (r *recorder) handler(name string) websocketRouteHandler {
+	return func(WebsocketTopic, []byte) error {
+		r.mutex.Lock()
+		r.received = append(r.received, name)
+		r.mutex.Unlock()
+		select {
+		case r.changed <- struct{}{}:
+		default:
+		}
+		return nil
+	}
+}
+
+// take :
+// Waits for count messages, then briefly for unexpected ones, and returns
+// them sorted.
+func (r *recorder) take(count int) []string {
+	deadline := time.After(time.Second)
+	for {
+		r.mutex.Lock()
+		received := len(r.received)
+		r.mutex.Unlock()
+		if received >= count {
+			break
+		}
+		select {
+		case <-r.changed:
+		case <-deadline:
+			return r.drain()
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	return r.drain()
+}
+
+func (r *recorder) drain() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	received := r.received
+	r.received = nil
+	sort.Strings(received)
+	return received
+}
+
+func TestWebsocketRouterDispatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    []string
+	}{
+		{"key", `{"topic":"smd+1"}`, []string{"smd 1 a", "smd 1 b"}},
+		{"other key", `{"topic":"smd+2"}`, []string{"smd 2"}},
+		{"fallback", `{"topic":"smd+3"}`, []string{"smd any"}},
+		{"topic only", `{"topic":"sor"}`, []string{"sor v1", "sor v2"}},
+		{"unhandled", `{"topic":"str"}`, []string{"unhandled str"}},
+		{"no topic", `{"message":"waiting for session"}`, []string{"unhandled "}},
+	}
+
+	rec := newRecorder()
+	router := newWebsocketRouter()
+	router.handle("smd", "1", "umd+1+{}", rec.handler("smd 1 a"))
+	router.handle("smd", "1", "umd+1+{}", rec.handler("smd 1 b"))
+	router.handle("smd", "2", "umd+2+{}", rec.handler("smd 2"))
+	router.handle("smd", "", "", rec.handler("smd any"))
+	router.handle("sor", "", "uor+{}", rec.handler("sor v1"))
+	router.handle("sor", "", "uor+{}", rec.handler("sor v2"))
+	router.setUnhandledHandler(func(topic WebsocketTopic, _ []byte) {
+		rec.handler("unhandled "+topic.Name)(topic, nil)
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router.dispatch([]byte(tt.message))
+			if got := rec.take(len(tt.want)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebsocketRouterRemove(t *testing.T) {
+	rec := newRecorder()
+	router := newWebsocketRouter()
+	removeV1 := router.handle("sor", "", "uor+{}", rec.handler("v1"))
+	removeV2 := router.handle("sor", "", "uor+{}", rec.handler("v2"))
+	removeBook1 := router.handle("sbd", "DU1+1", "ubd+{DU1}", rec.handler("book 1"))
+	removeBook2 := router.handle("sbd", "DU1+2", "ubd+{DU1}", rec.handler("book 2"))
+
+	if removeV1() {
+		t.Error("removing v1 while v2 is subscribed must not unsubscribe")
+	}
+	if removeV1() {
+		t.Error("removing twice must not unsubscribe")
+	}
+	router.dispatch([]byte(`{"topic":"sor"}`))
+	if got := rec.take(1); !reflect.DeepEqual(got, []string{"v2"}) {
+		t.Errorf("after removing v1 got %v, want [v2]", got)
+	}
+	if !removeV2() {
+		t.Error("removing the last sor handler must unsubscribe")
+	}
+
+	// ubd ends every book of the account, not just one conid
+	if removeBook1() {
+		t.Error("removing book 1 while book 2 is subscribed must not unsubscribe")
+	}
+	if got := router.unsubscribeMessages(); !reflect.DeepEqual(got, []string{"ubd+{DU1}"}) {
+		t.Errorf("unsubscribe messages %v, want [ubd+{DU1}]", got)
+	}
+	if !removeBook2() {
+		t.Error("removing the last book must unsubscribe")
+	}
+	if got := router.unsubscribeMessages(); len(got) != 0 {
+		t.Errorf("unsubscribe messages %v, want none", got)
+	}
+}
+
+func TestWebsocketRouterHandleDirect(t *testing.T) {
+	rec := newRecorder()
+	router := newWebsocketRouter()
+	router.handleDirect("system", "", rec.handler("first"))
+	router.handleDirect("system", "", rec.handler("second"))
+	router.dispatch([]byte(`{"topic":"system"}`))
+	if got := rec.take(1); !reflect.DeepEqual(got, []string{"second"}) {
+		t.Errorf("got %v, want [second]", got)
+	}
+	router.remove("system", "")
+	router.dispatch([]byte(`{"topic":"system"}`))
+	if got := rec.take(0); len(got) != 0 {
+		t.Errorf("got %v after remove, want nothing", got)
+	}
+}
+
+func TestWebsocketRouterErrors(t *testing.T) {
+	router := newWebsocketRouter()
+	errs := make(chan error, 4)
+	router.setErrorHandler(func(_ WebsocketTopic, _ []byte, err error) {
+		errs <- err
+	})
+	failure := errors.New("handler failed")
+	router.handle("smd", "1", "", func(WebsocketTopic, []byte) error { return failure })
+	router.handle("smd", "1", "", func(WebsocketTopic, []byte) error { return nil })
+
+	router.dispatch([]byte(`not json`))
+	if err := <-errs; err == nil || errors.Is(err, failure) {
+		t.Errorf("got %v, want a decode error", err)
+	}
+	router.dispatch([]byte(`{"topic":"smd+1"}`))
+	select {
+	case err := <-errs:
+		if !errors.Is(err, failure) {
+			t.Errorf("got %v, want %v", err, failure)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("handler error not reported")
+	}
+}
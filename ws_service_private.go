@@ -2,12 +2,7 @@ package ibkr
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"fmt"
-	"os"
-	"os/signal"
-	"strings"
 	"sync"
 	"time"
 
@@ -20,11 +15,17 @@ type WebsocketPrivateServiceI interface {
 	Run() error
 	Ping() error
 	Close() error
+	Shutdown() error
 	SetAccountUpdatesChan(channel chan *WebsocketUnsolicitedAccountUpdatesResponse)
 	SetAuthStatusChan(channel chan *WebsocketUnsolicitedAuthStatusResponse)
 	SetSystemChan(channel chan *WebsocketUnsolicitedSystemConnectionResponse)
 	SetBulletinsChan(channel chan *WebsocketUnsolicitedBulletinsResponse)
 	SetNotificationsChan(channel chan *WebsocketUnsolicitedNotificationsResponse)
+	SetUnhandledMessageHandler(handler UnhandledMessageHandler)
+	SetMessageErrorHandler(handler MessageErrorHandler)
+	SetDeliveryOptions(topic string, options DeliveryOptions) error
+	DeliveryStats(topic string) DeliveryStats
+	SetMonitor(monitor *WebsocketMonitor)
 
 	SubscribeAccountSummary(
 		WebsocketPrivateAccountSummaryParam,
@@ -62,75 +63,152 @@ type WebsocketPrivateServiceI interface {
 	UnsubscribeTradesData(
 		WebsocketPrivateTradesDataParam,
 	) error
+
+	StreamAccountSummary(
+		WebsocketPrivateAccountSummaryParam,
+		DeliveryOptions,
+	) (*Subscription[WebsocketPrivateAccountSummaryResponse], error)
+	StreamAccountLedger(
+		WebsocketPrivateAccountLedgerParam,
+		DeliveryOptions,
+	) (*Subscription[WebsocketPrivateAccountLedgerResponse], error)
+	StreamOrder(
+		WebsocketPrivateOrderParam,
+		DeliveryOptions,
+	) (*Subscription[WebsocketPrivateOrderResponse], error)
+	StreamOrderV2(
+		DeliveryOptions,
+	) (*Subscription[WebsocketPrivateOrderResponseV2], error)
+	StreamPnL(
+		DeliveryOptions,
+	) (*Subscription[WebsocketPrivatePnLResponse], error)
+	StreamTradesData(
+		WebsocketPrivateTradesDataParam,
+		DeliveryOptions,
+	) (*Subscription[WebsocketPrivateTradesDataResponse], error)
 }
 
+var _ WebsocketPrivateServiceI = (*WebsocketPrivateService)(nil)
+
 type WebsocketPrivateService struct {
 	client     *WebSocketClient
 	connection *websocket.Conn
 	writeMutex sync.Mutex
 
-	accountUpdatesChan chan *WebsocketUnsolicitedAccountUpdatesResponse
-	authStatusChan     chan *WebsocketUnsolicitedAuthStatusResponse
-	systemChan         chan *WebsocketUnsolicitedSystemConnectionResponse
-	bulletinsChan      chan *WebsocketUnsolicitedBulletinsResponse
-	notificationChan   chan *WebsocketUnsolicitedNotificationsResponse
-
-	accountSummaryResponseHandler func(WebsocketPrivateAccountSummaryResponse) error
-	accountLedgerResponseHandler  func(WebsocketPrivateAccountLedgerResponse) error
-	orderResponseHandler          func(WebsocketPrivateOrderResponse) error
-	orderResponseHandlerV2          func(WebsocketPrivateOrderResponseV2) error
-	pnlResponseHandler            func(WebsocketPrivatePnLResponse) error
-	tradesDataResponseHandler     func(WebsocketPrivateTradesDataResponse) error
-}
+	router  *websocketRouter
+	monitor *WebsocketMonitor
 
-// parseResponse :
-func (s *WebsocketPrivateService) parseResponse(respBody []byte, response interface{}) error {
-	if err := json.Unmarshal(respBody, &response); err != nil {
-		return err
-	}
-	return nil
+	stateMutex sync.Mutex
+	readerDone chan struct{}
 }
 
-// parseResponseTopic :
-func (s *WebsocketPrivateService) parseResponseTopic(respBody []byte) (string, error) {
-	if len(respBody) == 0 {
-		return "", nil
-	}
-	resp := map[string]interface{}{}
-	if err := json.Unmarshal(respBody, &resp); err != nil {
-		return "", err
-	}
-	if topic, has := resp["topic"]; has {
-		topicParts := strings.Split(topic.(string), "-")
-		return topicParts[0], nil
-	} else {
-		return "", nil
+func newWebsocketPrivateService(client *WebSocketClient, connection *websocket.Conn) *WebsocketPrivateService {
+	s := &WebsocketPrivateService{
+		client:     client,
+		connection: connection,
+		router:     newWebsocketRouter(),
 	}
+	s.SetMessageErrorHandler(nil)
+	s.router.setObserver(s.observe)
+	return s
 }
 
 func (s *WebsocketPrivateService) SetAccountUpdatesChan(channel chan *WebsocketUnsolicitedAccountUpdatesResponse) {
-	s.accountUpdatesChan = channel
+	setChanRoute(s.router, UnsolicitedMessageTopicAccountUpdates, channel)
 }
 func (s *WebsocketPrivateService) SetAuthStatusChan(channel chan *WebsocketUnsolicitedAuthStatusResponse) {
-	s.authStatusChan = channel
+	setChanRoute(s.router, UnsolicitedMessageTopicAuthStatus, channel)
 }
 func (s *WebsocketPrivateService) SetSystemChan(channel chan *WebsocketUnsolicitedSystemConnectionResponse) {
-	s.systemChan = channel
+	setChanRoute(s.router, UnsolicitedMessageTopicSystemConnection, channel)
 }
 func (s *WebsocketPrivateService) SetBulletinsChan(channel chan *WebsocketUnsolicitedBulletinsResponse) {
-	s.bulletinsChan = channel
+	setChanRoute(s.router, UnsolicitedMessageTopicBulletins, channel)
 }
 func (s *WebsocketPrivateService) SetNotificationsChan(channel chan *WebsocketUnsolicitedNotificationsResponse) {
-	s.notificationChan = channel
+	setChanRoute(s.router, UnsolicitedMessageTopicNotifications, channel)
+}
+
+// SetUnhandledMessageHandler :
+func (s *WebsocketPrivateService) SetUnhandledMessageHandler(handler UnhandledMessageHandler) {
+	s.router.setUnhandledHandler(handler)
+}
+
+// SetMessageErrorHandler :
+// Receives decode and handler errors of single messages, which no longer close
+// the connection. A nil handler logs them in debug mode.
+func (s *WebsocketPrivateService) SetMessageErrorHandler(handler MessageErrorHandler) {
+	if handler == nil {
+		s.router.setErrorHandler(func(topic WebsocketTopic, message []byte, err error) {
+			s.client.log().Debug("websocket message error", "service", "private", "topic", topic.Raw, "error", Redact(err.Error()))
+		})
+		return
+	}
+	s.router.setErrorHandler(func(topic WebsocketTopic, message []byte, err error) {
+		handler(&WebsocketMessageError{Topic: topic, Message: message, Err: err})
+	})
+}
+
+// SetDeliveryOptions :
+// Sets how messages of topic (e.g. MessageTopicSubscribeMarketData,
+// UnsolicitedMessageTopicBulletins) are delivered to subscriptions and
+// channels set up after the call. The default is DefaultDeliveryPolicy; an
+// unknown policy returns ErrDeliveryPolicy.
+func (s *WebsocketPrivateService) SetDeliveryOptions(topic string, options DeliveryOptions) error {
+	return s.router.setDeliveryOptions(topic, options)
+}
+
+// DeliveryStats :
+func (s *WebsocketPrivateService) DeliveryStats(topic string) DeliveryStats {
+	return s.router.deliveryStats(topic)
+}
+
+// SetMonitor :
+// Attaches a heartbeat and staleness monitor, which runs while Start does.
+func (s *WebsocketPrivateService) SetMonitor(monitor *WebsocketMonitor) {
+	s.stateMutex.Lock()
+	defer s.stateMutex.Unlock()
+	s.monitor = monitor
+}
+
+// currentMonitor :
+// Returns the monitor, which SetMonitor may change while the read loop runs.
+func (s *WebsocketPrivateService) currentMonitor() *WebsocketMonitor {
+	s.stateMutex.Lock()
+	defer s.stateMutex.Unlock()
+	return s.monitor
+}
+
+// observe :
+// Sees every message before it is routed.
+func (s *WebsocketPrivateService) observe(topic WebsocketTopic, message []byte) {
+	if monitor := s.currentMonitor(); monitor != nil {
+		monitor.observe(topic, message)
+	}
+	if s.client.hooks.OnMessage != nil {
+		s.client.hooks.OnMessage("private", topic)
+	}
 }
 
 // Start :
+// Runs the read loop and pings until ctx is done, then shuts down gracefully.
+// Signal handling is left to the caller.
 func (s *WebsocketPrivateService) Start(ctx context.Context, errHandler ErrHandler) error {
 	done := make(chan struct{})
+	s.stateMutex.Lock()
+	s.readerDone = done
+	s.stateMutex.Unlock()
 
 	go func() {
 		defer close(done)
 		defer s.connection.Close()
+		defer s.router.close()
+		var err error
+		if s.client.hooks.OnDisconnect != nil {
+			defer func() {
+				s.client.hooks.OnDisconnect("private", err)
+			}()
+		}
 
 		_ = s.connection.SetReadDeadline(time.Now().Add(60 * time.Second))
 		s.connection.SetPongHandler(func(string) error {
@@ -139,7 +217,7 @@ func (s *WebsocketPrivateService) Start(ctx context.Context, errHandler ErrHandl
 		})
 
 		for {
-			if err := s.Run(); err != nil {
+			if err = s.Run(); err != nil {
 				if errHandler == nil {
 					return
 				}
@@ -152,8 +230,11 @@ func (s *WebsocketPrivateService) Start(ctx context.Context, errHandler ErrHandl
 	ticker := time.NewTicker(20 * time.Second)
 	defer ticker.Stop()
 
-	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
-	defer stop()
+	if monitor := s.currentMonitor(); monitor != nil {
+		monitorCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go monitor.run(monitorCtx, s.Close)
+	}
 
 	for {
 		select {
@@ -164,155 +245,58 @@ func (s *WebsocketPrivateService) Start(ctx context.Context, errHandler ErrHandl
 				return err
 			}
 		case <-ctx.Done():
-			s.client.debugf("caught websocket public service interrupt signal")
+			s.client.log().Debug("websocket context done", "service", "private", "error", ctx.Err())
+			return s.Shutdown()
+		}
+	}
+}
 
-			if err := s.Close(); err != nil {
-				return err
-			}
-			select {
-			case <-done:
-			case <-time.After(time.Second):
-			}
-			return nil
+// Shutdown :
+// Sends the unsubscribe messages of all active subscriptions, closes the
+// connection and waits for the read loop of Start to exit, up to the shutdown
+// timeout of the client. On timeout the connection is closed without waiting
+// for the gateway, which ends the read loop.
+func (s *WebsocketPrivateService) Shutdown() error {
+	for _, message := range s.router.unsubscribeMessages() {
+		if err := s.writeMessage(websocket.TextMessage, []byte(message)); err != nil {
+			s.client.log().Warn("websocket unsubscribe failed", "service", "private", "message", message, "error", err)
+			break
 		}
 	}
+	if err := s.Close(); err != nil {
+		return err
+	}
+
+	s.stateMutex.Lock()
+	done := s.readerDone
+	s.stateMutex.Unlock()
+	if done == nil {
+		return nil
+	}
+	select {
+	case <-done:
+		return nil
+	case <-time.After(s.client.shutdownTimeout):
+		// the gateway did not answer the close frame, so stop reading now
+		// instead of at the read deadline
+		_ = s.connection.Close()
+		return ErrWebsocketShutdownTimeout
+	}
 }
 
 // Run :
+// Reads and dispatches one message. Only transport errors are returned;
+// message errors go to the message error handler.
 func (s *WebsocketPrivateService) Run() error {
 	_, message, err := s.connection.ReadMessage()
 	if err != nil {
 		return err
 	}
-
-	topic, err := s.parseResponseTopic(message)
-	if err != nil {
-		return err
-	}
-
-	switch topic {
-	case UnsolicitedMessageTopicAccountUpdates:
-		if s.accountUpdatesChan != nil {
-			var resp WebsocketUnsolicitedAccountUpdatesResponse
-			if err := s.parseResponse(message, &resp); err != nil {
-				return err
-			}
-			s.accountUpdatesChan <- &resp
-		}
-	case UnsolicitedMessageTopicAuthStatus:
-		if s.authStatusChan != nil {
-			var resp WebsocketUnsolicitedAuthStatusResponse
-			if err := s.parseResponse(message, &resp); err != nil {
-				return err
-			}
-			s.authStatusChan <- &resp
-		}
-	case UnsolicitedMessageTopicSystemConnection:
-		if s.systemChan != nil {
-			var resp WebsocketUnsolicitedSystemConnectionResponse
-			if err := s.parseResponse(message, &resp); err != nil {
-				return err
-			}
-			s.systemChan <- &resp
-		}
-	case UnsolicitedMessageTopicBulletins:
-		if s.bulletinsChan != nil {
-			var resp WebsocketUnsolicitedBulletinsResponse
-			if err := s.parseResponse(message, &resp); err != nil {
-				return err
-			}
-			s.bulletinsChan <- &resp
-		}
-	case UnsolicitedMessageTopicNotifications:
-		if s.notificationChan != nil {
-			var resp WebsocketUnsolicitedNotificationsResponse
-			if err := s.parseResponse(message, &resp); err != nil {
-				return err
-			}
-			s.notificationChan <- &resp
-		}
-	case MessageTopicSubscribeAccountSummary:
-		var resp WebsocketPrivateAccountSummaryResponse
-		if err := s.parseResponse(message, &resp); err != nil {
-			return err
-		}
-		if s.accountSummaryResponseHandler != nil {
-			if err := s.accountSummaryResponseHandler(resp); err != nil {
-				return err
-			}
-		}
-	case MessageTopicSubscribeAccountLedger:
-		var resp WebsocketPrivateAccountLedgerResponse
-		if err := s.parseResponse(message, &resp); err != nil {
-			return err
-		}
-		if s.accountLedgerResponseHandler != nil {
-			if err := s.accountLedgerResponseHandler(resp); err != nil {
-				return err
-			}
-		}
-	case MessageTopicSubscribeOrder:
-		if s.orderResponseHandler != nil {
-			var resp WebsocketPrivateOrderResponse
-			if err := s.parseResponse(message, &resp); err != nil {
-				return err
-			}
-			if err := s.orderResponseHandler(resp); err != nil {
-				return err
-			}
-		} else if s.orderResponseHandlerV2 != nil {
-			var preResp interface{}
-			if err := s.parseResponse(message, &preResp); err != nil {
-				return err
-			}
-			preMap, preMapOk := preResp.(map[string]interface{})
-			if !preMapOk {
-				return fmt.Errorf("unexpected response type: %T", preResp)
-			}
-
-			topicValue, hasTopic := preMap["topic"]
-			if !hasTopic {
-				return nil
-			}
-			topic, ok := topicValue.(string)
-			if !ok {
-				return fmt.Errorf("topic is not string: %T", topic)
-			}
-			if topic == "sor" {
-				fmt.Printf(">> get order message: %s\n", string(message))
-				var resp WebsocketPrivateOrderResponseV2
-				if err := s.parseResponse(message, &resp); err != nil {
-					return err
-				}
-				if err := s.orderResponseHandlerV2(resp); err != nil {
-					return err
-				}
-			} else {
-				// ignore message with topic of system: {"topic":"system","hb":1778570361377}
-				return nil
-			}
-		}
-	case MessageTopicSubscribePnL:
-		var resp WebsocketPrivatePnLResponse
-		if err := s.parseResponse(message, &resp); err != nil {
-			return err
-		}
-		if s.pnlResponseHandler != nil {
-			if err := s.pnlResponseHandler(resp); err != nil {
-				return err
-			}
-		}
-	case MessageTopicSubscribeTradesData:
-		var resp WebsocketPrivateTradesDataResponse
-		if err := s.parseResponse(message, &resp); err != nil {
-			return err
-		}
-		if s.tradesDataResponseHandler != nil {
-			if err := s.tradesDataResponseHandler(resp); err != nil {
-				return err
-			}
-		}
+	s.client.logMessage("private", message)
+	if s.client.hooks.OnRead != nil {
+		s.client.hooks.OnRead("private", message)
 	}
+	s.router.dispatch(message)
 	return nil
 }
 
@@ -337,11 +321,20 @@ func (s *WebsocketPrivateService) Close() error {
 }
 
 func (s *WebsocketPrivateService) writeMessage(messageType int, body []byte) error {
+	var done func(error)
+	if messageType == websocket.TextMessage {
+		done = s.client.sendHook("private", body)
+	}
+
 	s.writeMutex.Lock()
-	defer s.writeMutex.Unlock()
+	err := s.connection.WriteMessage(messageType, body)
+	s.writeMutex.Unlock()
 
-	if err := s.connection.WriteMessage(messageType, body); err != nil {
-		return err
+	if done != nil {
+		done(err)
 	}
-	return nil
+	if err == nil && messageType == websocket.TextMessage && s.client.hooks.OnWrite != nil {
+		s.client.hooks.OnWrite("private", body)
+	}
+	return err
 }
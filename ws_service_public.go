@@ -2,13 +2,8 @@ package ibkr
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"fmt"
 	"github.com/gorilla/websocket"
-	"os"
-	"os/signal"
-	"strings"
 	"sync"
 	"time"
 )
@@ -21,11 +16,17 @@ type WebsocketPublicServiceI interface {
 	Run() error
 	Ping() error
 	Close() error
+	Shutdown() error
 	SetAccountUpdatesChan(channel chan *WebsocketUnsolicitedAccountUpdatesResponse)
 	SetAuthStatusChan(channel chan *WebsocketUnsolicitedAuthStatusResponse)
 	SetSystemChan(channel chan *WebsocketUnsolicitedSystemConnectionResponse)
 	SetBulletinsChan(channel chan *WebsocketUnsolicitedBulletinsResponse)
 	SetNotificationsChan(channel chan *WebsocketUnsolicitedNotificationsResponse)
+	SetUnhandledMessageHandler(handler UnhandledMessageHandler)
+	SetMessageErrorHandler(handler MessageErrorHandler)
+	SetDeliveryOptions(topic string, options DeliveryOptions) error
+	DeliveryStats(topic string) DeliveryStats
+	SetMonitor(monitor *WebsocketMonitor)
 
 	SubscribeMarketData(
 		WebsocketPublicMarketDataParam,
@@ -34,12 +35,12 @@ type WebsocketPublicServiceI interface {
 	UnsubscribeMarketData(
 		WebsocketPublicMarketDataParam,
 	) error
-	SubscribeHistoricalMarketData(
-		WebsocketPublicMarketDataParam,
-		func(WebsocketPublicMarketDataResponse) error,
+	SubscribeHistoricalTicker(
+		WebsocketPublicHistoricalMarketDataParam,
+		func(WebsocketPublicHistoricalMarketDataResponse) error,
 	) (func() error, error)
 	UnsubscribeHistoricalMarketData(
-		WebsocketPublicMarketDataParam,
+		WebsocketPublicHistoricalMarketDataParam,
 	) error
 	SubscribeBookTrader(
 		WebsocketPublicBookTraderParam,
@@ -48,72 +49,142 @@ type WebsocketPublicServiceI interface {
 	UnsubscribeBookTrader(
 		WebsocketPublicBookTraderParam,
 	) error
+
+	StreamMarketData(
+		WebsocketPublicMarketDataParam,
+		DeliveryOptions,
+	) (*Subscription[WebsocketPublicMarketDataResponse], error)
+	StreamHistoricalTicker(
+		WebsocketPublicHistoricalMarketDataParam,
+		DeliveryOptions,
+	) (*Subscription[WebsocketPublicHistoricalMarketDataResponse], error)
+	StreamBookTrader(
+		WebsocketPublicBookTraderParam,
+		DeliveryOptions,
+	) (*Subscription[WebsocketPublicBookTraderResponse], error)
 }
 
+var _ WebsocketPublicServiceI = (*WebsocketPublicService)(nil)
+
 type WebsocketPublicService struct {
 	client     *WebSocketClient
 	connection *websocket.Conn
 	writeMutex sync.Mutex
 
-	accountUpdatesChan chan *WebsocketUnsolicitedAccountUpdatesResponse
-	authStatusChan     chan *WebsocketUnsolicitedAuthStatusResponse
-	systemChan         chan *WebsocketUnsolicitedSystemConnectionResponse
-	bulletinsChan      chan *WebsocketUnsolicitedBulletinsResponse
-	notificationChan   chan *WebsocketUnsolicitedNotificationsResponse
+	router  *websocketRouter
+	monitor *WebsocketMonitor
 
-	marketDataResponseHandler           func(WebsocketPublicMarketDataResponse) error
-	historicalMarketDataResponseHandler func(WebsocketPublicHistoricalMarketDataResponse) error
-	bookTraderResponseHandler           func(WebsocketPublicBookTraderResponse) error
+	stateMutex sync.Mutex
+	readerDone chan struct{}
 }
 
-// parseResponse :
-func (s *WebsocketPublicService) parseResponse(respBody []byte, response interface{}) error {
-	if err := json.Unmarshal(respBody, &response); err != nil {
-		return err
-	}
-	return nil
-}
-
-// parseResponseTopic :
-func (s *WebsocketPublicService) parseResponseTopic(respBody []byte) (string, error) {
-	if len(respBody) == 0 {
-		return "", nil
-	}
-	resp := map[string]interface{}{}
-	if err := json.Unmarshal(respBody, &resp); err != nil {
-		return "", err
-	}
-	if topic, has := resp["topic"]; has {
-		topicParts := strings.Split(topic.(string), "+")
-		return topicParts[0], nil
-	} else {
-		return "", nil
+func newWebsocketPublicService(client *WebSocketClient, connection *websocket.Conn) *WebsocketPublicService {
+	s := &WebsocketPublicService{
+		client:     client,
+		connection: connection,
+		router:     newWebsocketRouter(),
 	}
+	s.SetMessageErrorHandler(nil)
+	s.router.setObserver(s.observe)
+	return s
 }
 
 func (s *WebsocketPublicService) SetAccountUpdatesChan(channel chan *WebsocketUnsolicitedAccountUpdatesResponse) {
-	s.accountUpdatesChan = channel
+	setChanRoute(s.router, UnsolicitedMessageTopicAccountUpdates, channel)
 }
 func (s *WebsocketPublicService) SetAuthStatusChan(channel chan *WebsocketUnsolicitedAuthStatusResponse) {
-	s.authStatusChan = channel
+	setChanRoute(s.router, UnsolicitedMessageTopicAuthStatus, channel)
 }
 func (s *WebsocketPublicService) SetSystemChan(channel chan *WebsocketUnsolicitedSystemConnectionResponse) {
-	s.systemChan = channel
+	setChanRoute(s.router, UnsolicitedMessageTopicSystemConnection, channel)
 }
 func (s *WebsocketPublicService) SetBulletinsChan(channel chan *WebsocketUnsolicitedBulletinsResponse) {
-	s.bulletinsChan = channel
+	setChanRoute(s.router, UnsolicitedMessageTopicBulletins, channel)
 }
 func (s *WebsocketPublicService) SetNotificationsChan(channel chan *WebsocketUnsolicitedNotificationsResponse) {
-	s.notificationChan = channel
+	setChanRoute(s.router, UnsolicitedMessageTopicNotifications, channel)
+}
+
+// SetUnhandledMessageHandler :
+func (s *WebsocketPublicService) SetUnhandledMessageHandler(handler UnhandledMessageHandler) {
+	s.router.setUnhandledHandler(handler)
+}
+
+// SetMessageErrorHandler :
+// Receives decode and handler errors of single messages, which no longer close
+// the connection. A nil handler logs them in debug mode.
+func (s *WebsocketPublicService) SetMessageErrorHandler(handler MessageErrorHandler) {
+	if handler == nil {
+		s.router.setErrorHandler(func(topic WebsocketTopic, message []byte, err error) {
+			s.client.log().Debug("websocket message error", "service", "public", "topic", topic.Raw, "error", Redact(err.Error()))
+		})
+		return
+	}
+	s.router.setErrorHandler(func(topic WebsocketTopic, message []byte, err error) {
+		handler(&WebsocketMessageError{Topic: topic, Message: message, Err: err})
+	})
+}
+
+// SetDeliveryOptions :
+// Sets how messages of topic (e.g. MessageTopicSubscribeMarketData,
+// UnsolicitedMessageTopicBulletins) are delivered to subscriptions and
+// channels set up after the call. The default is DefaultDeliveryPolicy; an
+// unknown policy returns ErrDeliveryPolicy.
+func (s *WebsocketPublicService) SetDeliveryOptions(topic string, options DeliveryOptions) error {
+	return s.router.setDeliveryOptions(topic, options)
+}
+
+// DeliveryStats :
+func (s *WebsocketPublicService) DeliveryStats(topic string) DeliveryStats {
+	return s.router.deliveryStats(topic)
+}
+
+// SetMonitor :
+// Attaches a heartbeat and staleness monitor, which runs while Start does.
+func (s *WebsocketPublicService) SetMonitor(monitor *WebsocketMonitor) {
+	s.stateMutex.Lock()
+	defer s.stateMutex.Unlock()
+	s.monitor = monitor
+}
+
+// currentMonitor :
+// Returns the monitor, which SetMonitor may change while the read loop runs.
+func (s *WebsocketPublicService) currentMonitor() *WebsocketMonitor {
+	s.stateMutex.Lock()
+	defer s.stateMutex.Unlock()
+	return s.monitor
+}
+
+// observe :
+// Sees every message before it is routed.
+func (s *WebsocketPublicService) observe(topic WebsocketTopic, message []byte) {
+	if monitor := s.currentMonitor(); monitor != nil {
+		monitor.observe(topic, message)
+	}
+	if s.client.hooks.OnMessage != nil {
+		s.client.hooks.OnMessage("public", topic)
+	}
 }
 
 // Start :
+// Runs the read loop and pings until ctx is done, then shuts down gracefully.
+// Signal handling is left to the caller.
 func (s *WebsocketPublicService) Start(ctx context.Context, errHandler ErrHandler) error {
 	done := make(chan struct{})
+	s.stateMutex.Lock()
+	s.readerDone = done
+	s.stateMutex.Unlock()
 
 	go func() {
 		defer close(done)
 		defer s.connection.Close()
+		defer s.router.close()
+		var err error
+		if s.client.hooks.OnDisconnect != nil {
+			defer func() {
+				s.client.hooks.OnDisconnect("public", err)
+			}()
+		}
 
 		_ = s.connection.SetReadDeadline(time.Now().Add(60 * time.Second))
 		s.connection.SetPongHandler(func(string) error {
@@ -122,7 +193,7 @@ func (s *WebsocketPublicService) Start(ctx context.Context, errHandler ErrHandle
 		})
 
 		for {
-			if err := s.Run(); err != nil {
+			if err = s.Run(); err != nil {
 				if errHandler == nil {
 					return
 				}
@@ -135,8 +206,11 @@ func (s *WebsocketPublicService) Start(ctx context.Context, errHandler ErrHandle
 	ticker := time.NewTicker(20 * time.Second)
 	defer ticker.Stop()
 
-	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
-	defer stop()
+	if monitor := s.currentMonitor(); monitor != nil {
+		monitorCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go monitor.run(monitorCtx, s.Close)
+	}
 
 	for {
 		select {
@@ -147,105 +221,58 @@ func (s *WebsocketPublicService) Start(ctx context.Context, errHandler ErrHandle
 				return err
 			}
 		case <-ctx.Done():
-			s.client.debugf("caught websocket public service interrupt signal")
+			s.client.log().Debug("websocket context done", "service", "public", "error", ctx.Err())
+			return s.Shutdown()
+		}
+	}
+}
 
-			if err := s.Close(); err != nil {
-				return err
-			}
-			select {
-			case <-done:
-			case <-time.After(time.Second):
-			}
-			return nil
+// Shutdown :
+// Sends the unsubscribe messages of all active subscriptions, closes the
+// connection and waits for the read loop of Start to exit, up to the shutdown
+// timeout of the client. On timeout the connection is closed without waiting
+// for the gateway, which ends the read loop.
+func (s *WebsocketPublicService) Shutdown() error {
+	for _, message := range s.router.unsubscribeMessages() {
+		if err := s.writeMessage(websocket.TextMessage, []byte(message)); err != nil {
+			s.client.log().Warn("websocket unsubscribe failed", "service", "public", "message", message, "error", err)
+			break
 		}
 	}
+	if err := s.Close(); err != nil {
+		return err
+	}
+
+	s.stateMutex.Lock()
+	done := s.readerDone
+	s.stateMutex.Unlock()
+	if done == nil {
+		return nil
+	}
+	select {
+	case <-done:
+		return nil
+	case <-time.After(s.client.shutdownTimeout):
+		// the gateway did not answer the close frame, so stop reading now
+		// instead of at the read deadline
+		_ = s.connection.Close()
+		return ErrWebsocketShutdownTimeout
+	}
 }
 
 // Run :
+// Reads and dispatches one message. Only transport errors are returned;
+// message errors go to the message error handler.
 func (s *WebsocketPublicService) Run() error {
 	_, message, err := s.connection.ReadMessage()
-	fmt.Printf("after read message: %s\n", message)
 	if err != nil {
 		return err
 	}
-
-	topic, err := s.parseResponseTopic(message)
-	if err != nil {
-		return err
-	}
-
-	switch topic {
-	case UnsolicitedMessageTopicAccountUpdates:
-		if s.accountUpdatesChan != nil {
-			var resp WebsocketUnsolicitedAccountUpdatesResponse
-			if err := s.parseResponse(message, &resp); err != nil {
-				return err
-			}
-			s.accountUpdatesChan <- &resp
-		}
-	case UnsolicitedMessageTopicAuthStatus:
-		if s.authStatusChan != nil {
-			var resp WebsocketUnsolicitedAuthStatusResponse
-			if err := s.parseResponse(message, &resp); err != nil {
-				return err
-			}
-			s.authStatusChan <- &resp
-		}
-	case UnsolicitedMessageTopicSystemConnection:
-		if s.systemChan != nil {
-			var resp WebsocketUnsolicitedSystemConnectionResponse
-			if err := s.parseResponse(message, &resp); err != nil {
-				return err
-			}
-			s.systemChan <- &resp
-		}
-	case UnsolicitedMessageTopicBulletins:
-		if s.bulletinsChan != nil {
-			var resp WebsocketUnsolicitedBulletinsResponse
-			if err := s.parseResponse(message, &resp); err != nil {
-				return err
-			}
-			s.bulletinsChan <- &resp
-		}
-	case UnsolicitedMessageTopicNotifications:
-		if s.notificationChan != nil {
-			var resp WebsocketUnsolicitedNotificationsResponse
-			if err := s.parseResponse(message, &resp); err != nil {
-				return err
-			}
-			s.notificationChan <- &resp
-		}
-	case MessageTopicSubscribeMarketData:
-		var resp WebsocketPublicMarketDataResponse
-		if err := s.parseResponse(message, &resp); err != nil {
-			return err
-		}
-		if s.marketDataResponseHandler != nil {
-			if err := s.marketDataResponseHandler(resp); err != nil {
-				return err
-			}
-		}
-	case MessageTopicSubscribeHistoricalMarketData:
-		var resp WebsocketPublicHistoricalMarketDataResponse
-		if err := s.parseResponse(message, &resp); err != nil {
-			return err
-		}
-		if s.historicalMarketDataResponseHandler != nil {
-			if err := s.historicalMarketDataResponseHandler(resp); err != nil {
-				return err
-			}
-		}
-	case MessageTopicSubscribeBookTrader:
-		var resp WebsocketPublicBookTraderResponse
-		if err := s.parseResponse(message, &resp); err != nil {
-			return err
-		}
-		if s.bookTraderResponseHandler != nil {
-			if err := s.bookTraderResponseHandler(resp); err != nil {
-				return err
-			}
-		}
+	s.client.logMessage("public", message)
+	if s.client.hooks.OnRead != nil {
+		s.client.hooks.OnRead("public", message)
 	}
+	s.router.dispatch(message)
 	return nil
 }
 
@@ -270,11 +297,20 @@ func (s *WebsocketPublicService) Close() error {
 }
 
 func (s *WebsocketPublicService) writeMessage(messageType int, body []byte) error {
+	var done func(error)
+	if messageType == websocket.TextMessage {
+		done = s.client.sendHook("public", body)
+	}
+
 	s.writeMutex.Lock()
-	defer s.writeMutex.Unlock()
+	err := s.connection.WriteMessage(messageType, body)
+	s.writeMutex.Unlock()
 
-	if err := s.connection.WriteMessage(messageType, body); err != nil {
-		return err
+	if done != nil {
+		done(err)
 	}
-	return nil
+	if err == nil && messageType == websocket.TextMessage && s.client.hooks.OnWrite != nil {
+		s.client.hooks.OnWrite("public", body)
+	}
+	return err
 }
@@ -0,0 +1,81 @@
+package ibkr_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ibkr "github.com/dictxwang/go-ibkr"
+	"github.com/gorilla/websocket"
+)
+
+// silentGateway accepts websocket connections and never reads from them, so
+// a close frame is not answered.
+func silentGateway(t *testing.T) string {
+	t.Helper()
+	release := make(chan struct{})
+	upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		<-release
+	}))
+	t.Cleanup(func() {
+		close(release)
+		server.Close()
+	})
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func TestShutdownTimeoutClosesConnection(t *testing.T) {
+	for _, kind := range []string{"public", "private"} {
+		t.Run(kind, func(t *testing.T) {
+			disconnected := make(chan struct{})
+			client := ibkr.NewWebsocketClient(silentGateway(t), "", true).
+				WithShutdownTimeout(50 * time.Millisecond).
+				WithHooks(ibkr.WebsocketHooks{OnDisconnect: func(string, error) { close(disconnected) }})
+
+			var start func(context.Context, ibkr.ErrHandler) error
+			if kind == "public" {
+				service, err := client.Service().Public("token")
+				if err != nil {
+					t.Fatal(err)
+				}
+				start = service.Start
+			} else {
+				service, err := client.Service().Private("token")
+				if err != nil {
+					t.Fatal(err)
+				}
+				start = service.Start
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			result := make(chan error, 1)
+			go func() {
+				result <- start(ctx, nil)
+			}()
+			cancel()
+			select {
+			case err := <-result:
+				if !errors.Is(err, ibkr.ErrWebsocketShutdownTimeout) {
+					t.Errorf("Start got %v, want ErrWebsocketShutdownTimeout", err)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("Start did not return")
+			}
+			select {
+			case <-disconnected:
+			case <-time.After(time.Second):
+				t.Error("the read loop kept running after the shutdown timed out")
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package ibkr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// dialEcho returns a connection to a server reading until the client leaves.
+func dialEcho(t *testing.T) *websocket.Conn {
+	t.Helper()
+	upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// failingClient returns a client whose connection is closed right before the
+// subscribe message of key failKey is written, so that write fails.
+func failingClient(failKey string, closer *func() error) *WebSocketClient {
+	return NewWebsocketClient("ws://localhost", "", true).WithHooks(WebsocketHooks{
+		OnSend: func(_ string, topic WebsocketTopic, subscribe bool) func(error) {
+			if subscribe && (failKey == "" || topic.Key() == failKey) {
+				_ = (*closer)()
+			}
+			return nil
+		},
+	})
+}
+
+func TestSubscribeWriteErrorRemovesRoutes(t *testing.T) {
+	publicTests := []struct {
+		name      string
+		failKey   string
+		subscribe func(*WebsocketPublicService) error
+	}{
+		{"market data", "8314", func(s *WebsocketPublicService) error {
+			_, err := s.SubscribeMarketData(WebsocketPublicMarketDataParam{ContractIds: []int{265598, 8314}}, func(WebsocketPublicMarketDataResponse) error { return nil })
+			return err
+		}},
+		{"historical", "8314", func(s *WebsocketPublicService) error {
+			_, err := s.SubscribeHistoricalTicker(WebsocketPublicHistoricalMarketDataParam{ContractIds: []int{265598, 8314}}, func(WebsocketPublicHistoricalMarketDataResponse) error { return nil })
+			return err
+		}},
+		{"book trader", "DU123+8314", func(s *WebsocketPublicService) error {
+			_, err := s.SubscribeBookTrader(WebsocketPublicBookTraderParam{AccountId: "DU123", ContractIds: []int{265598, 8314}}, func(WebsocketPublicBookTraderResponse) error { return nil })
+			return err
+		}},
+	}
+	for _, tt := range publicTests {
+		t.Run(tt.name, func(t *testing.T) {
+			var closer func() error
+			service := newWebsocketPublicService(failingClient(tt.failKey, &closer), dialEcho(t))
+			closer = service.Close
+			if err := tt.subscribe(service); err == nil {
+				t.Fatal("subscribe succeeded on a closed connection")
+			}
+			if messages := service.router.unsubscribeMessages(); len(messages) != 0 {
+				t.Errorf("routes left behind, Shutdown would send %v", messages)
+			}
+		})
+	}
+
+	privateTests := []struct {
+		name      string
+		subscribe func(*WebsocketPrivateService) error
+	}{
+		{"account summary", func(s *WebsocketPrivateService) error {
+			_, err := s.SubscribeAccountSummary(WebsocketPrivateAccountSummaryParam{AccountId: "DU123"}, func(WebsocketPrivateAccountSummaryResponse) error { return nil })
+			return err
+		}},
+		{"account ledger", func(s *WebsocketPrivateService) error {
+			_, err := s.SubscribeAccountLedger(WebsocketPrivateAccountLedgerParam{AccountId: "DU123"}, func(WebsocketPrivateAccountLedgerResponse) error { return nil })
+			return err
+		}},
+		{"orders", func(s *WebsocketPrivateService) error {
+			_, err := s.SubscribeOrder(WebsocketPrivateOrderParam{}, func(WebsocketPrivateOrderResponse) error { return nil })
+			return err
+		}},
+		{"orders v2", func(s *WebsocketPrivateService) error {
+			_, err := s.SubscribeOrderV2(func(WebsocketPrivateOrderResponseV2) error { return nil })
+			return err
+		}},
+		{"trades", func(s *WebsocketPrivateService) error {
+			_, err := s.SubscribeTradesData(WebsocketPrivateTradesDataParam{}, func(WebsocketPrivateTradesDataResponse) error { return nil })
+			return err
+		}},
+		{"pnl", func(s *WebsocketPrivateService) error {
+			_, err := s.SubscribePnL(func(WebsocketPrivatePnLResponse) error { return nil })
+			return err
+		}},
+	}
+	for _, tt := range privateTests {
+		t.Run(tt.name, func(t *testing.T) {
+			var closer func() error
+			service := newWebsocketPrivateService(failingClient("", &closer), dialEcho(t))
+			closer = service.Close
+			if err := tt.subscribe(service); err == nil {
+				t.Fatal("subscribe succeeded on a closed connection")
+			}
+			if messages := service.router.unsubscribeMessages(); len(messages) != 0 {
+				t.Errorf("routes left behind, Shutdown would send %v", messages)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package ibkr
+
+import (
+	"context"
+	"iter"
+	"sync"
+)
+
+// Subscription :
+// A channel based alternative to the Subscribe* callbacks. Messages are
+// queued on C() according to the delivery options, so consumers never run on
+// the read goroutine and can not tear down the connection.
+type Subscription[T any] struct {
+	channel chan T
+	options DeliveryOptions
+	stats   deliveryCounters
+
+	unsubscribe func() error
+	cancelHook  func()
+
+	mutex     sync.RWMutex
+	done      chan struct{}
+	closeOnce sync.Once
+	closed    bool
+	err       error
+}
+
+func newSubscription[T any](options DeliveryOptions) *Subscription[T] {
+	return &Subscription[T]{
+		channel: make(chan T, options.bufferSize()),
+		options: options,
+		done:    make(chan struct{}),
+	}
+}
+
+// NewSubscription :
+// Creates a subscription fed by the handler passed to subscribeFunc, which
+// returns the unsubscribe func called by Close. Services use it for the
+// Stream* methods; fakes can use it to stream their own messages.
+func NewSubscription[T any](
+	options DeliveryOptions,
+	subscribeFunc func(handler func(T) error) (func() error, error),
+) (*Subscription[T], error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+	subscription := newSubscription[T](options)
+	unsubscribe, err := subscribeFunc(subscription.handle)
+	if err != nil {
+		subscription.terminate(err)
+		return nil, err
+	}
+	subscription.unsubscribe = unsubscribe
+	return subscription, nil
+}
+
+// subscribe :
+// Creates a subscription that ends when the connection of router closes.
+func subscribe[T any](
+	router *websocketRouter,
+	options DeliveryOptions,
+	subscribeFunc func(handler func(T) error) (func() error, error),
+) (*Subscription[T], error) {
+	subscription, err := NewSubscription(options, subscribeFunc)
+	if err != nil {
+		return nil, err
+	}
+	subscription.cancelHook = router.onClose(func() {
+		subscription.terminate(ErrWebsocketConnectionClosed)
+	})
+	return subscription, nil
+}
+
+// C :
+// Returns the message channel. It is closed when the subscription ends.
+func (s *Subscription[T]) C() <-chan T {
+	return s.channel
+}
+
+// All :
+// Iterates messages until the subscription ends or ctx is done. The context
+// error, or ErrWebsocketConnectionClosed when the connection went away, is
+// yielded as the last element.
+func (s *Subscription[T]) All(ctx context.Context) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		for {
+			select {
+			case <-ctx.Done():
+				yield(zero, ctx.Err())
+				return
+			case value, ok := <-s.channel:
+				if !ok {
+					if err := s.Err(); err != nil {
+						yield(zero, err)
+					}
+					return
+				}
+				if !yield(value, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Err :
+// Returns why the subscription ended, nil while it is active or after Close.
+func (s *Subscription[T]) Err() error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.err
+}
+
+// Stats :
+func (s *Subscription[T]) Stats() DeliveryStats {
+	return s.stats.stats()
+}
+
+// Close :
+// Unsubscribes and closes the channel.
+func (s *Subscription[T]) Close() error {
+	var err error
+	if s.cancelHook != nil {
+		s.cancelHook()
+	}
+	if s.unsubscribe != nil && !s.isClosed() {
+		err = s.unsubscribe()
+	}
+	s.terminate(nil)
+	return err
+}
+
+func (s *Subscription[T]) isClosed() bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.closed
+}
+
+// handle :
+// Never returns an error, so a subscription can not close the connection.
+func (s *Subscription[T]) handle(value T) error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if s.closed {
+		return nil
+	}
+
+	if s.options.blocking() {
+		select {
+		case s.channel <- value:
+			s.stats.delivered.Add(1)
+		case <-s.done:
+		}
+		return nil
+	}
+	dropped := offer(s.channel, value, s.options.policy())
+	s.stats.dropped.Add(dropped)
+	if s.options.policy() != DeliveryPolicyDropNewest || dropped == 0 {
+		s.stats.delivered.Add(1)
+	}
+	return nil
+}
+
+func (s *Subscription[T]) terminate(err error) {
+	s.closeOnce.Do(func() {
+		// unblock a pending send before waiting for it to finish
+		close(s.done)
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		s.closed = true
+		s.err = err
+		close(s.channel)
+	})
+}
@@ -0,0 +1,154 @@
+package ibkr
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testSubscription subscribes through router and returns the handler feeding
+// the subscription and how often it was unsubscribed.
+func testSubscription(t *testing.T, router *websocketRouter) (*Subscription[int], func(int) error, *atomic.Int32) {
+	t.Helper()
+	var handler func(int) error
+	unsubscribed := &atomic.Int32{}
+	subscription, err := subscribe(router, DeliveryOptions{BufferSize: 4}, func(h func(int) error) (func() error, error) {
+		handler = h
+		return func() error {
+			unsubscribed.Add(1)
+			return nil
+		}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return subscription, handler, unsubscribed
+}
+
+// collect runs All in a goroutine and returns the yielded values and errors
+// once it stops.
+func collect(ctx context.Context, subscription *Subscription[int]) <-chan []any {
+	result := make(chan []any, 1)
+	go func() {
+		var yielded []any
+		for value, err := range subscription.All(ctx) {
+			if err != nil {
+				yielded = append(yielded, err)
+				continue
+			}
+			yielded = append(yielded, value)
+		}
+		result <- yielded
+	}()
+	return result
+}
+
+func waitCollected(t *testing.T, result <-chan []any) []any {
+	t.Helper()
+	select {
+	case yielded := <-result:
+		return yielded
+	case <-time.After(2 * time.Second):
+		t.Fatal("All did not stop")
+		return nil
+	}
+}
+
+func TestSubscriptionAllStopsOnCancel(t *testing.T) {
+	subscription, handler, _ := testSubscription(t, newWebsocketRouter())
+	_ = handler(1)
+	_ = handler(2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := collect(ctx, subscription)
+	for len(subscription.C()) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+
+	yielded := waitCollected(t, result)
+	if len(yielded) != 3 || yielded[0] != 1 || yielded[1] != 2 || yielded[2] != context.Canceled {
+		t.Errorf("yielded %v, want 1, 2 and context.Canceled", yielded)
+	}
+	if subscription.Err() != nil {
+		t.Errorf("cancelling All ended the subscription with %v", subscription.Err())
+	}
+}
+
+func TestSubscriptionAllStopsOnBreak(t *testing.T) {
+	subscription, handler, _ := testSubscription(t, newWebsocketRouter())
+	_ = handler(1)
+	_ = handler(2)
+	for value, err := range subscription.All(context.Background()) {
+		if err != nil || value != 1 {
+			t.Errorf("got %d, %v", value, err)
+		}
+		break
+	}
+	if got := <-subscription.C(); got != 2 {
+		t.Errorf("next value %d, want 2", got)
+	}
+}
+
+func TestSubscriptionClose(t *testing.T) {
+	router := newWebsocketRouter()
+	subscription, handler, unsubscribed := testSubscription(t, router)
+	result := collect(context.Background(), subscription)
+
+	for range 3 {
+		if err := subscription.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := unsubscribed.Load(); got != 1 {
+		t.Errorf("unsubscribed %d times, want once", got)
+	}
+	if _, open := <-subscription.C(); open {
+		t.Error("C() is still open")
+	}
+	if yielded := waitCollected(t, result); len(yielded) != 0 {
+		t.Errorf("yielded %v after Close, want nothing", yielded)
+	}
+	if subscription.Err() != nil {
+		t.Errorf("Err %v after Close, want nil", subscription.Err())
+	}
+	// late messages and the router closing later are ignored
+	if err := handler(1); err != nil {
+		t.Error(err)
+	}
+	router.close()
+	if subscription.Err() != nil {
+		t.Errorf("Err %v after the router closed, want nil", subscription.Err())
+	}
+}
+
+func TestSubscriptionEndsWithRouter(t *testing.T) {
+	router := newWebsocketRouter()
+	subscription, handler, unsubscribed := testSubscription(t, router)
+	_ = handler(1)
+	result := collect(context.Background(), subscription)
+
+	router.close()
+	yielded := waitCollected(t, result)
+	var last error
+	if len(yielded) > 0 {
+		last, _ = yielded[len(yielded)-1].(error)
+	}
+	if !errors.Is(last, ErrWebsocketConnectionClosed) {
+		t.Errorf("yielded %v, want ErrWebsocketConnectionClosed last", yielded)
+	}
+	if !errors.Is(subscription.Err(), ErrWebsocketConnectionClosed) {
+		t.Errorf("Err %v, want ErrWebsocketConnectionClosed", subscription.Err())
+	}
+	if _, open := <-subscription.C(); open {
+		t.Error("C() is still open")
+	}
+
+	// there is no connection left to send an unsubscribe message on
+	_ = subscription.Close()
+	if got := unsubscribed.Load(); got != 0 {
+		t.Errorf("unsubscribed %d times after the connection closed", got)
+	}
+}